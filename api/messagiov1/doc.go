@@ -0,0 +1,4 @@
+// Package messagiov1 is the gRPC API of messages generated from message.proto.
+package messagiov1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative message.proto
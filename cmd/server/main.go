@@ -6,14 +6,21 @@ import (
 	"github.com/IBM/sarama"
 	"log"
 	"log/slog"
+	"messagio_assignment/internal/adapters/jwtauth"
 	"messagio_assignment/internal/adapters/kafkaprod"
 	"messagio_assignment/internal/adapters/pgstore"
+	"messagio_assignment/internal/adapters/webhookhttp"
 	"messagio_assignment/internal/config"
+	domainauth "messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/webhook"
 	"messagio_assignment/internal/graceful"
+	"messagio_assignment/internal/limiter"
 	"messagio_assignment/internal/logger"
+	"messagio_assignment/internal/ports/grpcapi"
 	"messagio_assignment/internal/ports/kafkacons"
 	"messagio_assignment/internal/ports/rest"
 	"messagio_assignment/internal/usecases"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -58,7 +65,18 @@ func main() {
 		slogger.Error("kafkaProd.New", logger.Err(err))
 		return
 	}
+	// Outbox relay производит сообщения, пока не закрыт, поэтому закрывается до producer:
+	// функции closer выполняются параллельно
+	var outboxRelay *usecases.OutboxRelay
 	closer.Add(func(ctx context.Context) error {
+		if outboxRelay != nil {
+			err := outboxRelay.Close(ctx)
+			if err != nil {
+				return fmt.Errorf("outbox relay close: %w", err)
+			}
+			slogger.Info("outbox relay is closed")
+		}
+
 		err := kafkaProd.Close()
 		if err != nil {
 			return fmt.Errorf("kafka prod close %w", err)
@@ -89,7 +107,123 @@ func main() {
 	}
 
 	// Создание usecase
-	messageUC := usecases.NewMessageUC(store.Message(), kafkaProd.Messages())
+	messageUC := usecases.NewMessageUC(store.Message())
+	messageUC.Events = store.Events()
+	idempotencyUC := usecases.NewIdempotencyUC(store.Idempotency(), cfg.Idempotency.TTL, cfg.Idempotency.ReservationTimeout)
+	apiKeyUC := usecases.NewAPIKeyUC(store.APIKey(), cfg.Auth.BootstrapKey)
+	webhookUC := usecases.NewWebhookUC(store.Webhook())
+
+	// Создание и запуск outbox relay
+	outboxRelay = usecases.NewOutboxRelay(store.Outbox(), kafkaProd.Messages(), slogger,
+		usecases.OutboxRelayConfig{
+			Interval:  cfg.Outbox.Interval,
+			BatchSize: cfg.Outbox.BatchSize,
+		})
+	go func() {
+		outboxRelay.Run(ctx)
+	}()
+
+	// Создание и запуск планировщика отложенных сообщений
+	scheduler := usecases.NewScheduler(store.Outbox(), slogger,
+		usecases.SchedulerConfig{
+			Interval:  cfg.Scheduler.Interval,
+			BatchSize: cfg.Scheduler.BatchSize,
+		})
+	closer.Add(func(ctx context.Context) error {
+		err := scheduler.Close(ctx)
+		if err != nil {
+			return fmt.Errorf("scheduler close: %w", err)
+		}
+		slogger.Info("scheduler is closed")
+		return nil
+	})
+	go func() {
+		scheduler.Run(ctx)
+	}()
+
+	// Создание и запуск отправки вебхуков, без секрета доставки ждут в журнале
+	if cfg.Webhooks.Secret != "" {
+		webhookDispatcher := usecases.NewWebhookDispatcher(store.Webhook(),
+			webhookhttp.NewSender(cfg.Webhooks.Secret, cfg.Webhooks.Timeout, cfg.Webhooks.AllowedHosts), slogger,
+			usecases.WebhookDispatcherConfig{
+				Interval:  cfg.Webhooks.Interval,
+				BatchSize: cfg.Webhooks.BatchSize,
+				Lease:     2 * cfg.Webhooks.Timeout,
+				Retry: webhook.RetryPolicy{
+					MaxAttempts: cfg.Webhooks.MaxAttempts,
+					MinBackoff:  cfg.Webhooks.MinBackoff,
+					MaxBackoff:  cfg.Webhooks.MaxBackoff,
+				},
+			})
+		closer.Add(func(ctx context.Context) error {
+			err := webhookDispatcher.Close(ctx)
+			if err != nil {
+				return fmt.Errorf("webhook dispatcher close: %w", err)
+			}
+			slogger.Info("webhook dispatcher is closed")
+			return nil
+		})
+		go func() {
+			webhookDispatcher.Run(ctx)
+		}()
+	} else {
+		slogger.Warn("webhooks.secret is not set, webhooks are not sent")
+	}
+
+	// Создание и запуск очистки старых сообщений
+	retentionPurger := usecases.NewRetentionPurger(store.Message(), slogger,
+		usecases.RetentionPurgerConfig{
+			Interval:  cfg.Retention.Interval,
+			MaxAge:    cfg.Retention.MaxAge,
+			BatchSize: cfg.Retention.BatchSize,
+		})
+	closer.Add(func(ctx context.Context) error {
+		err := retentionPurger.Close(ctx)
+		if err != nil {
+			return fmt.Errorf("retention purger close: %w", err)
+		}
+		slogger.Info("retention purger is closed")
+		return nil
+	})
+	go func() {
+		retentionPurger.Run(ctx)
+	}()
+
+	// Создание и запуск хаба событий сообщений для SSE, события приходят через Postgres LISTEN/NOTIFY
+	eventHub := usecases.NewEventHub(store.Events(), slogger,
+		usecases.EventHubConfig{
+			Buffer:        cfg.Events.Buffer,
+			RetryInterval: cfg.Events.RetryInterval,
+		})
+	closer.Add(func(ctx context.Context) error {
+		err := eventHub.Close(ctx)
+		if err != nil {
+			return fmt.Errorf("event hub close: %w", err)
+		}
+		slogger.Info("event hub is closed")
+		return nil
+	})
+	go func() {
+		eventHub.Run(ctx)
+	}()
+
+	// Создание и запуск стримера статистики для /ws/stats, статистика пересчитывается по событиям хаба
+	statsStreamer := usecases.NewStatsStreamer(store.Message(), eventHub, slogger,
+		usecases.StatsStreamerConfig{
+			Interval: cfg.StatsStream.Interval,
+			Buffer:   cfg.StatsStream.Buffer,
+		})
+	closer.Add(func(ctx context.Context) error {
+		err := statsStreamer.Close(ctx)
+		if err != nil {
+			return fmt.Errorf("stats streamer close: %w", err)
+		}
+		slogger.Info("stats streamer is closed")
+		return nil
+	})
+	go func() {
+		statsStreamer.Run(ctx)
+	}()
 
 	// Создание и запуск Kafka Consumers
 	kafkaCons, err := kafkacons.New(slogger, messageUC, saramaCfg, cfg.Kafka)
@@ -109,8 +243,68 @@ func main() {
 		kafkaCons.ProcessedMsgs().StartConsume(ctx)
 	}()
 
+	// Аутентификация по API-ключам и JWT
+	var auth *rest.Auth
+	var authenticator rest.Authenticator
+	if cfg.Auth.Enabled {
+		authenticators := rest.Authenticators{apiKeyUC}
+
+		if cfg.Auth.JWT.JWKSPath != "" {
+			keySet, err := jwtauth.NewKeySet(cfg.Auth.JWT.JWKSPath, cfg.Auth.JWT.ReloadInterval, slogger)
+			if err != nil {
+				slogger.Error("jwtauth.NewKeySet", logger.Err(err))
+				return
+			}
+			closer.Add(func(ctx context.Context) error {
+				err := keySet.Close(ctx)
+				if err != nil {
+					return fmt.Errorf("jwks close: %w", err)
+				}
+				slogger.Info("jwks is closed")
+				return nil
+			})
+			go func() {
+				keySet.Run(ctx)
+			}()
+
+			scopeMapping := make(map[string][]domainauth.Scope, len(cfg.Auth.JWT.ScopeMapping))
+			for value, scopes := range cfg.Auth.JWT.ScopeMapping {
+				for _, scope := range scopes {
+					if !domainauth.Scope(scope).Valid() {
+						slogger.Error("unknown scope in auth.jwt.scope_mapping", slog.String("scope", scope))
+						return
+					}
+					scopeMapping[value] = append(scopeMapping[value], domainauth.Scope(scope))
+				}
+			}
+
+			authenticators = append(authenticators, jwtauth.NewVerifier(keySet, jwtauth.VerifierConfig{
+				Issuer:       cfg.Auth.JWT.Issuer,
+				Audience:     cfg.Auth.JWT.Audience,
+				ScopeClaim:   cfg.Auth.JWT.ScopeClaim,
+				ScopeMapping: scopeMapping,
+				Leeway:       cfg.Auth.JWT.Leeway,
+			}))
+		}
+
+		authenticator = authenticators
+		auth = rest.NewAuth(authenticator, slogger)
+	}
+
+	// Лимиты запросов общие для REST и gRPC
+	rateLimitCfg, err := limiter.NewConfig(cfg.HTTPServer.Handlers.Message.RateLimit, store.RateLimit())
+	if err != nil {
+		slogger.Error("limiter.NewConfig", logger.Err(err))
+		return
+	}
+
 	// Создание и запуск rest http сервера
-	server := rest.NewServer(cfg.HTTPServer, messageUC, slogger)
+	server, err := rest.NewServer(cfg.HTTPServer, messageUC, idempotencyUC, auth, apiKeyUC,
+		webhookUC, rateLimitCfg, eventHub, statsStreamer, slogger)
+	if err != nil {
+		slogger.Error("rest.NewServer", logger.Err(err))
+		return
+	}
 	closer.Add(func(ctx context.Context) error {
 		if err := server.Shutdown(ctx); err != nil {
 			return fmt.Errorf("rest http server shutdown: %w", err)
@@ -127,5 +321,44 @@ func main() {
 		}
 	}()
 
+	// Создание и запуск gRPC сервера, если задан адрес
+	if cfg.GRPCServer.Addr != "" {
+		grpcServer, err := grpcapi.NewServer(cfg.GRPCServer, cfg.HTTPServer, messageUC, authenticator,
+			rateLimitCfg, eventHub, slogger)
+		if err != nil {
+			slogger.Error("grpcapi.NewServer", logger.Err(err))
+			return
+		}
+		lis, err := net.Listen("tcp", cfg.GRPCServer.Addr)
+		if err != nil {
+			slogger.Error("grpc listen", logger.Err(err))
+			return
+		}
+		closer.Add(func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return fmt.Errorf("grpc server shutdown: %w", ctx.Err())
+			}
+			slogger.Info("grpc server is closed")
+			return nil
+		})
+		go func() {
+			slogger.Info("grpc listening...", slog.String("addr", cfg.GRPCServer.Addr))
+
+			err := grpcServer.Serve(lis)
+			if err != nil {
+				slogger.Error("grpc serve", logger.Err(err))
+			}
+		}()
+	}
+
 	<-ctx.Done()
 }
@@ -15,9 +15,61 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/messages": {
+        "/admin/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list API keys including revoked ones, secrets are never returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListAPIKeysResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "create a message",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create an API key with scopes, the key is returned only once",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,17 +77,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "admin"
                 ],
-                "summary": "Create a message",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Create message",
-                        "name": "message",
+                        "description": "Create API key",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateMessageReq"
+                            "$ref": "#/definitions/dto.CreateAPIKeyReq"
                         }
                     }
                 ],
@@ -43,12 +95,347 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateMessageResp"
+                            "$ref": "#/definitions/dto.CreateAPIKeyResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke an API key, requests with it are rejected right away",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Key is not found or is already revoked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the webhook delivery log with the result of the last attempt of each delivery",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by message id",
+                        "name": "message_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 50 by default, 500 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListDeliveriesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "send the webhook delivery again right away with all retry attempts, whatever its status is",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Redeliver a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeliveryResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list messages ordered by id with keyset pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "List messages",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "queued",
+                            "processing",
+                            "processed",
+                            "failed",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by label key:value, all labels must match",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimal message id, inclusive",
+                        "name": "min_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal message id, inclusive",
+                        "name": "max_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 50 by default, 500 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListMessagesResp"
                         },
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -63,12 +450,12 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/dto.HTTPError"
+                            "$ref": "#/definitions/dto.Problem"
                         },
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -80,15 +467,15 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/dto.HTTPError"
+                            "$ref": "#/definitions/dto.Problem"
                         },
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -100,15 +487,15 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/dto.HTTPError"
+                            "$ref": "#/definitions/dto.Problem"
                         },
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -123,12 +510,16 @@ const docTemplate = `{
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
-                            "$ref": "#/definitions/dto.HTTPError"
+                            "$ref": "#/definitions/dto.Problem"
                         },
                         "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -142,10 +533,13 @@ const docTemplate = `{
                     },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -158,28 +552,174 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/messages/stats": {
-            "get": {
-                "description": "get messages stats",
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create a message",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Get messages stats",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.GetStatsResp"
-                        },
-                        "headers": {
-                            "X-RateLimit-Limit": {
+                "summary": "Create a message",
+                "parameters": [
+                    {
+                        "description": "Create message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMessageReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a retried request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMessageResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Message already exists or the request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields, message is not created or Idempotency-Key is reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationProblem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -194,12 +734,16 @@ const docTemplate = `{
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
-                            "$ref": "#/definitions/dto.HTTPError"
+                            "$ref": "#/definitions/dto.Problem"
                         },
                         "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -216,7 +760,7 @@ const docTemplate = `{
                         "headers": {
                             "X-RateLimit-Limit": {
                                 "type": "string",
-                                "description": "Request limit per minute"
+                                "description": "Request limit for the time window of the client tier"
                             },
                             "X-RateLimit-Remaining": {
                                 "type": "string",
@@ -230,52 +774,2089 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.CreateMessageReq": {
-            "type": "object",
-            "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "processed": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "dto.CreateMessageResp": {
-            "type": "object",
-            "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "processed": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "dto.GetStatsResp": {
-            "type": "object",
-            "properties": {
-                "all": {
-                    "type": "integer"
-                },
-                "processed": {
-                    "type": "integer"
-                }
-            }
         },
-        "dto.HTTPError": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
+        "/v1/messages/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create messages in one transaction, malformed messages are reported per item and skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Create messages in batch",
+                "parameters": [
+                    {
+                        "description": "Create messages",
+                        "name": "messages",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.CreateMessageReq"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a retried request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All messages are created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMessagesResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "Some messages are malformed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMessagesResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream with a \"status\" event each time a message is created or processed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Stream status changes of all messages",
+                "responses": {
+                    "200": {
+                        "description": "data of the status events",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageEventResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "full-text search over message content, results are ranked by relevance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Search messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query in the web search syntax: quoted phrases, or, -excluded",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default, 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SearchMessagesResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get messages stats, optionally in a time window and with a time series",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get messages stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Window start, inclusive, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end, exclusive, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "minute",
+                            "hour",
+                            "day"
+                        ],
+                        "type": "string",
+                        "description": "Series bucket",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by label key:value, all labels must match",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label key to group stats by",
+                        "name": "group_by_label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetStatsResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get a message by id. With wait, the request blocks until the message is processed,\nfailed, cancelled or expired or the wait elapses, and then returns the current state.\nThe wait is capped to fit the server write timeout.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for a processing result, e.g. 30s",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetMessageResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "soft delete a message, it is hidden from reads and stats and purged later",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Delete a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "cancel a message with send_at before it is sent, so it is never produced",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Cancel a scheduled message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetMessageResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not scheduled or is already sent",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/messages/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream. The first \"status\" event is the current status,\nthe next ones are sent when the message is processed. The stream ends after a final status.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Stream status changes of a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "data of the status events",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageEventResp"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before retrying the request"
+                            },
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        },
+                        "headers": {
+                            "X-RateLimit-Limit": {
+                                "type": "string",
+                                "description": "Request limit for the time window of the client tier"
+                            },
+                            "X-RateLimit-Remaining": {
+                                "type": "string",
+                                "description": "The number of requests left for the time window"
+                            },
+                            "X-RateLimit-Reset": {
+                                "type": "string",
+                                "description": "The remaining window before the rate limit resets in UTC epoch seconds"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The first message is a \"snapshot\" with the current stats, the next ones are \"delta\"\nwith the changes since the previous message. Deltas are sent when messages are created\nor processed, at most once per the configured interval. A slow client is disconnected\nand gets a new snapshot after reconnecting.",
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Stream message stats over WebSocket",
+                "responses": {
+                    "101": {
+                        "description": "WebSocket messages",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatsStreamResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.APIKeyResp": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "msg_Xy3kQ9"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.CreateAPIKeyReq": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "description": "CallbackURL is the default webhook URL of messages created with the key.",
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/messages"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "crm"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "messages:read",
+                        "messages:write"
+                    ]
+                }
+            }
+        },
+        "dto.CreateAPIKeyResp": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "msg_Xy3kQ9"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.CreateMessageReq": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "description": "CallbackURL receives a webhook with the processing result. The URL of the API key is used if it is empty.",
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/messages"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt or TTL drops the message if it is not produced in time.",
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "send_at": {
+                    "description": "SendAt delays producing the message until the time.",
+                    "type": "string"
+                },
+                "ttl": {
+                    "type": "string",
+                    "example": "1h30m"
+                }
+            }
+        },
+        "dto.CreateMessageResp": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "send_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "queued",
+                        "processing",
+                        "processed",
+                        "failed",
+                        "cancelled",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "dto.CreateMessagesItemResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "The request has invalid fields."
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateMessagesResp": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreateMessagesItemResp"
+                    }
+                }
+            }
+        },
+        "dto.DeliveryResp": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_response_code": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "message_status": {
+                    "type": "string",
+                    "enum": [
+                        "processed",
+                        "failed",
+                        "expired"
+                    ]
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "succeeded",
+                        "failed"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/messages"
+                }
+            }
+        },
+        "dto.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "content"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must not be empty"
+                }
+            }
+        },
+        "dto.GetMessageResp": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "send_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "queued",
+                        "processing",
+                        "processed",
+                        "failed",
+                        "cancelled",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "dto.GetStatsResp": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "integer"
+                },
+                "by_label": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LabelStatsResp"
+                    }
+                },
+                "by_status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "outbox_pending": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StatsBucketResp"
+                    }
+                }
+            }
+        },
+        "dto.LabelStatsResp": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LatencyResp": {
+            "type": "object",
+            "properties": {
+                "p50_ms": {
+                    "type": "number"
+                },
+                "p95_ms": {
+                    "type": "number"
+                },
+                "p99_ms": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.ListAPIKeysResp": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.APIKeyResp"
+                    }
+                }
+            }
+        },
+        "dto.ListDeliveriesResp": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeliveryResp"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListMessagesResp": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GetMessageResp"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageEventResp": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "queued",
+                        "processing",
+                        "processed",
+                        "failed",
+                        "cancelled",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "dto.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "The resource is not found."
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/messages/42"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:messagio:problem:not_found"
+                }
+            }
+        },
+        "dto.SearchHitResp": {
+            "type": "object",
+            "properties": {
+                "highlight": {
+                    "description": "Highlight has the matched fragments of the content with the matched words in \u003cmark\u003e tags.\nThe content is not escaped.",
+                    "type": "string"
+                },
+                "message": {
+                    "$ref": "#/definitions/dto.GetMessageResp"
+                },
+                "rank": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.SearchMessagesResp": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SearchHitResp"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StatsBucketResp": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "latency": {
+                    "$ref": "#/definitions/dto.LatencyResp"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StatsDeltaResp": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "integer"
+                },
+                "by_status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "outbox_pending": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.StatsStreamResp": {
+            "type": "object",
+            "properties": {
+                "delta": {
+                    "$ref": "#/definitions/dto.StatsDeltaResp"
+                },
+                "stats": {
+                    "$ref": "#/definitions/dto.GetStatsResp"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "snapshot",
+                        "delta"
+                    ]
+                }
+            }
+        },
+        "dto.ValidationProblem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "The resource is not found."
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/messages/42"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:messagio:problem:not_found"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Bearer API key or JWT, e.g. \"Bearer msg_...\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -287,7 +2868,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Messagio Assigment",
-	Description:      "Test task to Messagio.",
+	Description:      "Test task to Messagio.\nThe message routes are versioned, e.g. /v1/messages. The unversioned routes, e.g. /messages,\nare deprecated aliases of /v1 with the Deprecation, Sunset and Link headers.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,261 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"messagio_assignment/internal/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwk has the members of RSA and EC public keys, RFC 7517 and RFC 7518.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	N string `json:"n"`
+	E string `json:"e"`
+
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS returns the signature keys of the set by kid. Keys of other types and uses are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			key, err = k.ecdsa()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %d %q: %w", i, k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k *jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("n: %w", err)
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("e: %w", err)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("e is out of range")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k *jwk) ecdsa() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := decodeInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("is empty")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySet is the JWKS loaded from a local file. Run reloads it when the file changes.
+type KeySet struct {
+	path     string
+	interval time.Duration
+	log      *slog.Logger
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey
+	modTime time.Time
+	size    int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewKeySet loads the JWKS file. The interval is how often the file is checked for changes.
+func NewKeySet(path string, interval time.Duration, log *slog.Logger) (*KeySet, error) {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "adapters/jwtauth"), slog.String("jwks_path", path))
+
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ks := &KeySet{
+		path:     path,
+		interval: interval,
+		log:      log,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if _, err := ks.Reload(); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Key returns the key by kid. Tokens without kid can be verified only if the set has one key.
+func (ks *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+	}
+	return key, nil
+}
+
+// Reload loads the file if it has changed and reports whether the keys are replaced.
+// The current keys are kept if the file is invalid.
+func (ks *KeySet) Reload() (bool, error) {
+	info, err := os.Stat(ks.path)
+	if err != nil {
+		return false, fmt.Errorf("jwks: %w", err)
+	}
+
+	ks.mu.RLock()
+	unchanged := ks.keys != nil && info.ModTime().Equal(ks.modTime) && info.Size() == ks.size
+	ks.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(ks.path)
+	if err != nil {
+		return false, fmt.Errorf("jwks: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return false, err
+	}
+
+	ks.mu.Lock()
+	ks.keys, ks.modTime, ks.size = keys, info.ModTime(), info.Size()
+	ks.mu.Unlock()
+
+	return true, nil
+}
+
+// Run is blocking. It reloads the file every interval until ctx is done or Close is called.
+func (ks *KeySet) Run(ctx context.Context) {
+	defer close(ks.done)
+
+	ticker := time.NewTicker(ks.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ks.stop:
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := ks.Reload()
+		if err != nil {
+			ks.log.Error("reload jwks, the previous keys are kept", logger.Err(err))
+			continue
+		}
+		if reloaded {
+			ks.log.Info("jwks is reloaded")
+		}
+	}
+}
+
+// Close stops Run and waits for it to return.
+func (ks *KeySet) Close(ctx context.Context) error {
+	ks.stopOnce.Do(func() {
+		close(ks.stop)
+	})
+
+	select {
+	case <-ks.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("KeySet.Close: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,142 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func rsaJWK(t *testing.T, kid string, key *rsa.PublicKey) map[string]string {
+	t.Helper()
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string, key *ecdsa.PublicKey) map[string]string {
+	t.Helper()
+	size := (key.Curve.Params().BitSize + 7) / 8
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": key.Curve.Params().Name,
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+// writeJWKS writes the set and moves mtime forward, so the change is seen even within the same second.
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	writeFile(t, path, data)
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	mtime := time.Now().Add(time.Hour)
+	if info, err := os.Stat(path); err == nil && !info.ModTime().Before(mtime) {
+		mtime = info.ModTime().Add(time.Second)
+	}
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	enc := rsaJWK(t, "enc", &rsaKey.PublicKey)
+	enc["use"] = "enc"
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		rsaJWK(t, "rsa", &rsaKey.PublicKey),
+		ecJWK(t, "ec", &ecKey.PublicKey),
+		enc,
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}})
+	require.NoError(t, err)
+
+	keys, err := ParseJWKS(data)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["rsa"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["ec"]))
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"RSA","kid":"bad","n":"!","e":"AQAB"}]}`))
+	assert.Error(t, err)
+	_, err = ParseJWKS([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestKeySet_Reload(t *testing.T) {
+	first, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, ecJWK(t, "first", &first.PublicKey))
+
+	ks, err := NewKeySet(path, time.Minute, nil)
+	require.NoError(t, err)
+
+	key, err := ks.Key("")
+	require.NoError(t, err)
+	assert.True(t, first.PublicKey.Equal(key))
+
+	reloaded, err := ks.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged file must not be reloaded")
+
+	writeJWKS(t, path, ecJWK(t, "first", &first.PublicKey), ecJWK(t, "second", &second.PublicKey))
+	reloaded, err = ks.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	key, err = ks.Key("second")
+	require.NoError(t, err)
+	assert.True(t, second.PublicKey.Equal(key))
+	_, err = ks.Key("")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "kid is required when the set has several keys")
+
+	writeFile(t, path, []byte(`{"keys":`))
+	_, err = ks.Reload()
+	assert.Error(t, err)
+
+	key, err = ks.Key("second")
+	require.NoError(t, err, "the previous keys must be kept")
+	assert.True(t, second.PublicKey.Equal(key))
+
+	_, err = ks.Key("unknown")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestNewKeySet_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewKeySet(filepath.Join(dir, "missing.json"), 0, nil)
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "jwks.json")
+	writeFile(t, path, []byte(`[]`))
+	_, err = NewKeySet(path, 0, nil)
+	assert.Error(t, err)
+}
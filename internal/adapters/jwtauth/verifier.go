@@ -0,0 +1,119 @@
+package jwtauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"strings"
+	"time"
+)
+
+// DefaultScopeClaim is the OAuth 2.0 scope claim, RFC 8693.
+const DefaultScopeClaim = "scope"
+
+// validMethods are asymmetric algorithms only, keys of the set can't be used as HMAC secrets.
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type VerifierConfig struct {
+	// Issuer and Audience are checked if they are not empty.
+	Issuer   string
+	Audience string
+	// ScopeClaim has the scopes as a space-separated string or an array of strings.
+	ScopeClaim string
+	// ScopeMapping maps claim values to scopes. If it is empty, values are used as scopes.
+	ScopeMapping map[string][]auth.Scope
+	// Leeway allows clock skew with the identity provider.
+	Leeway time.Duration
+}
+
+// Verifier authenticates JWTs signed by keys of the set.
+type Verifier struct {
+	keys   *KeySet
+	cfg    VerifierConfig
+	parser *jwt.Parser
+}
+
+func NewVerifier(keys *KeySet, cfg VerifierConfig) *Verifier {
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = DefaultScopeClaim
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{keys: keys, cfg: cfg, parser: jwt.NewParser(opts...)}
+}
+
+// Authenticate verifies the token and maps its claims to the principal.
+// It returns auth.ErrUnknownToken if the token is not a JWT.
+func (v *Verifier) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if strings.Count(token, ".") != 2 {
+		return nil, auth.ErrUnknownToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwt: %w", domain.ErrUnauthenticated, err)
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil || sub == "" {
+		return nil, fmt.Errorf("%w: jwt: sub claim is required", domain.ErrUnauthenticated)
+	}
+
+	scopes, err := v.scopes(claims[v.cfg.ScopeClaim])
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwt: %s claim: %w", domain.ErrUnauthenticated, v.cfg.ScopeClaim, err)
+	}
+
+	return &auth.Principal{Subject: "jwt:" + sub, Scopes: scopes}, nil
+}
+
+// scopes maps the claim values to known scopes, unknown values are skipped.
+func (v *Verifier) scopes(claim any) ([]auth.Scope, error) {
+	var values []string
+	switch c := claim.(type) {
+	case nil:
+	case string:
+		values = strings.Fields(c)
+	case []any:
+		for _, item := range c {
+			s, ok := item.(string)
+			if !ok {
+				return nil, errors.New("must be a string or an array of strings")
+			}
+			values = append(values, s)
+		}
+	default:
+		return nil, errors.New("must be a string or an array of strings")
+	}
+
+	var scopes []auth.Scope
+	for _, value := range values {
+		if len(v.cfg.ScopeMapping) == 0 {
+			if scope := auth.Scope(value); scope.Valid() {
+				scopes = append(scopes, scope)
+			}
+			continue
+		}
+		scopes = append(scopes, v.cfg.ScopeMapping[value]...)
+	}
+
+	return scopes, nil
+}
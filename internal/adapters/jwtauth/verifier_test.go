@@ -0,0 +1,203 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "messagio"
+)
+
+type VerifierSuite struct {
+	suite.Suite
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	keys   *KeySet
+}
+
+func (s *VerifierSuite) SetupSuite() {
+	var err error
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	path := filepath.Join(s.T().TempDir(), "jwks.json")
+	writeJWKS(s.T(), path, rsaJWK(s.T(), "rsa", &s.rsaKey.PublicKey), ecJWK(s.T(), "ec", &s.ecKey.PublicKey))
+
+	s.keys, err = NewKeySet(path, time.Minute, nil)
+	s.Require().NoError(err)
+}
+
+func (s *VerifierSuite) sign(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	s.Require().NoError(err)
+	return signed
+}
+
+func validClaims(overrides jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "messages:read messages:write",
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+	return claims
+}
+
+func (s *VerifierSuite) TestAuthenticate() {
+	v := NewVerifier(s.keys, VerifierConfig{Issuer: testIssuer, Audience: testAudience})
+
+	tests := []struct {
+		Name           string
+		Token          string
+		ExpectedScopes []auth.Scope
+	}{
+		{
+			Name:           "rsa",
+			Token:          s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(nil)),
+			ExpectedScopes: []auth.Scope{auth.ScopeMessagesRead, auth.ScopeMessagesWrite},
+		},
+		{
+			Name:           "ec",
+			Token:          s.sign(jwt.SigningMethodES256, "ec", s.ecKey, validClaims(nil)),
+			ExpectedScopes: []auth.Scope{auth.ScopeMessagesRead, auth.ScopeMessagesWrite},
+		},
+		{
+			Name:           "scope_array_with_unknown_values",
+			Token:          s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"scope": []string{"admin", "openid"}})),
+			ExpectedScopes: []auth.Scope{auth.ScopeAdmin},
+		},
+		{
+			Name:  "no_scope",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"scope": nil})),
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.Name, func() {
+			p, err := v.Authenticate(context.Background(), tc.Token)
+			s.Require().NoError(err)
+			s.Equal("jwt:user-1", p.Subject)
+			s.Equal(tc.ExpectedScopes, p.Scopes)
+		})
+	}
+}
+
+func (s *VerifierSuite) TestAuthenticate_Rejected() {
+	v := NewVerifier(s.keys, VerifierConfig{Issuer: testIssuer, Audience: testAudience})
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	tests := []struct {
+		Name  string
+		Token string
+	}{
+		{
+			Name:  "wrong_issuer",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"iss": "https://evil.example.com"})),
+		},
+		{
+			Name:  "wrong_audience",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"aud": []string{"other"}})),
+		},
+		{
+			Name:  "expired",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		},
+		{
+			Name:  "no_exp",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"exp": nil})),
+		},
+		{
+			Name:  "no_sub",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"sub": nil})),
+		},
+		{
+			Name:  "unknown_kid",
+			Token: s.sign(jwt.SigningMethodRS256, "other", s.rsaKey, validClaims(nil)),
+		},
+		{
+			Name:  "no_kid_several_keys",
+			Token: s.sign(jwt.SigningMethodRS256, "", s.rsaKey, validClaims(nil)),
+		},
+		{
+			Name:  "wrong_signature",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", otherKey, validClaims(nil)),
+		},
+		{
+			Name:  "hmac",
+			Token: s.sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims(nil)),
+		},
+		{
+			Name:  "bad_scope_claim",
+			Token: s.sign(jwt.SigningMethodRS256, "rsa", s.rsaKey, validClaims(jwt.MapClaims{"scope": 42})),
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.Name, func() {
+			_, err := v.Authenticate(context.Background(), tc.Token)
+			s.ErrorIs(err, domain.ErrUnauthenticated)
+			s.NotErrorIs(err, auth.ErrUnknownToken)
+		})
+	}
+}
+
+func (s *VerifierSuite) TestAuthenticate_ScopeMapping() {
+	v := NewVerifier(s.keys, VerifierConfig{
+		ScopeClaim: "roles",
+		ScopeMapping: map[string][]auth.Scope{
+			"messagio-reader": {auth.ScopeMessagesRead},
+			"messagio-admin":  {auth.ScopeAdmin},
+		},
+	})
+
+	token := s.sign(jwt.SigningMethodES256, "ec", s.ecKey, validClaims(jwt.MapClaims{
+		"iss":   "any",
+		"aud":   "any",
+		"roles": []string{"messagio-reader", "messages:write"},
+	}))
+
+	p, err := v.Authenticate(context.Background(), token)
+	s.Require().NoError(err)
+	s.Equal([]auth.Scope{auth.ScopeMessagesRead}, p.Scopes, "only mapped values are scopes")
+}
+
+func TestVerifierSuite(t *testing.T) {
+	suite.Run(t, new(VerifierSuite))
+}
+
+func TestVerifier_UnknownToken(t *testing.T) {
+	v := NewVerifier(nil, VerifierConfig{})
+
+	_, err := v.Authenticate(context.Background(), "msg_secret")
+	assert.ErrorIs(t, err, auth.ErrUnknownToken)
+	require.ErrorIs(t, err, domain.ErrUnauthenticated)
+}
@@ -3,12 +3,17 @@ package dto
 import (
 	"encoding/json"
 	"messagio_assignment/internal/domain/message"
+	"time"
 )
 
 type MessageValue struct {
-	ID        int    `json:"id"`
-	Content   string `json:"content"`
-	Processed bool   `json:"processed"`
+	ID          int               `json:"id"`
+	Content     string            `json:"content"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ProcessedAt *time.Time        `json:"processed_at,omitempty"`
 
 	bytes []byte
 	err   error
@@ -19,7 +24,11 @@ func NewMessageValue(msg *message.Message) *MessageValue {
 
 	v.ID = msg.ID
 	v.Content = msg.Content
-	v.Processed = msg.Processed
+	v.Status = msg.Status.String()
+	v.Labels = msg.Labels
+	v.ExpiresAt = msg.ExpiresAt
+	v.CreatedAt = msg.CreatedAt
+	v.ProcessedAt = msg.ProcessedAt
 
 	var err error
 	v.bytes, err = json.Marshal(v)
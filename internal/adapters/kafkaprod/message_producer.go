@@ -11,7 +11,7 @@ import (
 )
 
 type MessageProducer struct {
-	p     sarama.AsyncProducer
+	p     sarama.SyncProducer
 	log   *slog.Logger
 	topic string
 }
@@ -42,19 +42,17 @@ func NewMessageProducer(log *slog.Logger, brokerList []string,
 	conf.Producer.RequiredAcks = sarama.WaitForLocal
 	conf.Producer.Compression = sarama.CompressionSnappy
 
-	producer, err := sarama.NewAsyncProducer(brokerList, &conf)
+	// required by sync producer, delivery result is needed by the outbox relay
+	conf.Producer.Return.Successes = true
+	conf.Producer.Return.Errors = true
+
+	producer, err := sarama.NewSyncProducer(brokerList, &conf)
 	if err != nil {
 		return nil, err
 	}
 
 	mp := &MessageProducer{p: producer, log: log, topic: producerCfg.Topic}
 
-	go func() {
-		for err = range producer.Errors() {
-			mp.log.Error("messages producer error", logger.Err(err))
-		}
-	}()
-
 	return mp, nil
 }
 
@@ -62,13 +60,29 @@ func (p *MessageProducer) Close() error {
 	if p.p != nil {
 		return p.p.Close()
 	}
-	return errors.New("MessageProducer.Close: async producer is nil")
+	return errors.New("MessageProducer.Close: sync producer is nil")
 }
 
-func (p *MessageProducer) Produce(msg *message.Message) {
-	p.p.Input() <- &sarama.ProducerMessage{
-		Topic: p.topic,
-		Key:   nil, // sarama.StringEncoder(strconv.Itoa(msg.ID)),
-		Value: dto.NewMessageValue(msg),
+// Produce sends messages in one batch and blocks until all of them are acknowledged.
+func (p *MessageProducer) Produce(msgs ...*message.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	pms := make([]*sarama.ProducerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		pms = append(pms, &sarama.ProducerMessage{
+			Topic: p.topic,
+			Key:   nil, // sarama.StringEncoder(strconv.Itoa(msg.ID)),
+			Value: dto.NewMessageValue(msg),
+		})
 	}
+
+	err := p.p.SendMessages(pms)
+	if err != nil {
+		p.log.Error("messages producer error", logger.Err(err))
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,106 @@
+package pgstore
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+)
+
+type APIKeyRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepoPG(db *pgxpool.Pool) *APIKeyRepoPG {
+	return &APIKeyRepoPG{db: db}
+}
+
+const apiKeyColumns = "k.id, k.name, k.prefix, k.scopes, coalesce(k.callback_url, ''), k.created_at, k.revoked_at"
+
+func scanAPIKey(row pgx.Row) (*apikey.Key, error) {
+	var (
+		key    apikey.Key
+		scopes []string
+	)
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &scopes, &key.CallbackURL, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]auth.Scope, len(scopes))
+	for i, s := range scopes {
+		key.Scopes[i] = auth.Scope(s)
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepoPG) Create(ctx context.Context, key *apikey.Key, hash string) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		scopes[i] = s.String()
+	}
+
+	q := `insert into api_keys(name, prefix, key_hash, scopes, callback_url) values($1, $2, $3, $4, nullif($5, ''))
+       returning id, created_at`
+
+	err := r.db.QueryRow(ctx, q, key.Name, key.Prefix, hash, scopes, key.CallbackURL).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return &apikey.Error{Err: ErrCreateIntoDomain(err)}
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepoPG) GetActiveByHash(ctx context.Context, hash string) (*apikey.Key, error) {
+	q := `select ` + apiKeyColumns + ` from api_keys as k 
+       where k.key_hash = $1 and k.revoked_at is null`
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, q, hash))
+	if err != nil {
+		return nil, &apikey.Error{Err: ErrGetIntoDomain(err)}
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepoPG) List(ctx context.Context) ([]*apikey.Key, error) {
+	q := `select ` + apiKeyColumns + ` from api_keys as k order by k.id`
+
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, &apikey.Error{Err: err}
+	}
+	defer rows.Close()
+
+	keys := make([]*apikey.Key, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, &apikey.Error{Err: err}
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &apikey.Error{Err: err}
+	}
+
+	return keys, nil
+}
+
+// Revoke returns domain.ErrNotFound if the key doesn't exist or is already revoked.
+func (r *APIKeyRepoPG) Revoke(ctx context.Context, id int) error {
+	q := "update api_keys as k set revoked_at = now() where k.id = $1 and k.revoked_at is null"
+
+	ct, err := r.db.Exec(ctx, q, id)
+	if err != nil {
+		return &apikey.Error{ID: id, Err: err}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apikey.Error{ID: id, Err: domain.ErrNotFound}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package pgstore
+
+import (
+	"context"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+)
+
+func (su *PGStoreTestSuite) APIKeyRepo() *APIKeyRepoPG {
+	return su.store.APIKey()
+}
+
+func (su *PGStoreTestSuite) TestAPIKeyRepo() {
+	su.Run("get repo", func() {
+		repo := su.store.APIKey()
+		su.Require().NotNil(repo)
+	})
+
+	ctx := context.Background()
+
+	su.Run("create, get, list, revoke", func() {
+		key := &apikey.Key{
+			Name:        "crm",
+			Prefix:      "msg_abcdef",
+			Scopes:      []auth.Scope{auth.ScopeMessagesRead, auth.ScopeMessagesWrite},
+			CallbackURL: "https://crm.example.com/hooks",
+		}
+		err := su.APIKeyRepo().Create(ctx, key, "hash")
+		su.Require().NoError(err)
+		su.NotZero(key.ID)
+		su.NotZero(key.CreatedAt)
+
+		got, err := su.APIKeyRepo().GetActiveByHash(ctx, "hash")
+		su.Require().NoError(err)
+		su.Equal(key.ID, got.ID)
+		su.Equal(key.Scopes, got.Scopes)
+		su.Equal(key.CallbackURL, got.CallbackURL)
+		su.Nil(got.RevokedAt)
+
+		err = su.APIKeyRepo().Create(ctx, &apikey.Key{Name: "copy", Scopes: key.Scopes}, "hash")
+		su.ErrorIs(err, domain.ErrAlreadyExists)
+
+		err = su.APIKeyRepo().Revoke(ctx, key.ID)
+		su.Require().NoError(err)
+
+		_, err = su.APIKeyRepo().GetActiveByHash(ctx, "hash")
+		su.ErrorIs(err, domain.ErrNotFound)
+
+		err = su.APIKeyRepo().Revoke(ctx, key.ID)
+		su.ErrorIs(err, domain.ErrNotFound)
+
+		keys, err := su.APIKeyRepo().List(ctx)
+		su.Require().NoError(err)
+		su.Require().Len(keys, 1)
+		su.NotNil(keys[0].RevokedAt)
+	})
+
+	su.Run("list empty", func() {
+		keys, err := su.APIKeyRepo().List(ctx)
+		su.Require().NoError(err)
+		su.Empty(keys)
+	})
+}
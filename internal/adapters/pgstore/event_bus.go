@@ -0,0 +1,107 @@
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"time"
+)
+
+// MessageEventsChannel is the LISTEN/NOTIFY channel of message events.
+const MessageEventsChannel = "message_events"
+
+// EventBusPG fans message events out to all replicas with LISTEN/NOTIFY.
+// Notifications are not stored, so events published while a replica doesn't listen are lost for it.
+type EventBusPG struct {
+	db  *pgxpool.Pool
+	log *slog.Logger
+}
+
+func NewEventBusPG(db *pgxpool.Pool, log *slog.Logger) *EventBusPG {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	return &EventBusPG{db: db, log: log}
+}
+
+type eventPayload struct {
+	MessageID int            `json:"message_id"`
+	Status    message.Status `json:"status"`
+	At        time.Time      `json:"at"`
+}
+
+// Publish notifies the listeners of all replicas, including this one, in one statement. Errors are logged.
+func (b *EventBusPG) Publish(ctx context.Context, evs ...message.Event) {
+	if len(evs) == 0 {
+		return
+	}
+
+	if err := notifyEvents(ctx, b.db, evs); err != nil {
+		b.log.Error("publish message events", logger.Err(&message.EventError{Err: err}),
+			slog.Int("count", len(evs)))
+	}
+}
+
+// execer is a pool or a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// notifyEvents sends the events to the listeners in one statement.
+// In a transaction the notifications are sent only if it is committed.
+func notifyEvents(ctx context.Context, db execer, evs []message.Event) error {
+	payloads := make([]string, len(evs))
+	for i, ev := range evs {
+		payload, err := json.Marshal(eventPayload{MessageID: ev.MessageID, Status: ev.Status, At: ev.At})
+		if err != nil {
+			return err
+		}
+		payloads[i] = string(payload)
+	}
+
+	q := "select pg_notify($1, p) from unnest($2::text[]) as p"
+
+	_, err := db.Exec(ctx, q, MessageEventsChannel, payloads)
+	return err
+}
+
+// Listen holds a connection of the pool while listening. Invalid payloads are logged and skipped.
+func (b *EventBusPG) Listen(ctx context.Context, handle func(message.Event)) error {
+	conn, err := b.db.Acquire(ctx)
+	if err != nil {
+		return &message.EventError{Err: err}
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "listen "+MessageEventsChannel)
+	if err != nil {
+		return &message.EventError{Err: err}
+	}
+	defer func() {
+		// The connection goes back to the pool, so it must not receive notifications anymore.
+		unlistenCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlistenCtx, "unlisten "+MessageEventsChannel); err != nil {
+			conn.Conn().Close(unlistenCtx)
+		}
+	}()
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return &message.EventError{Err: err}
+		}
+
+		var p eventPayload
+		if err := json.Unmarshal([]byte(n.Payload), &p); err != nil {
+			b.log.Warn("invalid message event", logger.Err(err), slog.String("payload", n.Payload))
+			continue
+		}
+
+		handle(message.Event{MessageID: p.MessageID, Status: p.Status, At: p.At})
+	}
+}
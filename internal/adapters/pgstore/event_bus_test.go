@@ -0,0 +1,57 @@
+package pgstore
+
+import (
+	"context"
+	"messagio_assignment/internal/domain/message"
+	"time"
+)
+
+func (su *PGStoreTestSuite) TestEventBus() {
+	su.Run("get bus", func() {
+		bus := su.store.Events()
+		su.Require().NotNil(bus)
+	})
+
+	su.Run("publish and listen", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		received := make(chan message.Event, 1)
+		listening := make(chan error, 1)
+		go func() {
+			listening <- su.store.Events().Listen(ctx, func(ev message.Event) {
+				select {
+				case received <- ev:
+				default:
+				}
+			})
+		}()
+
+		want := message.Event{
+			MessageID: 42,
+			Status:    message.StatusProcessed,
+			At:        time.Now().UTC().Truncate(time.Microsecond),
+		}
+
+		// LISTEN is executed asynchronously, so publish until the event is received.
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			su.store.Events().Publish(ctx, want)
+
+			select {
+			case got := <-received:
+				su.Equal(want.MessageID, got.MessageID)
+				su.Equal(want.Status, got.Status)
+				su.True(want.At.Equal(got.At))
+
+				cancel()
+				su.Error(<-listening)
+				return
+			case err := <-listening:
+				su.FailNow("listen is stopped", err)
+			case <-ticker.C:
+			}
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"messagio_assignment/internal/domain/idempotency"
+	"time"
+)
+
+type IdempotencyRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepoPG(db *pgxpool.Pool) *IdempotencyRepoPG {
+	return &IdempotencyRepoPG{db: db}
+}
+
+// Reserve overwrites the expired and stale records, so expired keys don't need to be purged before reuse
+// and the key of a request lost with its replica is not blocked until it expires. The overwritten record
+// gets a new token, so the lost request can't complete or release it.
+func (r *IdempotencyRepoPG) Reserve(ctx context.Context, principal, key, fingerprint string,
+	expiresAt, staleBefore time.Time) (*idempotency.Record, bool, error) {
+	q := `insert into idempotency_keys(principal, key, fingerprint, expires_at) values($1, $2, $3, $4)
+       on conflict (principal, key) do update 
+           set fingerprint = excluded.fingerprint, status_code = null, body = null,
+               created_at = now(), expires_at = excluded.expires_at, token = excluded.token
+           where idempotency_keys.expires_at <= now() 
+              or (idempotency_keys.status_code is null and idempotency_keys.created_at < $5)
+       returning token`
+
+	var token string
+	err := r.db.QueryRow(ctx, q, principal, key, fingerprint, expiresAt, staleBefore).Scan(&token)
+	if err == nil {
+		rec := idempotency.Record{
+			Principal:   principal,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Token:       token,
+			ExpiresAt:   expiresAt,
+		}
+		return &rec, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, &idempotency.Error{Key: key, Err: err}
+	}
+
+	q = `select k.fingerprint, k.token, k.status_code, k.body, k.expires_at 
+       from idempotency_keys as k where k.principal = $1 and k.key = $2`
+
+	var (
+		rec        = idempotency.Record{Principal: principal, Key: key}
+		statusCode *int
+		body       []byte
+	)
+	err = r.db.QueryRow(ctx, q, principal, key).Scan(&rec.Fingerprint, &rec.Token, &statusCode, &body, &rec.ExpiresAt)
+	if err != nil {
+		return nil, false, &idempotency.Error{Key: key, Err: ErrGetIntoDomain(err)}
+	}
+
+	if statusCode != nil {
+		rec.Response = &idempotency.Response{StatusCode: *statusCode, Body: body}
+	}
+
+	return &rec, false, nil
+}
+
+func (r *IdempotencyRepoPG) Complete(ctx context.Context, principal, key, token string,
+	resp idempotency.Response) error {
+	q := `update idempotency_keys as k set status_code = $4, body = $5 
+       where k.principal = $1 and k.key = $2 and k.token = $3 and k.status_code is null`
+
+	ct, err := r.db.Exec(ctx, q, principal, key, token, resp.StatusCode, resp.Body)
+	if err != nil {
+		return &idempotency.Error{Key: key, Err: err}
+	}
+	if ct.RowsAffected() == 0 {
+		return &idempotency.Error{Key: key, Err: idempotency.ErrReservationLost}
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepoPG) Release(ctx context.Context, principal, key, token string) error {
+	q := `delete from idempotency_keys as k 
+       where k.principal = $1 and k.key = $2 and k.token = $3 and k.status_code is null`
+
+	ct, err := r.db.Exec(ctx, q, principal, key, token)
+	if err != nil {
+		return &idempotency.Error{Key: key, Err: err}
+	}
+	if ct.RowsAffected() == 0 {
+		return &idempotency.Error{Key: key, Err: idempotency.ErrReservationLost}
+	}
+
+	return nil
+}
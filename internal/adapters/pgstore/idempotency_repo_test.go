@@ -0,0 +1,147 @@
+package pgstore
+
+import (
+	"context"
+	"messagio_assignment/internal/domain/idempotency"
+	"time"
+)
+
+func (su *PGStoreTestSuite) IdempotencyRepo() *IdempotencyRepoPG {
+	return su.store.Idempotency()
+}
+
+func (su *PGStoreTestSuite) TestIdempotencyRepo() {
+	su.Run("get repo", func() {
+		repo := su.store.Idempotency()
+		su.Require().NotNil(repo)
+	})
+
+	ctx := context.Background()
+	expiresAt := func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+	notStale := time.Now().Add(-time.Hour)
+	client := "apikey:1"
+
+	su.Run("reserve, complete, replay", func() {
+		reservation, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+		su.NotEmpty(reservation.Token)
+
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.False(reserved)
+		su.Equal("fingerprint", rec.Fingerprint)
+		su.Nil(rec.Response)
+
+		resp := idempotency.Response{StatusCode: 201, Body: []byte(`{"id":1}`)}
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", reservation.Token, resp)
+		su.Require().NoError(err)
+
+		rec, reserved, err = su.IdempotencyRepo().Reserve(ctx, client, "key", "other fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.False(reserved)
+		su.Equal("fingerprint", rec.Fingerprint)
+		su.Equal(&resp, rec.Response)
+
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", reservation.Token, resp)
+		su.ErrorIs(err, idempotency.ErrReservationLost)
+	})
+
+	su.Run("release", func() {
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+
+		err = su.IdempotencyRepo().Release(ctx, client, "key", rec.Token)
+		su.Require().NoError(err)
+
+		_, reserved, err = su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+	})
+
+	su.Run("completed key is not released", func() {
+		rec, _, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", rec.Token, idempotency.Response{StatusCode: 201})
+		su.Require().NoError(err)
+
+		err = su.IdempotencyRepo().Release(ctx, client, "key", rec.Token)
+		su.ErrorIs(err, idempotency.ErrReservationLost)
+
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.False(reserved)
+		su.NotNil(rec.Response)
+	})
+
+	su.Run("expired key is reserved again", func() {
+		rec, _, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", time.Now().Add(-time.Second), notStale)
+		su.Require().NoError(err)
+
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", rec.Token, idempotency.Response{StatusCode: 201})
+		su.Require().NoError(err)
+
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "new fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+		su.Equal("new fingerprint", rec.Fingerprint)
+		su.Nil(rec.Response)
+	})
+	su.Run("stale reservation is reserved again", func() {
+		lost, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+
+		_, reserved, err = su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.False(reserved)
+
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "other fingerprint", expiresAt(), time.Now())
+		su.Require().NoError(err)
+		su.True(reserved)
+		su.Nil(rec.Response)
+		su.NotEqual(lost.Token, rec.Token)
+
+		// the lost request finishes late, but the key belongs to the new one
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", lost.Token, idempotency.Response{StatusCode: 201})
+		su.ErrorIs(err, idempotency.ErrReservationLost)
+		err = su.IdempotencyRepo().Release(ctx, client, "key", lost.Token)
+		su.ErrorIs(err, idempotency.ErrReservationLost)
+
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", rec.Token, idempotency.Response{StatusCode: 201})
+		su.Require().NoError(err)
+
+		// completed keys are replayed until they expire
+		rec, reserved, err = su.IdempotencyRepo().Reserve(ctx, client, "key", "other fingerprint", expiresAt(), time.Now())
+		su.Require().NoError(err)
+		su.False(reserved)
+		su.Equal("other fingerprint", rec.Fingerprint)
+		su.NotNil(rec.Response)
+	})
+	su.Run("keys are scoped by principal", func() {
+		rec, reserved, err := su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+
+		err = su.IdempotencyRepo().Complete(ctx, client, "key", rec.Token, idempotency.Response{StatusCode: 201})
+		su.Require().NoError(err)
+
+		rec, reserved, err = su.IdempotencyRepo().Reserve(ctx, "apikey:2", "key", "other", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.True(reserved)
+		su.Equal("apikey:2", rec.Principal)
+		su.Nil(rec.Response)
+
+		err = su.IdempotencyRepo().Release(ctx, "apikey:2", "key", rec.Token)
+		su.Require().NoError(err)
+
+		rec, reserved, err = su.IdempotencyRepo().Reserve(ctx, client, "key", "fingerprint", expiresAt(), notStale)
+		su.Require().NoError(err)
+		su.False(reserved)
+		su.NotNil(rec.Response)
+	})
+}
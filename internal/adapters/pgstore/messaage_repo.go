@@ -1,12 +1,43 @@
 package pgstore
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"messagio_assignment/internal/domain"
 	"messagio_assignment/internal/domain/message"
+	"slices"
+	"time"
 )
 
+// messageColumns are selected from messages as m in the order of messageFields.
+// Empty labels are selected as null, so they are scanned as nil. No callback URL is selected as empty.
+const messageColumns = "m.id, m.content, m.status, nullif(m.labels, '{}'), m.send_at, m.expires_at, " +
+	"coalesce(m.callback_url, ''), m.created_at, m.processed_at"
+
+func messageFields(msg *message.Message) []any {
+	return []any{&msg.ID, &msg.Content, &msg.Status, &msg.Labels, &msg.SendAt, &msg.ExpiresAt,
+		&msg.CallbackURL, &msg.CreatedAt, &msg.ProcessedAt}
+}
+
+// labelsOrEmpty returns the message labels for insert, the labels column is not null.
+func labelsOrEmpty(msg *message.Message) message.Labels {
+	if msg.Labels == nil {
+		return message.Labels{}
+	}
+	return msg.Labels
+}
+
+// statusOrPending returns the message status for insert, new messages are pending by default.
+func statusOrPending(msg *message.Message) message.Status {
+	if msg.Status == "" {
+		return message.StatusPending
+	}
+	return msg.Status
+}
+
 type MessageRepoPG struct {
 	db *pgxpool.Pool
 }
@@ -15,24 +46,148 @@ func NewMessageRepoPG(db *pgxpool.Pool) *MessageRepoPG {
 	return &MessageRepoPG{db: db}
 }
 
+// Create inserts the message and its outbox entry in one transaction,
+// so the message is produced by the outbox relay even if the broker is unavailable now.
 func (r *MessageRepoPG) Create(ctx context.Context, msg *message.Message) error {
-	q := "insert into messages(content, processed) values($1, $2) returning id"
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+	defer tx.Rollback(ctx)
+
+	q := `insert into messages(content, status, labels, send_at, expires_at, callback_url) 
+       values($1, $2, $3, $4, $5, nullif($6, '')) 
+       returning id, send_at, expires_at, created_at`
 
-	var id int
-	err := r.db.QueryRow(ctx, q, msg.Content, msg.Processed).Scan(&id)
+	var (
+		id        int
+		status    = statusOrPending(msg)
+		sendAt    *time.Time
+		expiresAt *time.Time
+		createdAt time.Time
+	)
+	err = tx.QueryRow(ctx, q, msg.Content, status, labelsOrEmpty(msg), msg.SendAt, msg.ExpiresAt, msg.CallbackURL).
+		Scan(&id, &sendAt, &expiresAt, &createdAt)
 	if err != nil {
 		return &message.Error{Err: ErrCreateIntoDomain(err)}
 	}
 
+	err = insertOutbox(ctx, tx, id, msg.SendAt)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+
 	msg.ID = id
+	msg.Status = status
+	msg.SendAt = sendAt
+	msg.ExpiresAt = expiresAt
+	msg.CreatedAt = createdAt
+	msg.ProcessedAt = nil
+	return nil
+}
+
+// CreateBatch inserts messages and their outbox entries in one round trip for each table.
+func (r *MessageRepoPG) CreateBatch(ctx context.Context, msgs []*message.Message) error {
+	contents := make([]string, len(msgs))
+	statuses := make([]message.Status, len(msgs))
+	labels := make([]message.Labels, len(msgs))
+	sendAts := make([]*time.Time, len(msgs))
+	expiresAts := make([]*time.Time, len(msgs))
+	callbackURLs := make([]string, len(msgs))
+	for i, msg := range msgs {
+		contents[i] = msg.Content
+		statuses[i] = statusOrPending(msg)
+		labels[i] = labelsOrEmpty(msg)
+		sendAts[i] = msg.SendAt
+		expiresAts[i] = msg.ExpiresAt
+		callbackURLs[i] = msg.CallbackURL
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+	defer tx.Rollback(ctx)
+
+	// ids are generated in the order of selected rows, so sorted ids match the input order
+	q := `insert into messages(content, status, labels, send_at, expires_at, callback_url)
+       select t.content, t.status, t.labels, t.send_at, t.expires_at, nullif(t.callback_url, '')
+       from unnest($1::varchar[], $2::varchar[], $3::jsonb[], $4::timestamptz[], $5::timestamptz[],
+                   $6::varchar[]) 
+           with ordinality as t(content, status, labels, send_at, expires_at, callback_url, ord)
+       order by t.ord
+       returning id, send_at, expires_at, created_at`
+
+	rows, err := tx.Query(ctx, q, contents, statuses, labels, sendAts, expiresAts, callbackURLs)
+	if err != nil {
+		return &message.Error{Err: ErrCreateIntoDomain(err)}
+	}
+
+	created := make([]message.Message, 0, len(msgs))
+	for rows.Next() {
+		var msg message.Message
+
+		err = rows.Scan(&msg.ID, &msg.SendAt, &msg.ExpiresAt, &msg.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return &message.Error{Err: err}
+		}
+
+		created = append(created, msg)
+	}
+	if err = rows.Err(); err != nil {
+		return &message.Error{Err: ErrCreateIntoDomain(err)}
+	}
+	if len(created) != len(msgs) {
+		return &message.Error{Err: domain.ErrNotCreated}
+	}
+
+	slices.SortFunc(created, func(a, b message.Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	ids := make([]int, len(created))
+	for i := range created {
+		ids[i] = created[i].ID
+	}
+
+	// scheduled messages are written to the outbox by OutboxRepoPG.EnqueueScheduled
+	q = `insert into outbox(message_id) 
+       select t.id from unnest($1::int[], $2::timestamptz[]) as t(id, send_at)
+       where t.send_at is null or t.send_at <= now()`
+
+	_, err = tx.Exec(ctx, q, ids, sendAts)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return &message.Error{Err: err}
+	}
+
+	for i, msg := range msgs {
+		msg.ID = created[i].ID
+		msg.Status = statuses[i]
+		msg.SendAt = created[i].SendAt
+		msg.ExpiresAt = created[i].ExpiresAt
+		msg.CreatedAt = created[i].CreatedAt
+		msg.ProcessedAt = nil
+	}
+
 	return nil
 }
 
 func (r *MessageRepoPG) GetByID(ctx context.Context, id int) (*message.Message, error) {
-	q := "select m.id, m.content, m.processed from messages as m where m.id = $1"
+	q := "select " + messageColumns + " from messages as m where m.id = $1 and m.deleted_at is null"
 
 	var msg message.Message
-	err := r.db.QueryRow(ctx, q, id).Scan(&msg.ID, &msg.Content, &msg.Processed)
+	err := r.db.QueryRow(ctx, q, id).Scan(messageFields(&msg)...)
 	if err != nil {
 		return nil, &message.ErrorWithID{ID: id, Err: ErrGetIntoDomain(err)}
 	}
@@ -40,29 +195,352 @@ func (r *MessageRepoPG) GetByID(ctx context.Context, id int) (*message.Message,
 	return &msg, nil
 }
 
-func (r *MessageRepoPG) GetStats(ctx context.Context) (*message.Stats, error) {
-	q := `select (select count(*) from messages as m), 
-       (select count(*) from messages as m where m.processed = true)`
+func (r *MessageRepoPG) List(ctx context.Context, filter message.ListFilter) ([]*message.Message, error) {
+	var where whereBuilder
+
+	where.And("m.deleted_at is null")
+	if filter.Status != nil {
+		where.And("m.status = " + where.Arg(*filter.Status))
+	}
+	if len(filter.Labels) > 0 {
+		where.And("m.labels @> " + where.Arg(filter.Labels))
+	}
+	if filter.MinID != nil {
+		where.And("m.id >= " + where.Arg(*filter.MinID))
+	}
+	if filter.MaxID != nil {
+		where.And("m.id <= " + where.Arg(*filter.MaxID))
+	}
+	if filter.CreatedFrom != nil {
+		where.And("m.created_at >= " + where.Arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		where.And("m.created_at < " + where.Arg(*filter.CreatedTo))
+	}
+	if filter.AfterID != 0 {
+		where.And("m.id > " + where.Arg(filter.AfterID))
+	}
+
+	q := "select " + messageColumns + " from messages as m" + where.String() +
+		" order by m.id limit " + where.Arg(filter.Limit)
+
+	rows, err := r.db.Query(ctx, q, where.Args()...)
+	if err != nil {
+		return nil, &message.Error{Err: err}
+	}
+	defer rows.Close()
+
+	msgs := make([]*message.Message, 0, filter.Limit)
+	for rows.Next() {
+		var msg message.Message
+
+		err = rows.Scan(messageFields(&msg)...)
+		if err != nil {
+			return nil, &message.Error{Err: err}
+		}
+
+		msgs = append(msgs, &msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &message.Error{Err: err}
+	}
+
+	return msgs, nil
+}
+
+// Search uses the content_tsv generated column and its GIN index.
+func (r *MessageRepoPG) Search(ctx context.Context, query message.SearchQuery) ([]*message.SearchHit, error) {
+	q := `select ` + messageColumns + `, ts_rank_cd(m.content_tsv, q.query)::float8 as rank,
+              ts_headline('simple', m.content, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=3')
+       from messages as m, websearch_to_tsquery('simple', $1) as q(query)
+       where m.deleted_at is null and m.content_tsv @@ q.query
+       order by rank desc, m.id desc
+       offset $2 limit $3`
+
+	rows, err := r.db.Query(ctx, q, query.Query, query.Offset, query.Limit)
+	if err != nil {
+		return nil, &message.Error{Err: err}
+	}
+	defer rows.Close()
+
+	hits := make([]*message.SearchHit, 0, query.Limit)
+	for rows.Next() {
+		hit := message.SearchHit{Message: &message.Message{}}
+
+		err = rows.Scan(append(messageFields(hit.Message), &hit.Rank, &hit.Highlight)...)
+		if err != nil {
+			return nil, &message.Error{Err: err}
+		}
+
+		hits = append(hits, &hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &message.Error{Err: err}
+	}
+
+	return hits, nil
+}
+
+func (r *MessageRepoPG) GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error) {
+	q := `select m.status, count(*) from messages as m 
+       where m.deleted_at is null
+         and ($1::timestamptz is null or m.created_at >= $1) 
+         and ($2::timestamptz is null or m.created_at < $2)
+         and ($3::jsonb is null or m.labels @> $3)
+       group by m.status`
 
-	var stats message.Stats
-	err := r.db.QueryRow(ctx, q).Scan(&stats.All, &stats.Processed)
+	rows, err := r.db.Query(ctx, q, filter.From, filter.To, filter.Labels)
 	if err != nil {
 		return nil, &message.StatsError{Err: err}
 	}
 
+	stats := message.Stats{ByStatus: make(map[message.Status]int, len(message.Statuses))}
+	for _, status := range message.Statuses {
+		stats.ByStatus[status] = 0
+	}
+
+	for rows.Next() {
+		var (
+			status message.Status
+			count  int
+		)
+
+		err = rows.Scan(&status, &count)
+		if err != nil {
+			rows.Close()
+			return nil, &message.StatsError{Err: err}
+		}
+
+		stats.ByStatus[status] = count
+		stats.All += count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &message.StatsError{Err: err}
+	}
+
+	q = `select (select count(*) from messages as m 
+               where m.status = 'processed' and m.deleted_at is null
+                 and ($1::timestamptz is null or m.processed_at >= $1) 
+                 and ($2::timestamptz is null or m.processed_at < $2)
+                 and ($3::jsonb is null or m.labels @> $3)),
+       (select count(*) from outbox)`
+
+	err = r.db.QueryRow(ctx, q, filter.From, filter.To, filter.Labels).Scan(&stats.Processed, &stats.OutboxPending)
+	if err != nil {
+		return nil, &message.StatsError{Err: err}
+	}
+
+	if filter.GroupByLabel != "" {
+		stats.ByLabel, err = r.getStatsByLabel(ctx, filter)
+		if err != nil {
+			return nil, &message.StatsError{Err: err}
+		}
+	}
+
+	if filter.Bucket != message.BucketNone {
+		stats.Series, err = r.getStatsSeries(ctx, filter)
+		if err != nil {
+			return nil, &message.StatsError{Err: err}
+		}
+	}
+
 	return &stats, nil
 }
 
-func (r *MessageRepoPG) UpdateProcessed(ctx context.Context, msg *message.Message) error {
-	q := "update messages as m set processed = $1 where m.id = $2"
+// getStatsSeries needs the closed window. Latency is computed for messages processed in the bucket.
+func (r *MessageRepoPG) getStatsSeries(ctx context.Context, filter message.StatsFilter) ([]message.StatsBucket, error) {
+	q := `select b.start, c.created, p.processed, p.latency
+       from generate_series(date_trunc($1::text, $2::timestamptz), $3::timestamptz, 
+                            ('1 ' || $1::text)::interval) as b(start)
+       cross join lateral (
+           select count(*) as created from messages as m
+           where m.deleted_at is null
+             and ($4::jsonb is null or m.labels @> $4)
+             and m.created_at >= greatest(b.start, $2) 
+             and m.created_at < least(b.start + ('1 ' || $1::text)::interval, $3)
+       ) as c
+       cross join lateral (
+           select count(*) as processed, 
+                  percentile_cont(array [0.5, 0.95, 0.99]) within group 
+                      (order by extract(epoch from m.processed_at - m.created_at)) as latency
+           from messages as m
+           where m.status = 'processed' and m.deleted_at is null
+             and ($4::jsonb is null or m.labels @> $4)
+             and m.processed_at >= greatest(b.start, $2) 
+             and m.processed_at < least(b.start + ('1 ' || $1::text)::interval, $3)
+       ) as p
+       where b.start < $3
+       order by b.start`
+
+	rows, err := r.db.Query(ctx, q, string(filter.Bucket), *filter.From, *filter.To, filter.Labels)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := make([]message.StatsBucket, 0)
+	for rows.Next() {
+		var (
+			bucket  message.StatsBucket
+			latency []float64
+		)
+
+		err = rows.Scan(&bucket.Start, &bucket.Created, &bucket.Processed, &latency)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(latency) == 3 {
+			bucket.Latency = &message.Latency{
+				P50: secondsToDuration(latency[0]),
+				P95: secondsToDuration(latency[1]),
+				P99: secondsToDuration(latency[2]),
+			}
+		}
+
+		series = append(series, bucket)
+	}
+
+	return series, rows.Err()
+}
+
+// getStatsByLabel counts created and processed messages in the window for each value of the label.
+func (r *MessageRepoPG) getStatsByLabel(ctx context.Context, filter message.StatsFilter) ([]message.LabelStats, error) {
+	q := `with w as (
+           select coalesce(m.labels ->> $3::text, '') as value,
+                  ($1::timestamptz is null or m.created_at >= $1)
+                      and ($2::timestamptz is null or m.created_at < $2) as created,
+                  m.status = 'processed'
+                      and ($1::timestamptz is null or m.processed_at >= $1)
+                      and ($2::timestamptz is null or m.processed_at < $2) as processed
+           from messages as m
+           where m.deleted_at is null and ($4::jsonb is null or m.labels @> $4)
+       )
+       select w.value, count(*) filter (where w.created), count(*) filter (where w.processed)
+       from w
+       where w.created or w.processed
+       group by w.value
+       order by w.value`
 
-	ct, err := r.db.Exec(ctx, q, msg.Processed, msg.ID)
+	rows, err := r.db.Query(ctx, q, filter.From, filter.To, filter.GroupByLabel, filter.Labels)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]message.LabelStats, 0)
+	for rows.Next() {
+		var group message.LabelStats
+
+		err = rows.Scan(&group.Value, &group.All, &group.Processed)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
+
+// updateStatusQuery moves the messages with ids $2 from the status $3 to $1. Webhook deliveries
+// of result statuses ($4) are enqueued in the same statement, so they are not lost if the service stops
+// right after the update. It returns the id and processed_at of the updated messages.
+const updateStatusQuery = `with updated as (
+           update messages as m 
+           set status = $1, processed_at = case when $1 = 'processed' then now() else m.processed_at end
+           where m.id = any($2) and m.status = $3 and m.deleted_at is null
+           returning m.id, m.status, m.callback_url, m.processed_at
+       ), delivery as (
+           insert into webhook_deliveries(message_id, url, message_status, event_at)
+           select u.id, u.callback_url, u.status, coalesce(u.processed_at, now()) from updated as u
+           where $4 and u.callback_url is not null
+       )
+       select u.id, u.processed_at from updated as u`
+
+// UpdateStatus enqueues the webhook delivery in the same statement as the update.
+func (r *MessageRepoPG) UpdateStatus(ctx context.Context, msg *message.Message, from message.Status) error {
+	var (
+		id          int
+		processedAt *time.Time
+	)
+	err := r.db.QueryRow(ctx, updateStatusQuery, msg.Status, []int{msg.ID}, from, msg.Status.IsResult()).
+		Scan(&id, &processedAt)
+	if err == nil {
+		msg.ProcessedAt = processedAt
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
 		return &message.ErrorWithID{ID: msg.ID, Err: err}
 	}
-	if ct.RowsAffected() == 0 {
+
+	q := "select exists(select 1 from messages as m where m.id = $1 and m.deleted_at is null)"
+
+	var exists bool
+	err = r.db.QueryRow(ctx, q, msg.ID).Scan(&exists)
+	switch {
+	case err != nil:
+		return &message.ErrorWithID{ID: msg.ID, Err: err}
+	case !exists:
 		return &message.ErrorWithID{ID: msg.ID, Err: domain.ErrNotFound}
+	default:
+		return &message.ErrorWithID{ID: msg.ID, Err: domain.ErrConflict}
+	}
+}
+
+// Delete soft deletes the message and drops its pending outbox entry, so the message is not produced.
+func (r *MessageRepoPG) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return &message.ErrorWithID{ID: id, Err: err}
+	}
+	defer tx.Rollback(ctx)
+
+	q := "update messages as m set deleted_at = now() where m.id = $1 and m.deleted_at is null"
+
+	tag, err := tx.Exec(ctx, q, id)
+	if err != nil {
+		return &message.ErrorWithID{ID: id, Err: err}
+	}
+	if tag.RowsAffected() == 0 {
+		return &message.ErrorWithID{ID: id, Err: domain.ErrNotFound}
+	}
+
+	q = "delete from outbox as o where o.message_id = $1"
+
+	_, err = tx.Exec(ctx, q, id)
+	if err != nil {
+		return &message.ErrorWithID{ID: id, Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return &message.ErrorWithID{ID: id, Err: err}
 	}
 
 	return nil
 }
+
+// Purge deletes one batch in its own statement, so rows are locked only for a short time.
+// Locked rows are skipped and purged by the next call. Outbox entries are deleted by cascade.
+func (r *MessageRepoPG) Purge(ctx context.Context, before time.Time, limit int) (int, error) {
+	q := `delete from messages
+       where id in (
+           select m.id from messages as m
+           where m.deleted_at < $1 or (m.status = 'processed' and m.processed_at < $1)
+           order by m.id
+           limit $2
+           for update skip locked
+       )`
+
+	tag, err := r.db.Exec(ctx, q, before, limit)
+	if err != nil {
+		return 0, &message.Error{Err: err}
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"messagio_assignment/internal/domain"
 	"messagio_assignment/internal/domain/message"
+	"time"
 )
 
 func (su *PGStoreTestSuite) MsgRepo() *MessageRepoPG {
@@ -33,17 +34,17 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 			{
 				Name: "simple",
 				Msg: &message.Message{
-					ID:        0,
-					Content:   "this is content that is string",
-					Processed: false,
+					ID:      0,
+					Content: "this is content that is string",
+					Status:  message.StatusPending,
 				},
 			},
 			{
 				Name: "without default fields",
 				Msg: &message.Message{
-					ID:        1,
-					Content:   "default fields not exist, you know",
-					Processed: true,
+					ID:      1,
+					Content: "default fields not exist, you know",
+					Status:  message.StatusProcessed,
 				},
 			},
 		}
@@ -52,6 +53,8 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 			su.Run(tc.Name, func() {
 				err := su.MsgRepo().Create(context.Background(), tc.Msg)
 				su.NoError(err)
+				su.False(tc.Msg.CreatedAt.IsZero())
+				su.Nil(tc.Msg.ProcessedAt) // processed_at is set by UpdateStatus
 
 				gotMsg, err := su.MsgRepo().GetByID(context.Background(), tc.Msg.ID)
 				su.NoError(err)
@@ -60,12 +63,276 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 		}
 	})
 
+	su.Run("create batch", func() {
+		messages := []*message.Message{
+			{Content: "first"},
+			{Content: "second", Status: message.StatusProcessed},
+			{Content: "third"},
+		}
+
+		err := su.MsgRepo().CreateBatch(context.Background(), messages)
+		su.Require().NoError(err)
+
+		for i, msg := range messages {
+			if i > 0 {
+				su.Greater(msg.ID, messages[i-1].ID)
+			}
+
+			gotMsg, err := su.MsgRepo().GetByID(context.Background(), msg.ID)
+			su.Require().NoError(err)
+			su.Equal(msg, gotMsg)
+		}
+
+		su.Equal(len(messages), su.outboxEntries())
+	})
+
+	su.Run("list", func() {
+		messages := []*message.Message{
+			{Content: "first", Status: message.StatusPending},
+			{Content: "second", Status: message.StatusProcessed},
+			{Content: "third", Status: message.StatusPending},
+			{Content: "fourth", Status: message.StatusProcessed},
+			{Content: "fifth", Status: message.StatusPending},
+		}
+		for _, msg := range messages {
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+		}
+
+		processed, pending := message.StatusProcessed, message.StatusPending
+		minID, maxID := messages[1].ID, messages[3].ID
+		future := time.Now().Add(time.Hour)
+
+		tcases := []struct {
+			Name   string
+			Filter message.ListFilter
+			Want   []*message.Message
+		}{
+			{
+				Name:   "all",
+				Filter: message.ListFilter{Limit: 10},
+				Want:   messages,
+			},
+			{
+				Name:   "limit",
+				Filter: message.ListFilter{Limit: 2},
+				Want:   messages[:2],
+			},
+			{
+				Name:   "after id",
+				Filter: message.ListFilter{AfterID: messages[2].ID, Limit: 10},
+				Want:   messages[3:],
+			},
+			{
+				Name:   "processed",
+				Filter: message.ListFilter{Status: &processed, Limit: 10},
+				Want:   []*message.Message{messages[1], messages[3]},
+			},
+			{
+				Name:   "pending after id",
+				Filter: message.ListFilter{Status: &pending, AfterID: messages[0].ID, Limit: 10},
+				Want:   []*message.Message{messages[2], messages[4]},
+			},
+			{
+				Name:   "id range",
+				Filter: message.ListFilter{MinID: &minID, MaxID: &maxID, Limit: 10},
+				Want:   messages[1:4],
+			},
+			{
+				Name:   "created in future",
+				Filter: message.ListFilter{CreatedFrom: &future, Limit: 10},
+				Want:   []*message.Message{},
+			},
+			{
+				Name:   "created before future",
+				Filter: message.ListFilter{CreatedTo: &future, Limit: 10},
+				Want:   messages,
+			},
+		}
+
+		for _, tc := range tcases {
+			got, err := su.MsgRepo().List(context.Background(), tc.Filter)
+			su.Require().NoError(err, tc.Name)
+			su.Equal(tc.Want, got, tc.Name)
+		}
+	})
+
+	su.Run("labels", func() {
+		messages := []*message.Message{
+			{Content: "first", Labels: message.Labels{"source": "crm", "customer": "42"}},
+			{Content: "second", Labels: message.Labels{"source": "crm"}, Status: message.StatusProcessed},
+			{Content: "third", Labels: message.Labels{"source": "billing", "customer": "42"}},
+			{Content: "fourth"},
+		}
+		err := su.MsgRepo().CreateBatch(context.Background(), messages[:2])
+		su.Require().NoError(err)
+		for _, msg := range messages[2:] {
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+		}
+
+		for _, msg := range messages {
+			gotMsg, err := su.MsgRepo().GetByID(context.Background(), msg.ID)
+			su.Require().NoError(err)
+			su.Equal(msg, gotMsg)
+		}
+
+		got, err := su.MsgRepo().List(context.Background(), message.ListFilter{
+			Labels: message.Labels{"customer": "42"}, Limit: 10,
+		})
+		su.Require().NoError(err)
+		su.Equal([]*message.Message{messages[0], messages[2]}, got)
+
+		got, err = su.MsgRepo().List(context.Background(), message.ListFilter{
+			Labels: message.Labels{"customer": "42", "source": "crm"}, Limit: 10,
+		})
+		su.Require().NoError(err)
+		su.Equal([]*message.Message{messages[0]}, got)
+
+		gotStats, err := su.MsgRepo().GetStats(context.Background(), message.StatsFilter{
+			Labels:       message.Labels{"source": "crm"},
+			GroupByLabel: "customer",
+		})
+		su.Require().NoError(err)
+		su.Equal(2, gotStats.All)
+		su.Equal(1, gotStats.Processed)
+		su.Equal([]message.LabelStats{
+			{Value: "", All: 1, Processed: 1},
+			{Value: "42", All: 1, Processed: 0},
+		}, gotStats.ByLabel)
+
+		gotStats, err = su.MsgRepo().GetStats(context.Background(), message.StatsFilter{GroupByLabel: "source"})
+		su.Require().NoError(err)
+		su.Equal(4, gotStats.All)
+		su.Equal([]message.LabelStats{
+			{Value: "", All: 1, Processed: 0},
+			{Value: "billing", All: 1, Processed: 0},
+			{Value: "crm", All: 2, Processed: 1},
+		}, gotStats.ByLabel)
+	})
+
+	su.Run("search", func() {
+		messages := []*message.Message{
+			{Content: "the quick brown fox"},
+			{Content: "a lazy dog"},
+			{Content: "fox and fox again"},
+			{Content: "deleted fox"},
+		}
+		for _, msg := range messages {
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+		}
+
+		err := su.MsgRepo().Delete(context.Background(), messages[3].ID)
+		su.Require().NoError(err)
+
+		hits, err := su.MsgRepo().Search(context.Background(), message.SearchQuery{Query: "fox", Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(hits, 2)
+
+		su.Equal(messages[2], hits[0].Message) // more matches rank higher
+		su.Equal(messages[0], hits[1].Message)
+		su.Greater(hits[0].Rank, hits[1].Rank)
+		su.Contains(hits[1].Highlight, "<mark>fox</mark>")
+
+		hits, err = su.MsgRepo().Search(context.Background(), message.SearchQuery{Query: "fox", Offset: 1, Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(hits, 1)
+		su.Equal(messages[0], hits[0].Message)
+
+		hits, err = su.MsgRepo().Search(context.Background(), message.SearchQuery{Query: "fox -quick", Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(hits, 1)
+		su.Equal(messages[2], hits[0].Message)
+
+		hits, err = su.MsgRepo().Search(context.Background(), message.SearchQuery{Query: "cat", Limit: 10})
+		su.Require().NoError(err)
+		su.Empty(hits)
+	})
+
+	su.Run("delete", func() {
+		messages := []*message.Message{
+			{Content: "first"},
+			{Content: "second", Status: message.StatusProcessed},
+		}
+		for _, msg := range messages {
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+		}
+
+		err := su.MsgRepo().Delete(context.Background(), messages[0].ID)
+		su.Require().NoError(err)
+
+		err = su.MsgRepo().Delete(context.Background(), messages[0].ID)
+		su.ErrorIs(err, domain.ErrNotFound)
+
+		_, err = su.MsgRepo().GetByID(context.Background(), messages[0].ID)
+		su.ErrorIs(err, domain.ErrNotFound)
+
+		msg := *messages[0]
+		msg.Status = message.StatusQueued
+		err = su.MsgRepo().UpdateStatus(context.Background(), &msg, message.StatusPending)
+		su.ErrorIs(err, domain.ErrNotFound)
+
+		got, err := su.MsgRepo().List(context.Background(), message.ListFilter{Limit: 10})
+		su.Require().NoError(err)
+		su.Equal(messages[1:], got)
+
+		gotStats, err := su.MsgRepo().GetStats(context.Background(), message.StatsFilter{})
+		su.Require().NoError(err)
+		su.Equal(1, gotStats.All)
+		su.Equal(1, gotStats.Processed)
+		su.Equal(1, gotStats.OutboxPending) // the deleted message is not produced
+	})
+
+	su.Run("purge", func() {
+		messages := []*message.Message{
+			{Content: "deleted"},
+			{Content: "processed"},
+			{Content: "pending"},
+			{Content: "deleted too"},
+		}
+		for _, msg := range messages {
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+		}
+
+		messages[1].Status = message.StatusProcessed
+		err := su.MsgRepo().UpdateStatus(context.Background(), messages[1], message.StatusPending)
+		su.Require().NoError(err)
+
+		for _, msg := range []*message.Message{messages[0], messages[3]} {
+			err := su.MsgRepo().Delete(context.Background(), msg.ID)
+			su.Require().NoError(err)
+		}
+
+		past := time.Now().Add(-time.Hour)
+		n, err := su.MsgRepo().Purge(context.Background(), past, 10)
+		su.Require().NoError(err)
+		su.Equal(0, n)
+
+		future := time.Now().Add(time.Hour)
+		n, err = su.MsgRepo().Purge(context.Background(), future, 2)
+		su.Require().NoError(err)
+		su.Equal(2, n)
+
+		n, err = su.MsgRepo().Purge(context.Background(), future, 2)
+		su.Require().NoError(err)
+		su.Equal(1, n)
+
+		got, err := su.MsgRepo().List(context.Background(), message.ListFilter{Limit: 10})
+		su.Require().NoError(err)
+		su.Equal([]*message.Message{messages[2]}, got)
+
+		su.Equal(1, su.outboxEntries())
+	})
+
 	su.Run("stats", func() {
 		notProcessed := func() *message.Message {
-			return &message.Message{ID: 0, Content: "not processed", Processed: false}
+			return &message.Message{ID: 0, Content: "not processed", Status: message.StatusPending}
 		}
 		processed := func() *message.Message {
-			return &message.Message{ID: 0, Content: "processed", Processed: true}
+			return &message.Message{ID: 0, Content: "processed", Status: message.StatusProcessed}
 		}
 
 		su.Run("with create", func() {
@@ -78,32 +345,52 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 					Name:     "0 all, 0 processed",
 					Messages: []*message.Message{},
 					WantStats: &message.Stats{
-						All:       0,
-						Processed: 0,
+						All:           0,
+						Processed:     0,
+						OutboxPending: 0,
+						ByStatus: statusCounts(map[message.Status]int{
+							message.StatusPending:   0,
+							message.StatusProcessed: 0,
+						}),
 					},
 				},
 				{
 					Name:     "1 all, 0 processed",
 					Messages: []*message.Message{notProcessed()},
 					WantStats: &message.Stats{
-						All:       1,
-						Processed: 0,
+						All:           1,
+						Processed:     0,
+						OutboxPending: 1,
+						ByStatus: statusCounts(map[message.Status]int{
+							message.StatusPending:   1,
+							message.StatusProcessed: 0,
+						}),
 					},
 				},
 				{
 					Name:     "1 all, 1 processed",
 					Messages: []*message.Message{processed()},
 					WantStats: &message.Stats{
-						All:       1,
-						Processed: 1,
+						All:           1,
+						Processed:     1,
+						OutboxPending: 1,
+						ByStatus: statusCounts(map[message.Status]int{
+							message.StatusPending:   0,
+							message.StatusProcessed: 1,
+						}),
 					},
 				},
 				{
 					Name:     "2 all, 1 processed",
 					Messages: []*message.Message{processed(), notProcessed()},
 					WantStats: &message.Stats{
-						All:       2,
-						Processed: 1,
+						All:           2,
+						Processed:     1,
+						OutboxPending: 2,
+						ByStatus: statusCounts(map[message.Status]int{
+							message.StatusPending:   1,
+							message.StatusProcessed: 1,
+						}),
 					},
 				},
 				{
@@ -111,8 +398,13 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 					Messages: []*message.Message{processed(), notProcessed(), processed(),
 						processed(), notProcessed(), notProcessed(), notProcessed()},
 					WantStats: &message.Stats{
-						All:       7,
-						Processed: 3,
+						All:           7,
+						Processed:     3,
+						OutboxPending: 7,
+						ByStatus: statusCounts(map[message.Status]int{
+							message.StatusPending:   4,
+							message.StatusProcessed: 3,
+						}),
 					},
 				},
 			}
@@ -124,19 +416,70 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 						su.NoError(err)
 					}
 
-					gotStats, err := su.MsgRepo().GetStats(context.Background())
+					gotStats, err := su.MsgRepo().GetStats(context.Background(), message.StatsFilter{})
 					su.NoError(err)
 					su.Equal(tc.WantStats, gotStats)
 				})
 			}
 		})
 
-		su.Run("with update", func() {
-			err := su.MsgRepo().UpdateProcessed(context.Background(), &message.Message{
-				ID:        1,
-				Content:   "123",
-				Processed: true,
+		su.Run("series", func() {
+			messages := []*message.Message{notProcessed(), notProcessed(), notProcessed()}
+			for _, msg := range messages {
+				err := su.MsgRepo().Create(context.Background(), msg)
+				su.Require().NoError(err)
+			}
+
+			messages[0].Status = message.StatusProcessed
+			err := su.MsgRepo().UpdateStatus(context.Background(), messages[0], message.StatusPending)
+			su.Require().NoError(err)
+
+			to := time.Now().Add(time.Minute)
+			from := to.Add(-5 * time.Minute)
+
+			gotStats, err := su.MsgRepo().GetStats(context.Background(), message.StatsFilter{
+				From:   &from,
+				To:     &to,
+				Bucket: message.BucketMinute,
 			})
+			su.Require().NoError(err)
+
+			su.Equal(3, gotStats.All)
+			su.Equal(1, gotStats.Processed)
+			su.Len(gotStats.Series, 6) // the first bucket is truncated to the minute
+
+			created, processed := 0, 0
+			for _, b := range gotStats.Series {
+				created += b.Created
+				processed += b.Processed
+
+				if b.Processed > 0 {
+					su.Require().NotNil(b.Latency)
+					su.LessOrEqual(b.Latency.P50, b.Latency.P99)
+				} else {
+					su.Nil(b.Latency)
+				}
+			}
+			su.Equal(3, created)
+			su.Equal(1, processed)
+
+			past := from.Add(-time.Hour)
+			gotStats, err = su.MsgRepo().GetStats(context.Background(), message.StatsFilter{
+				From: &past,
+				To:   &from,
+			})
+			su.Require().NoError(err)
+			su.Equal(0, gotStats.All)
+			su.Equal(0, gotStats.Processed)
+			su.Nil(gotStats.Series)
+		})
+
+		su.Run("with update", func() {
+			err := su.MsgRepo().UpdateStatus(context.Background(), &message.Message{
+				ID:      1,
+				Content: "123",
+				Status:  message.StatusProcessed,
+			}, message.StatusPending)
 			su.ErrorIs(err, domain.ErrNotFound)
 
 			messages := []*message.Message{
@@ -149,43 +492,64 @@ func (su *PGStoreTestSuite) TestMessageRepo() {
 				su.NoError(err)
 			}
 
-			processedCount := 0
+			counts := map[message.Status]int{message.StatusPending: len(messages)}
 
-			checkProcessedUpdate := func(i int, processed bool) {
-				if messages[i].Processed != processed {
-					if processed {
-						processedCount++
-					} else {
-						processedCount--
-					}
-				}
+			checkStatusUpdate := func(i int, status message.Status) {
+				from := messages[i].Status
+				counts[from]--
+				counts[status]++
 
-				messages[i].Processed = processed
-				err := su.MsgRepo().UpdateProcessed(context.Background(), messages[i])
+				messages[i].Status = status
+				err := su.MsgRepo().UpdateStatus(context.Background(), messages[i], from)
 				su.NoError(err)
 
+				if status == message.StatusProcessed {
+					su.NotNil(messages[i].ProcessedAt)
+				} else {
+					su.Nil(messages[i].ProcessedAt)
+				}
+
 				gotMsg, err := su.MsgRepo().GetByID(context.Background(), messages[i].ID)
 				su.NoError(err)
 				su.NotNil(gotMsg)
 				su.Equal(messages[i], gotMsg)
 
 				wantStats := &message.Stats{
-					All:       len(messages),
-					Processed: processedCount,
+					All:           len(messages),
+					Processed:     counts[message.StatusProcessed],
+					OutboxPending: len(messages),
+					ByStatus:      statusCounts(counts),
 				}
-				gotStats, err := su.MsgRepo().GetStats(context.Background())
+				gotStats, err := su.MsgRepo().GetStats(context.Background(), message.StatsFilter{})
 				su.NoError(err)
 				su.NotNil(gotMsg)
 				su.Equal(wantStats, gotStats)
 			}
 
 			for i := range messages {
-				checkProcessedUpdate(i, true)
+				checkStatusUpdate(i, message.StatusQueued)
 			}
 
 			for i := range messages {
-				checkProcessedUpdate(i, false)
+				checkStatusUpdate(i, message.StatusProcessed)
 			}
+
+			stale := *messages[0]
+			stale.Status = message.StatusFailed
+			err = su.MsgRepo().UpdateStatus(context.Background(), &stale, message.StatusQueued)
+			su.ErrorIs(err, domain.ErrConflict)
+
+			var wantErr *message.ErrorWithID
+			su.ErrorAs(err, &wantErr)
 		})
 	})
 }
+
+// statusCounts fills the counts of the missing statuses with zeros, as GetStats does.
+func statusCounts(counts map[message.Status]int) map[message.Status]int {
+	all := make(map[message.Status]int, len(message.Statuses))
+	for _, status := range message.Statuses {
+		all[status] = counts[status]
+	}
+	return all
+}
@@ -0,0 +1,178 @@
+package pgstore
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"messagio_assignment/internal/domain/message"
+	"slices"
+	"time"
+)
+
+type OutboxRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepoPG(db *pgxpool.Pool) *OutboxRepoPG {
+	return &OutboxRepoPG{db: db}
+}
+
+// insertOutbox writes the message to the outbox, unless it is scheduled for later.
+// Scheduled messages are written by EnqueueScheduled.
+func insertOutbox(ctx context.Context, tx pgx.Tx, messageID int, sendAt *time.Time) error {
+	q := `insert into outbox(message_id) 
+       select $1 where $2::timestamptz is null or $2 <= now()`
+
+	_, err := tx.Exec(ctx, q, messageID, sendAt)
+	return err
+}
+
+// RelayPending locks pending entries with skip locked, so several relays can work concurrently.
+// Entries are deleted and pending messages are marked as queued only after relay succeeds,
+// so messages are delivered at least once and the outbox holds only pending entries.
+func (r *OutboxRepoPG) RelayPending(ctx context.Context, limit int,
+	relay func(msgs []*message.Message) error) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+	defer tx.Rollback(ctx)
+
+	q := `select o.id, ` + messageColumns + ` 
+       from outbox as o join messages as m on m.id = o.message_id
+       order by o.id
+       limit $1
+       for update of o skip locked`
+
+	rows, err := tx.Query(ctx, q, limit)
+	if err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+
+	var (
+		ids  []int64
+		msgs []*message.Message
+	)
+	for rows.Next() {
+		var id int64
+		var msg message.Message
+
+		err = rows.Scan(append([]any{&id}, messageFields(&msg)...)...)
+		if err != nil {
+			rows.Close()
+			return 0, &message.OutboxError{Err: err}
+		}
+
+		ids = append(ids, id)
+		msgs = append(msgs, &msg)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// cancelled and expired messages are dropped, their entries are deleted too
+	var expired []int
+	now := time.Now()
+	msgs = slices.DeleteFunc(msgs, func(msg *message.Message) bool {
+		if msg.Status == message.StatusPending && msg.Expired(now) &&
+			msg.Status.CanTransitionTo(message.StatusExpired) {
+			expired = append(expired, msg.ID)
+			return true
+		}
+		return msg.Status == message.StatusCancelled
+	})
+
+	if len(msgs) > 0 {
+		err = relay(msgs)
+		if err != nil {
+			return 0, &message.OutboxError{Err: err}
+		}
+	}
+
+	if len(expired) > 0 {
+		err = expireMessages(ctx, tx, expired)
+		if err != nil {
+			return 0, &message.OutboxError{Err: err}
+		}
+	}
+
+	// the processor may already have reported a later status
+	q = `with sent as (
+           delete from outbox as o where o.id = any($1)
+           returning o.message_id
+       )
+       update messages as m set status = 'queued' 
+       where m.id in (select sent.message_id from sent) and m.status = 'pending'`
+
+	_, err = tx.Exec(ctx, q, ids)
+	if err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+
+	return len(ids), nil
+}
+
+// expireMessages moves the pending messages to expired the same way as MessageRepoPG.UpdateStatus,
+// so their webhooks are enqueued, and notifies the event listeners when the transaction is committed.
+func expireMessages(ctx context.Context, tx pgx.Tx, ids []int) error {
+	rows, err := tx.Query(ctx, updateStatusQuery, message.StatusExpired, ids, message.StatusPending,
+		message.StatusExpired.IsResult())
+	if err != nil {
+		return err
+	}
+
+	var evs []message.Event
+	now := time.Now()
+	for rows.Next() {
+		var processedAt *time.Time
+		ev := message.Event{Status: message.StatusExpired, At: now}
+
+		err = rows.Scan(&ev.MessageID, &processedAt)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		evs = append(evs, ev)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if len(evs) == 0 {
+		return nil
+	}
+
+	return notifyEvents(ctx, tx, evs)
+}
+
+// EnqueueScheduled locks due messages with skip locked, so several schedulers can work concurrently.
+// The unique outbox message_id makes enqueueing the same message twice a no-op.
+func (r *OutboxRepoPG) EnqueueScheduled(ctx context.Context, limit int) (int, error) {
+	q := `with due as (
+           select m.id from messages as m
+           where m.status = 'pending' and m.deleted_at is null and m.send_at <= now()
+             and not exists(select 1 from outbox as o where o.message_id = m.id)
+           order by m.send_at
+           limit $1
+           for update of m skip locked
+       )
+       insert into outbox(message_id)
+       select due.id from due
+       on conflict (message_id) do nothing`
+
+	tag, err := r.db.Exec(ctx, q, limit)
+	if err != nil {
+		return 0, &message.OutboxError{Err: err}
+	}
+
+	return int(tag.RowsAffected()), nil
+}
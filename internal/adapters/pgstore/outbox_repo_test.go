@@ -0,0 +1,193 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/webhook"
+	"time"
+)
+
+func (su *PGStoreTestSuite) OutboxRepo() *OutboxRepoPG {
+	return su.store.Outbox()
+}
+
+func (su *PGStoreTestSuite) outboxEntries() int {
+	var entries int
+	err := su.store.db.QueryRow(context.Background(), "select count(*) from outbox").Scan(&entries)
+	su.Require().NoError(err)
+	return entries
+}
+
+func (su *PGStoreTestSuite) TestOutboxRepo() {
+	su.Run("get repo", func() {
+		repo := su.store.Outbox()
+		su.Require().NotNil(repo)
+	})
+
+	createMessages := func(count int) []*message.Message {
+		messages := make([]*message.Message, 0, count)
+		for range count {
+			msg := &message.Message{Content: "outbox content"}
+			err := su.MsgRepo().Create(context.Background(), msg)
+			su.Require().NoError(err)
+
+			messages = append(messages, msg)
+		}
+		return messages
+	}
+
+	su.Run("empty outbox", func() {
+		su.Equal(0, su.outboxEntries())
+
+		n, err := su.OutboxRepo().RelayPending(context.Background(), 10, func(_ []*message.Message) error {
+			su.Fail("relay must not be called for empty outbox")
+			return nil
+		})
+		su.Require().NoError(err)
+		su.Equal(0, n)
+	})
+
+	su.Run("create adds pending entry", func() {
+		createMessages(3)
+
+		su.Equal(3, su.outboxEntries())
+	})
+
+	su.Run("relay in batches", func() {
+		messages := createMessages(5)
+
+		var relayed []*message.Message
+		relay := func(msgs []*message.Message) error {
+			relayed = append(relayed, msgs...)
+			return nil
+		}
+
+		n, err := su.OutboxRepo().RelayPending(context.Background(), 2, relay)
+		su.Require().NoError(err)
+		su.Equal(2, n)
+
+		su.Equal(3, su.outboxEntries())
+
+		n, err = su.OutboxRepo().RelayPending(context.Background(), 10, relay)
+		su.Require().NoError(err)
+		su.Equal(3, n)
+
+		su.Equal(0, su.outboxEntries()) // sent entries are deleted
+
+		su.Equal(messages, relayed)
+	})
+
+	su.Run("relay error keeps entries pending", func() {
+		createMessages(2)
+
+		relayErr := errors.New("broker is unavailable")
+		n, err := su.OutboxRepo().RelayPending(context.Background(), 10, func(_ []*message.Message) error {
+			return relayErr
+		})
+		su.ErrorIs(err, relayErr)
+		su.Equal(0, n)
+
+		var wantErr *message.OutboxError
+		su.ErrorAs(err, &wantErr)
+
+		su.Equal(2, su.outboxEntries())
+	})
+
+	su.Run("relay drops cancelled messages", func() {
+		messages := createMessages(2)
+
+		messages[0].Status = message.StatusCancelled
+		err := su.MsgRepo().UpdateStatus(context.Background(), messages[0], message.StatusPending)
+		su.Require().NoError(err)
+
+		var relayed []*message.Message
+		n, err := su.OutboxRepo().RelayPending(context.Background(), 10, func(msgs []*message.Message) error {
+			relayed = append(relayed, msgs...)
+			return nil
+		})
+		su.Require().NoError(err)
+		su.Equal(2, n)
+		su.Equal(messages[1:], relayed)
+
+		su.Equal(0, su.outboxEntries())
+	})
+
+	su.Run("relay drops expired messages", func() {
+		past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+		messages := []*message.Message{
+			{Content: "expired", ExpiresAt: &future, CallbackURL: "https://example.com/hook"},
+			{Content: "alive", ExpiresAt: &future},
+		}
+		err := su.MsgRepo().CreateBatch(context.Background(), messages)
+		su.Require().NoError(err)
+
+		_, err = su.store.db.Exec(context.Background(),
+			"update messages set expires_at = $1 where id = $2", past, messages[0].ID)
+		su.Require().NoError(err)
+
+		var relayed []int
+		n, err := su.OutboxRepo().RelayPending(context.Background(), 10, func(msgs []*message.Message) error {
+			for _, msg := range msgs {
+				relayed = append(relayed, msg.ID)
+			}
+			return nil
+		})
+		su.Require().NoError(err)
+		su.Equal(2, n)
+		su.Equal([]int{messages[1].ID}, relayed)
+
+		expired, err := su.MsgRepo().GetByID(context.Background(), messages[0].ID)
+		su.Require().NoError(err)
+		su.Equal(message.StatusExpired, expired.Status)
+
+		// expired is a result, so the webhook is enqueued like for UpdateStatus
+		deliveries, err := su.WebhookRepo().List(context.Background(), webhook.ListFilter{Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(deliveries, 1)
+		su.Equal(messages[0].ID, deliveries[0].MessageID)
+		su.Equal(message.StatusExpired, deliveries[0].MessageStatus)
+
+		alive, err := su.MsgRepo().GetByID(context.Background(), messages[1].ID)
+		su.Require().NoError(err)
+		su.Equal(message.StatusQueued, alive.Status)
+	})
+
+	su.Run("enqueue scheduled", func() {
+		past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+		messages := []*message.Message{
+			{Content: "due", SendAt: &past},
+			{Content: "later", SendAt: &future},
+		}
+		err := su.MsgRepo().CreateBatch(context.Background(), messages)
+		su.Require().NoError(err)
+
+		scheduled := &message.Message{Content: "later too", SendAt: &future}
+		err = su.MsgRepo().Create(context.Background(), scheduled)
+		su.Require().NoError(err)
+
+		su.Equal(1, su.outboxEntries()) // the due message is enqueued on create
+
+		_, err = su.store.db.Exec(context.Background(),
+			"update messages set send_at = $1 where id = $2", past, scheduled.ID)
+		su.Require().NoError(err)
+
+		n, err := su.OutboxRepo().EnqueueScheduled(context.Background(), 10)
+		su.Require().NoError(err)
+		su.Equal(1, n)
+
+		n, err = su.OutboxRepo().EnqueueScheduled(context.Background(), 10)
+		su.Require().NoError(err)
+		su.Equal(0, n)
+
+		var relayed []int
+		_, err = su.OutboxRepo().RelayPending(context.Background(), 10, func(msgs []*message.Message) error {
+			for _, msg := range msgs {
+				relayed = append(relayed, msg.ID)
+			}
+			return nil
+		})
+		su.Require().NoError(err)
+		su.Equal([]int{messages[0].ID, scheduled.ID}, relayed)
+	})
+}
@@ -18,6 +18,13 @@ type Store struct {
 	log *slog.Logger
 
 	messageRepo *MessageRepoPG
+	outboxRepo  *OutboxRepoPG
+
+	idempotencyRepo *IdempotencyRepoPG
+	apiKeyRepo      *APIKeyRepoPG
+	rateLimitRepo   *RateLimitRepoPG
+	eventBus        *EventBusPG
+	webhookRepo     *WebhookRepoPG
 }
 
 // New create new Store and connects to a database. Need call Close after this before goroutine shutdown.
@@ -103,3 +110,51 @@ func (s *Store) Message() *MessageRepoPG {
 
 	return s.messageRepo
 }
+
+func (s *Store) Outbox() *OutboxRepoPG {
+	if s.outboxRepo == nil {
+		s.outboxRepo = NewOutboxRepoPG(s.db)
+	}
+
+	return s.outboxRepo
+}
+
+func (s *Store) Idempotency() *IdempotencyRepoPG {
+	if s.idempotencyRepo == nil {
+		s.idempotencyRepo = NewIdempotencyRepoPG(s.db)
+	}
+
+	return s.idempotencyRepo
+}
+
+func (s *Store) APIKey() *APIKeyRepoPG {
+	if s.apiKeyRepo == nil {
+		s.apiKeyRepo = NewAPIKeyRepoPG(s.db)
+	}
+
+	return s.apiKeyRepo
+}
+
+func (s *Store) RateLimit() *RateLimitRepoPG {
+	if s.rateLimitRepo == nil {
+		s.rateLimitRepo = NewRateLimitRepoPG(s.db)
+	}
+
+	return s.rateLimitRepo
+}
+
+func (s *Store) Events() *EventBusPG {
+	if s.eventBus == nil {
+		s.eventBus = NewEventBusPG(s.db, s.log)
+	}
+
+	return s.eventBus
+}
+
+func (s *Store) Webhook() *WebhookRepoPG {
+	if s.webhookRepo == nil {
+		s.webhookRepo = NewWebhookRepoPG(s.db)
+	}
+
+	return s.webhookRepo
+}
@@ -0,0 +1,33 @@
+package pgstore
+
+import (
+	"strconv"
+	"strings"
+)
+
+// whereBuilder builds a where clause with positional arguments.
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+// Arg adds an argument and returns its placeholder.
+func (b *whereBuilder) Arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *whereBuilder) And(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *whereBuilder) Args() []any {
+	return b.args
+}
+
+func (b *whereBuilder) String() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(b.conds, " and ")
+}
@@ -0,0 +1,81 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"messagio_assignment/internal/domain/ratelimit"
+	"time"
+)
+
+// RateLimitRepoPG keeps one row per key, the row is moved to the new window on the first increment in it.
+// So the table size is bounded by the number of clients and doesn't need to be purged.
+type RateLimitRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewRateLimitRepoPG(db *pgxpool.Pool) *RateLimitRepoPG {
+	return &RateLimitRepoPG{db: db}
+}
+
+// Increment is an atomic upsert, so concurrent increments of all replicas are counted.
+// A row of a later window, e.g. written by a replica with the clock ahead, is incremented as is.
+func (r *RateLimitRepoPG) Increment(ctx context.Context, key string, window time.Time,
+	length time.Duration, amount int) (int, int, error) {
+	q := `insert into rate_limit_counters as c(key, window_start, count) values($1, $2, $3)
+       on conflict (key) do update
+           set prev_count = case
+                   when c.window_start >= excluded.window_start then c.prev_count
+                   when c.window_start = excluded.window_start - $4::interval then c.count
+                   else 0 end,
+               count = case
+                   when c.window_start >= excluded.window_start then c.count + excluded.count
+                   else excluded.count end,
+               window_start = greatest(c.window_start, excluded.window_start)
+       returning c.window_start, c.count, c.prev_count`
+
+	var (
+		start      time.Time
+		curr, prev int
+	)
+	err := r.db.QueryRow(ctx, q, key, window, amount, length).Scan(&start, &curr, &prev)
+	if err != nil {
+		return 0, 0, &ratelimit.Error{Key: key, Err: err}
+	}
+
+	curr, prev = windowCounters(start, curr, prev, window, length)
+	return curr, prev, nil
+}
+
+func (r *RateLimitRepoPG) Get(ctx context.Context, key string, window time.Time,
+	length time.Duration) (int, int, error) {
+	q := `select c.window_start, c.count, c.prev_count from rate_limit_counters as c where c.key = $1`
+
+	var (
+		start      time.Time
+		curr, prev int
+	)
+	err := r.db.QueryRow(ctx, q, key).Scan(&start, &curr, &prev)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, &ratelimit.Error{Key: key, Err: err}
+	}
+
+	curr, prev = windowCounters(start, curr, prev, window, length)
+	return curr, prev, nil
+}
+
+// windowCounters returns the counters of the window from the counters of the row that starts at start.
+func windowCounters(start time.Time, curr, prev int, window time.Time, length time.Duration) (int, int) {
+	switch {
+	case !start.Before(window):
+		return curr, prev
+	case start.Equal(window.Add(-length)):
+		return 0, curr
+	default:
+		return 0, 0
+	}
+}
@@ -0,0 +1,101 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+)
+
+func (su *PGStoreTestSuite) RateLimitRepo() *RateLimitRepoPG {
+	return su.store.RateLimit()
+}
+
+func (su *PGStoreTestSuite) TestRateLimitRepo() {
+	su.Run("get repo", func() {
+		repo := su.store.RateLimit()
+		su.Require().NotNil(repo)
+	})
+
+	ctx := context.Background()
+	length := time.Minute
+	window := time.Now().UTC().Truncate(length)
+
+	su.Run("unknown key", func() {
+		curr, prev, err := su.RateLimitRepo().Get(ctx, "unknown", window, length)
+		su.Require().NoError(err)
+		su.Zero(curr)
+		su.Zero(prev)
+	})
+
+	su.Run("increment and roll windows", func() {
+		const key = "create_msg:apikey:1"
+
+		curr, prev, err := su.RateLimitRepo().Increment(ctx, key, window, length, 1)
+		su.Require().NoError(err)
+		su.Equal(1, curr)
+		su.Zero(prev)
+
+		curr, prev, err = su.RateLimitRepo().Increment(ctx, key, window, length, 2)
+		su.Require().NoError(err)
+		su.Equal(3, curr)
+		su.Zero(prev)
+
+		curr, prev, err = su.RateLimitRepo().Get(ctx, key, window, length)
+		su.Require().NoError(err)
+		su.Equal(3, curr)
+		su.Zero(prev)
+
+		next := window.Add(length)
+		curr, prev, err = su.RateLimitRepo().Get(ctx, key, next, length)
+		su.Require().NoError(err)
+		su.Zero(curr)
+		su.Equal(3, prev)
+
+		curr, prev, err = su.RateLimitRepo().Increment(ctx, key, next, length, 1)
+		su.Require().NoError(err)
+		su.Equal(1, curr)
+		su.Equal(3, prev)
+
+		curr, prev, err = su.RateLimitRepo().Get(ctx, key, next, length)
+		su.Require().NoError(err)
+		su.Equal(1, curr)
+		su.Equal(3, prev)
+
+		later := next.Add(5 * length)
+		_, _, err = su.RateLimitRepo().Increment(ctx, key, later, length, 1)
+		su.Require().NoError(err)
+		curr, prev, err = su.RateLimitRepo().Get(ctx, key, later, length)
+		su.Require().NoError(err)
+		su.Equal(1, curr)
+		su.Zero(prev, "the counter of an old window is not the previous one")
+	})
+
+	su.Run("concurrent increments", func() {
+		const (
+			key     = "get_msg:ip:127.0.0.1"
+			workers = 20
+		)
+
+		type result struct {
+			curr int
+			err  error
+		}
+		results := make(chan result, workers)
+		for range workers {
+			go func() {
+				curr, _, err := su.RateLimitRepo().Increment(ctx, key, window, length, 1)
+				results <- result{curr: curr, err: err}
+			}()
+		}
+		seen := make(map[int]bool, workers)
+		for range workers {
+			res := <-results
+			su.Require().NoError(res.err)
+			seen[res.curr] = true
+		}
+		su.Len(seen, workers, "each increment returns its own count")
+
+		curr, _, err := su.RateLimitRepo().Get(ctx, key, window, length)
+		su.Require().NoError(err)
+		su.Equal(workers, curr)
+	})
+}
@@ -0,0 +1,138 @@
+package pgstore
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"messagio_assignment/internal/domain/webhook"
+	"time"
+)
+
+// WebhookRepoPG keeps webhook deliveries. They are inserted by MessageRepoPG.UpdateStatus.
+type WebhookRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepoPG(db *pgxpool.Pool) *WebhookRepoPG {
+	return &WebhookRepoPG{db: db}
+}
+
+const deliveryColumns = "d.id, d.message_id, d.url, d.message_status, d.event_at, d.status, d.attempts, " +
+	"d.next_attempt_at, d.last_attempt_at, coalesce(d.last_response_code, 0), coalesce(d.last_error, ''), " +
+	"d.created_at, d.delivered_at"
+
+func scanDelivery(row pgx.Row) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	err := row.Scan(&d.ID, &d.MessageID, &d.URL, &d.MessageStatus, &d.EventAt, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.LastResponseCode, &d.LastError,
+		&d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func collectDeliveries(rows pgx.Rows) ([]*webhook.Delivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*webhook.Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDue moves the next attempt of the claimed deliveries forward in one statement. Rows locked
+// by another replica are skipped. If the replica stops before SaveAttempt, the delivery is retried after the lease.
+func (r *WebhookRepoPG) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*webhook.Delivery, error) {
+	q := `update webhook_deliveries as d set next_attempt_at = now() + $2::interval
+       where d.id in (
+           select due.id from webhook_deliveries as due
+           where due.status = 'pending' and due.next_attempt_at <= now()
+           order by due.next_attempt_at
+           limit $1
+           for update skip locked
+       )
+       returning ` + deliveryColumns
+
+	rows, err := r.db.Query(ctx, q, limit, lease)
+	if err != nil {
+		return nil, &webhook.Error{Err: err}
+	}
+
+	deliveries, err := collectDeliveries(rows)
+	if err != nil {
+		return nil, &webhook.Error{Err: err}
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepoPG) SaveAttempt(ctx context.Context, d *webhook.Delivery) error {
+	q := `update webhook_deliveries as d
+       set status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5,
+           last_response_code = nullif($6, 0), last_error = nullif($7, ''), delivered_at = $8
+       where d.id = $1`
+
+	tag, err := r.db.Exec(ctx, q, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastAttemptAt,
+		d.LastResponseCode, d.LastError, d.DeliveredAt)
+	if err != nil {
+		return &webhook.Error{ID: d.ID, Err: err}
+	}
+	if tag.RowsAffected() == 0 {
+		return &webhook.Error{ID: d.ID, Err: ErrGetIntoDomain(pgx.ErrNoRows)}
+	}
+
+	return nil
+}
+
+func (r *WebhookRepoPG) List(ctx context.Context, filter webhook.ListFilter) ([]*webhook.Delivery, error) {
+	var where whereBuilder
+
+	if filter.MessageID != 0 {
+		where.And("d.message_id = " + where.Arg(filter.MessageID))
+	}
+	if filter.Status != "" {
+		where.And("d.status = " + where.Arg(filter.Status))
+	}
+	if filter.AfterID != 0 {
+		where.And("d.id > " + where.Arg(filter.AfterID))
+	}
+
+	q := "select " + deliveryColumns + " from webhook_deliveries as d" + where.String() +
+		" order by d.id limit " + where.Arg(filter.Limit)
+
+	rows, err := r.db.Query(ctx, q, where.Args()...)
+	if err != nil {
+		return nil, &webhook.Error{Err: err}
+	}
+
+	deliveries, err := collectDeliveries(rows)
+	if err != nil {
+		return nil, &webhook.Error{Err: err}
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepoPG) Redeliver(ctx context.Context, id int) (*webhook.Delivery, error) {
+	q := `update webhook_deliveries as d
+       set status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = null
+       where d.id = $1
+       returning ` + deliveryColumns
+
+	d, err := scanDelivery(r.db.QueryRow(ctx, q, id))
+	if err != nil {
+		return nil, &webhook.Error{ID: id, Err: ErrGetIntoDomain(err)}
+	}
+
+	return d, nil
+}
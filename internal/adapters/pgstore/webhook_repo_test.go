@@ -0,0 +1,129 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/webhook"
+	"time"
+)
+
+func (su *PGStoreTestSuite) WebhookRepo() *WebhookRepoPG {
+	return su.store.Webhook()
+}
+
+func (su *PGStoreTestSuite) TestWebhookRepo() {
+	su.Run("get repo", func() {
+		repo := su.store.Webhook()
+		su.Require().NotNil(repo)
+	})
+
+	ctx := context.Background()
+	policy := webhook.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	// process creates the message and moves it to the status, so a delivery is enqueued if the URL is set.
+	process := func(callbackURL string, status message.Status) *message.Message {
+		msg := &message.Message{Content: "webhook content", CallbackURL: callbackURL}
+		err := su.MsgRepo().Create(ctx, msg)
+		su.Require().NoError(err)
+
+		msg.Status = status
+		err = su.MsgRepo().UpdateStatus(ctx, msg, message.StatusPending)
+		su.Require().NoError(err)
+		return msg
+	}
+
+	su.Run("enqueued on results only", func() {
+		processed := process("https://example.com/hook", message.StatusProcessed)
+		process("https://example.com/hook", message.StatusProcessing)
+		process("", message.StatusProcessed)
+
+		got, err := su.MsgRepo().GetByID(ctx, processed.ID)
+		su.Require().NoError(err)
+		su.Equal("https://example.com/hook", got.CallbackURL)
+
+		deliveries, err := su.WebhookRepo().List(ctx, webhook.ListFilter{Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(deliveries, 1)
+
+		d := deliveries[0]
+		su.Equal(processed.ID, d.MessageID)
+		su.Equal("https://example.com/hook", d.URL)
+		su.Equal(message.StatusProcessed, d.MessageStatus)
+		su.WithinDuration(*processed.ProcessedAt, d.EventAt, time.Millisecond)
+		su.Equal(webhook.StatusPending, d.Status)
+		su.Zero(d.Attempts)
+	})
+
+	su.Run("claim, retry, fail, redeliver", func() {
+		msg := process("https://example.com/hook", message.StatusFailed)
+
+		claimed, err := su.WebhookRepo().ClaimDue(ctx, 10, time.Minute)
+		su.Require().NoError(err)
+		su.Require().Len(claimed, 1)
+		su.Equal(msg.ID, claimed[0].MessageID)
+		su.Equal(message.StatusFailed, claimed[0].MessageStatus)
+
+		// the lease hides the claimed delivery from other replicas
+		again, err := su.WebhookRepo().ClaimDue(ctx, 10, time.Minute)
+		su.Require().NoError(err)
+		su.Empty(again)
+
+		d := claimed[0]
+		d.Record(time.Now(), 500, errors.New("unexpected status 500"), policy)
+		su.Require().NoError(su.WebhookRepo().SaveAttempt(ctx, d))
+
+		d.Record(time.Now(), 0, errors.New("connection refused"), policy)
+		su.Require().NoError(su.WebhookRepo().SaveAttempt(ctx, d))
+
+		failed, err := su.WebhookRepo().List(ctx, webhook.ListFilter{Status: webhook.StatusFailed, Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(failed, 1)
+		su.Equal(2, failed[0].Attempts)
+		su.Zero(failed[0].LastResponseCode)
+		su.Equal("connection refused", failed[0].LastError)
+
+		redelivered, err := su.WebhookRepo().Redeliver(ctx, d.ID)
+		su.Require().NoError(err)
+		su.Equal(webhook.StatusPending, redelivered.Status)
+		su.Zero(redelivered.Attempts)
+		su.Equal("connection refused", redelivered.LastError) // the log of the last attempt is kept
+
+		claimed, err = su.WebhookRepo().ClaimDue(ctx, 10, time.Minute)
+		su.Require().NoError(err)
+		su.Require().Len(claimed, 1)
+
+		d = claimed[0]
+		d.Record(time.Now(), 204, nil, policy)
+		su.Require().NoError(su.WebhookRepo().SaveAttempt(ctx, d))
+
+		got, err := su.WebhookRepo().List(ctx, webhook.ListFilter{MessageID: msg.ID, Limit: 10})
+		su.Require().NoError(err)
+		su.Require().Len(got, 1)
+		su.Equal(webhook.StatusSucceeded, got[0].Status)
+		su.Equal(204, got[0].LastResponseCode)
+		su.Empty(got[0].LastError)
+		su.NotNil(got[0].DeliveredAt)
+	})
+
+	su.Run("redeliver unknown", func() {
+		_, err := su.WebhookRepo().Redeliver(ctx, 42)
+		su.ErrorIs(err, domain.ErrNotFound)
+	})
+
+	su.Run("list pages", func() {
+		for range 3 {
+			process("https://example.com/hook", message.StatusProcessed)
+		}
+
+		first, err := su.WebhookRepo().List(ctx, webhook.ListFilter{Limit: 2})
+		su.Require().NoError(err)
+		su.Require().Len(first, 2)
+
+		rest, err := su.WebhookRepo().List(ctx, webhook.ListFilter{AfterID: first[1].ID, Limit: 2})
+		su.Require().NoError(err)
+		su.Require().Len(rest, 1)
+		su.Greater(rest[0].ID, first[1].ID)
+	})
+}
@@ -0,0 +1,160 @@
+package webhookhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/webhook"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+const (
+	// IDHeader has the delivery id, it is the same for retries, so receivers can skip duplicates.
+	IDHeader        = "X-Webhook-ID"
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader is sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">, see Sign.
+	SignatureHeader = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+	// maxErrorBody limits the response body kept in the error of a failed attempt.
+	maxErrorBody = 256
+)
+
+// Payload is the body of the webhook.
+type Payload struct {
+	DeliveryID int       `json:"delivery_id"`
+	MessageID  int       `json:"message_id"`
+	Status     string    `json:"status"`
+	At         time.Time `json:"at"`
+}
+
+func NewPayload(d *webhook.Delivery) Payload {
+	return Payload{
+		DeliveryID: d.ID,
+		MessageID:  d.MessageID,
+		Status:     d.MessageStatus.String(),
+		At:         d.EventAt,
+	}
+}
+
+// Sign returns the signature header value of the body sent at the unix timestamp.
+// The timestamp is signed, so receivers can reject replayed requests.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender posts signed webhooks. Redirects are not followed and 2xx responses are successful.
+// Webhooks are sent only to public addresses, see message.IsPublicAddr.
+type Sender struct {
+	secret []byte
+	client *http.Client
+}
+
+// NewSender creates the sender. The allowed hosts, e.g. receivers in the internal network,
+// may resolve to any address.
+func NewSender(secret string, timeout time.Duration, allowedHosts []string) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would dial the receiver instead of the checked dialer
+	transport.Proxy = nil
+	transport.DialContext = newDialContext(allowedHosts)
+
+	return &Sender{
+		secret: []byte(secret),
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// newDialContext returns a dialer that refuses non-public addresses of hosts that are not allowed.
+// The address is checked after it is resolved, right before connecting, so DNS rebinding can't get around it.
+func newDialContext(allowedHosts []string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	allowed := make(map[string]struct{}, len(allowedHosts))
+	for _, host := range allowedHosts {
+		allowed[strings.ToLower(host)] = struct{}{}
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	publicDialer := &net.Dialer{
+		Timeout:   dialer.Timeout,
+		KeepAlive: dialer.KeepAlive,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrForbiddenAddress, err)
+			}
+			if !message.IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := allowed[strings.ToLower(host)]; ok {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return publicDialer.DialContext(ctx, network, addr)
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, d *webhook.Delivery) (int, error) {
+	body, err := json.Marshal(NewPayload(d))
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "messagio-webhooks/1")
+	req.Header.Set(IDHeader, strconv.Itoa(d.ID))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	// the connection is reused only if the body is read
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	return resp.StatusCode, nil
+}
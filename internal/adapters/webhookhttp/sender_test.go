@@ -0,0 +1,125 @@
+package webhookhttp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/webhook"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSender_Send(t *testing.T) {
+	const secret = "webhook-secret"
+	at := time.Date(2024, 8, 17, 12, 0, 0, 0, time.UTC)
+
+	var (
+		status   = http.StatusNoContent
+		received Payload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/hook", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "7", r.Header.Get(IDHeader))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+		assert.Equal(t, Sign([]byte(secret), timestamp, body), r.Header.Get(SignatureHeader))
+
+		require.NoError(t, json.Unmarshal(body, &received))
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("  busy\n"))
+	}))
+	defer server.Close()
+
+	sender := NewSender(secret, time.Second, []string{"127.0.0.1"})
+	d := &webhook.Delivery{
+		ID: 7, MessageID: 42, URL: server.URL + "/hook", MessageStatus: message.StatusProcessed, EventAt: at,
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		code, err := sender.Send(context.Background(), d)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, Payload{DeliveryID: 7, MessageID: 42, Status: "processed", At: at}, received)
+	})
+
+	t.Run("error_status", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		code, err := sender.Send(context.Background(), d)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.EqualError(t, err, "unexpected status 503: busy")
+	})
+
+	t.Run("redirect_is_not_followed", func(t *testing.T) {
+		status = http.StatusFound
+		code, err := sender.Send(context.Background(), d)
+		assert.Equal(t, http.StatusFound, code)
+		assert.Error(t, err)
+	})
+
+	t.Run("no_response", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		code, err := sender.Send(context.Background(), &webhook.Delivery{ID: 7, URL: closed.URL})
+		assert.Zero(t, code)
+		assert.Error(t, err)
+	})
+}
+
+func TestSender_SendRefusesNonPublicAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+
+	tcases := []struct {
+		Name string
+		URL  string
+	}{
+		{Name: "loopback", URL: server.URL + "/hook"},
+		{Name: "resolved to loopback", URL: "http://localhost:" + port + "/hook"},
+		{Name: "ipv6 loopback", URL: "http://[::1]:" + port + "/hook"},
+		{Name: "private", URL: "http://10.0.0.1:" + port + "/hook"},
+		{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data"},
+	}
+
+	sender := NewSender("secret", time.Second, []string{"example.com"})
+	for _, tc := range tcases {
+		code, err := sender.Send(context.Background(), &webhook.Delivery{ID: 7, URL: tc.URL})
+		assert.Zero(t, code, tc.Name)
+		assert.ErrorIs(t, err, ErrForbiddenAddress, tc.Name)
+	}
+	assert.Zero(t, hits.Load())
+
+	t.Run("allowed_host", func(t *testing.T) {
+		sender := NewSender("secret", time.Second, []string{"LOCALHOST"})
+		code, err := sender.Send(context.Background(), &webhook.Delivery{ID: 7, URL: "http://localhost:" + port + "/hook"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.EqualValues(t, 1, hits.Load())
+	})
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1723896000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=9937c486fd47cb3b3d5f90048b8f2dc068d2fb22f73f39252d52c035587926f2",
+		Sign([]byte("secret"), 1723896000, []byte("{}")))
+}
@@ -18,8 +18,17 @@ type Config struct {
 	LogLevel        slog.Level    `yaml:"log_level" env-default:"DEBUG"`
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
 	HTTPServer      HTTPServer    `yaml:"http_server" env-prefix:"HTTP_SERVER_"`
+	GRPCServer      GRPCServer    `yaml:"grpc_server" env-prefix:"GRPC_SERVER_"`
 	Postgres        Postgres      `yaml:"postgres" env-prefix:"POSTGRES_"`
 	Kafka           Kafka         `yaml:"kafka" env-prefix:"KAFKA_"`
+	Outbox          Outbox        `yaml:"outbox" env-prefix:"OUTBOX_"`
+	Scheduler       Scheduler     `yaml:"scheduler" env-prefix:"SCHEDULER_"`
+	Idempotency     Idempotency   `yaml:"idempotency" env-prefix:"IDEMPOTENCY_"`
+	Retention       Retention     `yaml:"retention" env-prefix:"RETENTION_"`
+	Auth            Auth          `yaml:"auth" env-prefix:"AUTH_"`
+	Events          Events        `yaml:"events" env-prefix:"EVENTS_"`
+	StatsStream     StatsStream   `yaml:"stats_stream" env-prefix:"STATS_STREAM_"`
+	Webhooks        Webhooks      `yaml:"webhooks" env-prefix:"WEBHOOKS_"`
 }
 
 type HTTPServer struct {
@@ -32,14 +41,44 @@ type HTTPServer struct {
 		Idle       time.Duration `yaml:"idle" env:"IDLE_TIMEOUT"`
 	} `yaml:"timeouts"`
 
+	// The message routes without the /v1 prefix are deprecated aliases of the /v1 routes.
+	UnversionedRoutes struct {
+		DeprecatedAt time.Time `yaml:"deprecated_at" env:"DEPRECATED_AT" env-default:"2024-09-01T00:00:00Z"`
+		SunsetAt     time.Time `yaml:"sunset_at" env:"SUNSET_AT" env-default:"2025-03-01T00:00:00Z"`
+	} `yaml:"unversioned_routes" env-prefix:"UNVERSIONED_ROUTES_"`
+
 	Handlers struct {
 		Message struct {
-			CreateMsgPerMinute int `yaml:"create_msg_per_minute"`
-			GetStatsPerMinute  int `yaml:"get_stats_per_minute"`
+			MaxBatchSize int `yaml:"max_batch_size"`
+			// The maximum size of message creation requests in bytes.
+			MaxBodySize int64     `yaml:"max_body_size"`
+			RateLimit   RateLimit `yaml:"rate_limit"`
+
+			Validation MessageValidation `yaml:"validation"`
 		} `yaml:"message"`
 	} `yaml:"handlers"`
 }
 
+// GRPCServer is the gRPC API. Message validation and rate limits are shared with HTTPServer.Handlers.
+type GRPCServer struct {
+	// The gRPC server is not started if Addr is empty.
+	Addr           string `yaml:"addr" env:"ADDR"`
+	MaxRecvMsgSize int    `yaml:"max_recv_msg_size" env:"MAX_RECV_MSG_SIZE" env-default:"4194304"`
+	// Reflection lets clients like grpcurl discover the services.
+	Reflection bool `yaml:"reflection" env:"REFLECTION"`
+}
+
+// MessageValidation is the rules for created messages, they are the same for REST and gRPC.
+type MessageValidation struct {
+	// The maximum content length in bytes, unlimited if zero.
+	MaxContentLength int  `yaml:"max_content_length"`
+	RequireContent   bool `yaml:"require_content"`
+	RequireUTF8      bool `yaml:"require_utf8"`
+	// Regular expressions, content matching any of them is rejected.
+	DenyPatterns          []string `yaml:"deny_patterns"`
+	DisallowUnknownFields bool     `yaml:"disallow_unknown_fields"`
+}
+
 type Postgres struct {
 	ConnectionURL string `env:"CONNECTION_URL" env-required:"true" env-description:"required"`
 	Migrate       bool   `yaml:"migrate" env:"MIGRATE" env-required:"true" env-description:"required"`
@@ -89,6 +128,27 @@ type KafkaProducer struct {
 	} `yaml:"flush" env-prefix:"FLUSH_"`
 }
 
+type RateLimit struct {
+	// Where the counters are kept: memory (per replica) or postgres (shared by all replicas).
+	Backend string `yaml:"backend" env-default:"memory"`
+	// The header identifying anonymous clients, e.g. set by the load balancer. IP is used if it is empty.
+	KeyHeader string `yaml:"key_header"`
+	// The tier of clients that are not in Clients.
+	DefaultTier string `yaml:"default_tier" env-default:"default"`
+	// Limits of routes by tier and route name: create_msg, create_batch, get_msg, cancel_msg,
+	// delete_msg, list_msg, search, get_stats, events.
+	Tiers map[string]map[string]RateLimitRoute `yaml:"tiers"`
+	// Tiers by client: apikey:<id>, jwt:<sub>, client:<key header value> or ip:<IP>.
+	Clients map[string]string `yaml:"clients"`
+}
+
+type RateLimitRoute struct {
+	// The sustained number of requests per minute, unlimited if zero.
+	PerMinute int `yaml:"per_minute"`
+	// The number of requests a client can make at once, per_minute if zero.
+	Burst int `yaml:"burst"`
+}
+
 type KafkaConsumer struct {
 	Group  string   `yaml:"group" env:"GROUP" env-required:"true" env-description:"required"`
 	Topics []string `yaml:"topics" env:"TOPICS" env-required:"true" env-description:"required"`
@@ -105,6 +165,96 @@ type KafkaConsumer struct {
 	} `yaml:"fetch" env-prefix:"FETCH_"`
 }
 
+type Outbox struct {
+	// How often the relay checks the outbox for messages that are not produced yet.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" env-default:"500ms"`
+	// The maximum number of messages produced to Kafka in one batch.
+	BatchSize int `yaml:"batch_size" env:"BATCH_SIZE" env-default:"100"`
+}
+
+type Scheduler struct {
+	// How often the scheduler checks for messages whose send_at has come.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" env-default:"1s"`
+	// The maximum number of messages written to the outbox in one batch.
+	BatchSize int `yaml:"batch_size" env:"BATCH_SIZE" env-default:"100"`
+}
+
+type Idempotency struct {
+	// How long the response for an Idempotency-Key is stored and replayed.
+	TTL time.Duration `yaml:"ttl" env:"TTL" env-default:"24h"`
+	// How long a request in progress holds its Idempotency-Key, it must be longer than the write timeout.
+	// The key of a request lost with its replica can be used again after it.
+	ReservationTimeout time.Duration `yaml:"reservation_timeout" env:"RESERVATION_TIMEOUT" env-default:"1m"`
+}
+
+type Events struct {
+	// The number of events kept for a slow SSE client, further events are dropped for it.
+	Buffer int `yaml:"buffer" env:"BUFFER" env-default:"16"`
+	// How long to wait before listening to Postgres notifications again after a failure.
+	RetryInterval time.Duration `yaml:"retry_interval" env:"RETRY_INTERVAL" env-default:"1s"`
+}
+
+type StatsStream struct {
+	// The minimum time between two stats deltas sent over /ws/stats.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" env-default:"1s"`
+	// The number of deltas kept for a slow WebSocket client, it is disconnected if the buffer is full.
+	Buffer int `yaml:"buffer" env:"BUFFER" env-default:"16"`
+}
+
+type Webhooks struct {
+	// Secret signs webhooks with HMAC-SHA256. It is read only from the environment.
+	// Webhooks are not sent while it is empty, deliveries wait in the log until it is set.
+	Secret string `env:"SECRET"`
+	// How long a receiver has to respond.
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"10s"`
+	// How often due deliveries are checked.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" env-default:"1s"`
+	// The maximum number of deliveries sent concurrently in one batch.
+	BatchSize int `yaml:"batch_size" env:"BATCH_SIZE" env-default:"50"`
+	// The number of attempts before a delivery fails, it can be redelivered by an admin then.
+	MaxAttempts int `yaml:"max_attempts" env:"MAX_ATTEMPTS" env-default:"10"`
+	// The delay after the first failed attempt, it doubles after each next one up to MaxBackoff.
+	MinBackoff time.Duration `yaml:"min_backoff" env:"MIN_BACKOFF" env-default:"10s"`
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"MAX_BACKOFF" env-default:"1h"`
+	// Webhooks are sent only to public addresses, except for these hosts, e.g. receivers in the internal network.
+	AllowedHosts []string `yaml:"allowed_hosts" env:"ALLOWED_HOSTS"`
+}
+
+type Retention struct {
+	// How often deleted and processed messages older than MaxAge are purged.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" env-default:"1h"`
+	// How long deleted and processed messages are kept before they are purged.
+	MaxAge time.Duration `yaml:"max_age" env:"MAX_AGE" env-default:"720h"`
+	// The maximum number of messages deleted in one statement.
+	BatchSize int `yaml:"batch_size" env:"BATCH_SIZE" env-default:"1000"`
+}
+
+type Auth struct {
+	// Enabled requires API keys for message endpoints and sets up admin endpoints.
+	Enabled bool `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	// BootstrapKey is an admin key accepted without the database, to create the first keys.
+	// It must start with msg_ and is read only from the environment.
+	BootstrapKey string `env:"BOOTSTRAP_KEY"`
+	// JWT accepts bearer JWTs of the identity provider along with API keys.
+	JWT JWT `yaml:"jwt" env-prefix:"JWT_"`
+}
+
+type JWT struct {
+	// JWKSPath is the local JWKS file of the identity provider. JWTs are not accepted if it is empty.
+	JWKSPath string `yaml:"jwks_path" env:"JWKS_PATH"`
+	// How often the JWKS file is checked for changes.
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"30s"`
+	// Issuer and Audience are checked if they are not empty.
+	Issuer   string `yaml:"issuer" env:"ISSUER"`
+	Audience string `yaml:"audience" env:"AUDIENCE"`
+	// The claim with scopes, a space-separated string or an array of strings.
+	ScopeClaim string `yaml:"scope_claim" env:"SCOPE_CLAIM" env-default:"scope"`
+	// Maps claim values to scopes. If it is empty, the values must be the scopes themselves.
+	ScopeMapping map[string][]string `yaml:"scope_mapping"`
+	// Allowed clock skew with the identity provider.
+	Leeway time.Duration `yaml:"leeway" env:"LEEWAY" env-default:"30s"`
+}
+
 func ReadConfig(path string) (Config, error) {
 	var cfg Config
 	err := cleanenv.ReadConfig(path, &cfg)
@@ -0,0 +1,80 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"messagio_assignment/internal/domain/auth"
+	"strings"
+	"time"
+)
+
+const (
+	// SecretPrefix tells API keys from other bearer tokens.
+	SecretPrefix = "msg_"
+	secretBytes  = 32
+	// displayPrefixLen is the length of the secret prefix kept to recognize the key in the list.
+	displayPrefixLen = len(SecretPrefix) + 6
+)
+
+// Key is an API key. Only the hash of its secret is stored, the secret is shown once on creation.
+type Key struct {
+	ID     int
+	Name   string
+	Prefix string
+	Scopes []auth.Scope
+	// CallbackURL is the default webhook URL of messages created with the key, empty if it is not set.
+	CallbackURL string
+
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// NewSecret generates the secret of a new key and returns it with its display prefix.
+func NewSecret() (secret, prefix string, err error) {
+	buf := make([]byte, secretBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	secret = SecretPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return secret, secret[:displayPrefixLen], nil
+}
+
+// IsSecret reports whether the token looks like an API key secret.
+func IsSecret(token string) bool {
+	return strings.HasPrefix(token, SecretPrefix)
+}
+
+// Hash returns the stored form of the secret. Secrets are random, so a fast hash is enough.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type Repository interface {
+	Create(ctx context.Context, key *Key, hash string) error
+	// GetActiveByHash returns the key that is not revoked.
+	GetActiveByHash(ctx context.Context, hash string) (*Key, error)
+	List(ctx context.Context) ([]*Key, error)
+	Revoke(ctx context.Context, id int) error
+}
+
+type Error struct {
+	ID  int
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.ID == 0 {
+		return fmt.Sprintf("api key: %v", e.Err)
+	}
+	return fmt.Sprintf("api key with %d id: %v", e.ID, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"slices"
+)
+
+// ErrUnknownToken is returned by an authenticator for tokens of other kinds, so the next one can try.
+var ErrUnknownToken = fmt.Errorf("%w: unknown token kind", domain.ErrUnauthenticated)
+
+// Scope allows a group of endpoints.
+type Scope string
+
+const (
+	ScopeMessagesRead  Scope = "messages:read"
+	ScopeMessagesWrite Scope = "messages:write"
+	// ScopeAdmin allows managing API keys and implies all other scopes.
+	ScopeAdmin Scope = "admin"
+)
+
+var Scopes = []Scope{ScopeMessagesRead, ScopeMessagesWrite, ScopeAdmin}
+
+func (s Scope) Valid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+func (s Scope) String() string {
+	return string(s)
+}
+
+// Principal is the authenticated client of the request.
+type Principal struct {
+	// Subject identifies the client in logs, e.g. apikey:42.
+	Subject string
+	Scopes  []Scope
+	// CallbackURL is the default webhook URL of messages created by the client, empty if it is not set.
+	CallbackURL string
+}
+
+// HasScope reports whether the principal is allowed the scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal of the authenticated request.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
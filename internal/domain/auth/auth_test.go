@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPrincipal_HasScope(t *testing.T) {
+	reader := &Principal{Scopes: []Scope{ScopeMessagesRead}}
+	admin := &Principal{Scopes: []Scope{ScopeAdmin}}
+
+	assert.True(t, reader.HasScope(ScopeMessagesRead))
+	assert.False(t, reader.HasScope(ScopeMessagesWrite))
+	assert.False(t, reader.HasScope(ScopeAdmin))
+	assert.True(t, admin.HasScope(ScopeMessagesWrite))
+	assert.True(t, admin.HasScope(ScopeAdmin))
+}
+
+func TestPrincipalFrom(t *testing.T) {
+	_, ok := PrincipalFrom(context.Background())
+	assert.False(t, ok)
+
+	p := &Principal{Subject: "apikey:1"}
+	got, ok := PrincipalFrom(WithPrincipal(context.Background(), p))
+	require.True(t, ok)
+	assert.Same(t, p, got)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "errors"
+
+// Code is a stable error code for clients to branch on. Unlike error texts, codes don't change.
+type Code string
+
+const (
+	CodeAlreadyExists        Code = "already_exists"
+	CodeNotCreated           Code = "not_created"
+	CodeNotFound             Code = "not_found"
+	CodeConflict             Code = "conflict"
+	CodeInvalidArgument      Code = "invalid_argument"
+	CodeUnauthenticated      Code = "unauthenticated"
+	CodeForbidden            Code = "forbidden"
+	CodeIdempotencyKeyReused Code = "idempotency_key_reused"
+	CodeRateLimited          Code = "rate_limited"
+
+	// request errors detected before the domain
+	CodeBadRequest      Code = "bad_request"
+	CodeValidation      Code = "validation_failed"
+	CodePayloadTooLarge Code = "payload_too_large"
+
+	CodeInternal Code = "internal"
+)
+
+// errorCodes is the registry of domain errors with codes, more specific errors go first.
+var errorCodes = []struct {
+	err  error
+	code Code
+}{
+	{ErrIdempotencyKeyReused, CodeIdempotencyKeyReused},
+	{ErrRateLimited, CodeRateLimited},
+	{ErrAlreadyExists, CodeAlreadyExists},
+	{ErrNotCreated, CodeNotCreated},
+	{ErrNotFound, CodeNotFound},
+	{ErrConflict, CodeConflict},
+	{ErrInvalidArgument, CodeInvalidArgument},
+	{ErrUnauthenticated, CodeUnauthenticated},
+	{ErrForbidden, CodeForbidden},
+}
+
+// ErrorCode returns the code of the registered domain error in the chain.
+// It returns false if there is none, e.g. for infrastructure errors.
+func ErrorCode(err error) (Code, bool) {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code, true
+		}
+	}
+	return "", false
+}
+
+// codeDetails are sent to clients instead of error texts, which can have internal details
+// like wrapped causes. Internal errors have no detail.
+var codeDetails = map[Code]string{
+	CodeAlreadyExists:        "The resource already exists.",
+	CodeNotCreated:           "The resource is not created.",
+	CodeNotFound:             "The resource is not found.",
+	CodeConflict:             "The resource state does not allow the operation.",
+	CodeInvalidArgument:      "The request has an invalid argument.",
+	CodeUnauthenticated:      "The request is not authenticated.",
+	CodeForbidden:            "The client is not allowed to make the request.",
+	CodeIdempotencyKeyReused: "The idempotency key is already used with a different request.",
+	CodeRateLimited:          "Too many requests, retry later.",
+	CodeBadRequest:           "The request is malformed.",
+	CodeValidation:           "The request has invalid fields.",
+	CodePayloadTooLarge:      "The request is too large.",
+}
+
+// Detail returns the public description of the code, it is the same for all errors with the code.
+func (c Code) Detail() string {
+	return codeDetails[c]
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	tcases := []struct {
+		Name  string
+		Err   error
+		Code  Code
+		Found bool
+	}{
+		{Name: "sentinel", Err: ErrNotFound, Code: CodeNotFound, Found: true},
+		{Name: "wrapped", Err: fmt.Errorf("message: %w", ErrAlreadyExists), Code: CodeAlreadyExists, Found: true},
+		{Name: "with detail", Err: fmt.Errorf("%w: bad label", ErrInvalidArgument), Code: CodeInvalidArgument, Found: true},
+		{Name: "more specific first", Err: fmt.Errorf("%w: %w", ErrIdempotencyKeyReused, ErrConflict),
+			Code: CodeIdempotencyKeyReused, Found: true},
+		{Name: "unknown", Err: errors.New("db error"), Found: false},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			code, found := ErrorCode(tc.Err)
+			assert.Equal(t, tc.Found, found)
+			assert.Equal(t, tc.Code, code)
+		})
+	}
+}
+
+func TestCode_Detail(t *testing.T) {
+	for _, ec := range errorCodes {
+		assert.NotEmpty(t, ec.code.Detail(), ec.code)
+	}
+	for _, code := range []Code{CodeBadRequest, CodeValidation, CodePayloadTooLarge} {
+		assert.NotEmpty(t, code.Detail(), code)
+	}
+	assert.Empty(t, CodeInternal.Detail())
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a paginated list. It is passed to clients as an opaque string.
+// Lists use AfterID as the keyset, search results use Offset.
+type Cursor struct {
+	AfterID int `json:"after_id"`
+	Offset  int `json:"offset,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c) // struct with int fields can't fail to marshal
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil || c.AfterID < 0 || c.Offset < 0 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
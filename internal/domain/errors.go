@@ -9,4 +9,16 @@ var (
 
 	// get errors
 	ErrNotFound = errors.New("not found")
+
+	// update errors
+	ErrConflict = errors.New("conflict")
+
+	// request errors
+	ErrInvalidArgument      = errors.New("invalid argument")
+	ErrIdempotencyKeyReused = errors.New("idempotency key is reused with a different request")
+	ErrRateLimited          = errors.New("too many requests")
+
+	// auth errors
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
 )
@@ -0,0 +1,63 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"time"
+)
+
+// ErrFingerprintMismatch is domain.ErrIdempotencyKeyReused, so it has the registered code.
+var ErrFingerprintMismatch = domain.ErrIdempotencyKeyReused
+
+// ErrReservationLost means the reservation is completed, released or reserved again by another request
+// after it was considered lost.
+var ErrReservationLost = errors.New("idempotency key reservation is lost")
+
+// Record is a request made with an idempotency key. Keys are scoped by the principal,
+// so different clients can use the same key.
+type Record struct {
+	// Principal is the subject of the authenticated client, empty for anonymous requests.
+	Principal   string
+	Key         string
+	Fingerprint string
+	// Token identifies the reservation, it changes when the key is reserved again.
+	Token string
+	// Response is nil while the original request is in progress.
+	Response  *Response
+	ExpiresAt time.Time
+}
+
+// Response is the stored response of the original request.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+type Repository interface {
+	// Reserve creates the record if the key is new or expired and returns true. A record without
+	// a response created before staleBefore is reserved again too, its request is considered lost.
+	// Otherwise, it returns the stored record and false.
+	Reserve(ctx context.Context, principal, key, fingerprint string,
+		expiresAt, staleBefore time.Time) (*Record, bool, error)
+	// Complete stores the response of the reservation with the token.
+	// It returns ErrReservationLost if the key doesn't have the reservation anymore.
+	Complete(ctx context.Context, principal, key, token string, resp Response) error
+	// Release removes the reservation with the token without a response, so the request can be retried.
+	// It returns ErrReservationLost if the key doesn't have the reservation anymore.
+	Release(ctx context.Context, principal, key, token string) error
+}
+
+type Error struct {
+	Key string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("idempotency key %q: %v", e.Key, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const MaxCallbackURLLen = 2048
+
+var ErrInvalidCallbackURL = errors.New("invalid callback url")
+
+// nonPublicPrefixes are the special-purpose ranges that are not covered by the netip.Addr methods.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, also some cloud metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64, it can reach any IPv4 address
+	netip.MustParsePrefix("2002::/16"),    // 6to4, the same
+}
+
+// IsPublicAddr reports whether webhooks may be sent to the address.
+// Loopback, private, link-local (including the 169.254.169.254 cloud metadata) and other
+// special-purpose addresses are not public.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateCallbackURL checks that the webhook URL is an absolute http or https URL.
+// An empty URL is valid and means no webhook. Literal non-public IP addresses and localhost are rejected,
+// other hosts are checked when webhooks are sent, since they may resolve to anything.
+func ValidateCallbackURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if len(raw) > MaxCallbackURLLen {
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidCallbackURL, MaxCallbackURLLen)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCallbackURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidCallbackURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: host is empty", ErrInvalidCallbackURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidCallbackURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: host is not public", ErrInvalidCallbackURL)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: host is not public", ErrInvalidCallbackURL)
+	}
+
+	return nil
+}
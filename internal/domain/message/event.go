@@ -0,0 +1,40 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Event is a creation of the message or a change of its status made by the processing.
+type Event struct {
+	MessageID int
+	Status    Status
+	At        time.Time
+}
+
+// EventPublisher delivers events to the subscribers of all replicas. Publishing is best-effort:
+// the publisher handles its errors itself, so the status change is not rolled back because of them.
+type EventPublisher interface {
+	Publish(ctx context.Context, evs ...Event)
+}
+
+//go:generate mockery --name EventSource
+
+// EventSource receives the events published by all replicas.
+type EventSource interface {
+	// Listen calls handle for each event until ctx is done or the source fails.
+	Listen(ctx context.Context, handle func(Event)) error
+}
+
+type EventError struct {
+	Err error
+}
+
+func (e *EventError) Error() string {
+	return fmt.Sprintf("message event: %v", e.Err)
+}
+
+func (e *EventError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,46 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	MaxLabels         = 16
+	MaxLabelKeyLen    = 63
+	MaxLabelValueLen  = 255
+	labelKeyPatternRe = `^[A-Za-z0-9][A-Za-z0-9_./-]*$`
+)
+
+var ErrInvalidLabels = errors.New("invalid labels")
+
+var labelKeyRe = regexp.MustCompile(labelKeyPatternRe)
+
+// Labels tag a message, e.g. by the source system or the customer.
+// Keys are letters, digits and _./- characters, so they can be used in query parameters as key:value.
+type Labels map[string]string
+
+// Validate checks the number of labels and the lengths of keys and values.
+func (l Labels) Validate() error {
+	if len(l) > MaxLabels {
+		return fmt.Errorf("%w: more than %d labels", ErrInvalidLabels, MaxLabels)
+	}
+
+	for key, value := range l {
+		if !ValidLabelKey(key) {
+			return fmt.Errorf("%w: key %q must match %s and be at most %d bytes",
+				ErrInvalidLabels, key, labelKeyPatternRe, MaxLabelKeyLen)
+		}
+		if len(value) > MaxLabelValueLen {
+			return fmt.Errorf("%w: value of %q is longer than %d bytes", ErrInvalidLabels, key, MaxLabelValueLen)
+		}
+	}
+
+	return nil
+}
+
+// ValidLabelKey reports whether the key can be used as a label key.
+func ValidLabelKey(key string) bool {
+	return len(key) <= MaxLabelKeyLen && labelKeyRe.MatchString(key)
+}
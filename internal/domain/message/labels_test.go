@@ -0,0 +1,38 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLabels_Validate(t *testing.T) {
+	tooMany := Labels{}
+	for i := range MaxLabels + 1 {
+		tooMany[string(rune('a'+i))] = "v"
+	}
+
+	tcases := []struct {
+		Name   string
+		Labels Labels
+		Valid  bool
+	}{
+		{Name: "nil", Labels: nil, Valid: true},
+		{Name: "simple", Labels: Labels{"source": "crm", "customer.id": "42", "team/x_y-z": ""}, Valid: true},
+		{Name: "empty key", Labels: Labels{"": "v"}, Valid: false},
+		{Name: "key with colon", Labels: Labels{"a:b": "v"}, Valid: false},
+		{Name: "key starts with dot", Labels: Labels{".a": "v"}, Valid: false},
+		{Name: "long key", Labels: Labels{strings.Repeat("k", MaxLabelKeyLen+1): "v"}, Valid: false},
+		{Name: "long value", Labels: Labels{"k": strings.Repeat("v", MaxLabelValueLen+1)}, Valid: false},
+		{Name: "too many", Labels: tooMany, Valid: false},
+	}
+
+	for _, tc := range tcases {
+		err := tc.Labels.Validate()
+		if tc.Valid {
+			assert.NoError(t, err, tc.Name)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidLabels, tc.Name)
+		}
+	}
+}
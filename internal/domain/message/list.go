@@ -0,0 +1,33 @@
+package message
+
+import "time"
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// ListFilter selects messages ordered by id. Nil fields are not filtered.
+type ListFilter struct {
+	Status *Status
+	// Labels selects messages that have all of these labels.
+	Labels Labels
+
+	// MinID and MaxID are inclusive.
+	MinID *int
+	MaxID *int
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	// AfterID is the keyset cursor: only messages with a greater id are listed.
+	AfterID int
+	Limit   int
+}
+
+// Page is a part of the messages list. NextAfterID is zero if there are no more messages.
+type Page struct {
+	Messages    []*Message
+	NextAfterID int
+}
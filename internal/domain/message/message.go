@@ -3,23 +3,59 @@ package message
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 type Message struct {
-	ID        int
-	Content   string
-	Processed bool
+	ID      int
+	Content string
+	Status  Status
+	Labels  Labels
+
+	// SendAt delays producing the message until the time. Nil means as soon as possible.
+	SendAt *time.Time
+	// ExpiresAt is the time after which the message is useless. It is not produced after this time
+	// and is marked as expired instead of processed. Nil means the message never expires.
+	ExpiresAt *time.Time
+	// CallbackURL receives a webhook when the message gets a processing result. Empty means no webhook.
+	CallbackURL string
+
+	CreatedAt time.Time
+	// ProcessedAt is nil while the message is not processed.
+	ProcessedAt *time.Time
+}
+
+// Expired reports whether the message is expired at the time.
+func (m *Message) Expired(at time.Time) bool {
+	return m.ExpiresAt != nil && !at.Before(*m.ExpiresAt)
 }
 
+//go:generate mockery --name Repository
 type Repository interface {
 	Create(ctx context.Context, msg *Message) error
+	// CreateBatch creates all messages or none of them.
+	CreateBatch(ctx context.Context, msgs []*Message) error
 	GetByID(ctx context.Context, id int) (*Message, error)
-	GetStats(ctx context.Context) (*Stats, error)
-	UpdateProcessed(ctx context.Context, msg *Message) error
+	List(ctx context.Context, filter ListFilter) ([]*Message, error)
+	// Search returns messages matching the query ordered by rank, the best first.
+	Search(ctx context.Context, query SearchQuery) ([]*SearchHit, error)
+	GetStats(ctx context.Context, filter StatsFilter) (*Stats, error)
+	// UpdateStatus changes the status to msg.Status only if the stored status is from.
+	// If the new status is a processing result and the message has a CallbackURL,
+	// a webhook delivery is enqueued in the same transaction.
+	// It returns domain.ErrConflict if the status was changed concurrently.
+	UpdateStatus(ctx context.Context, msg *Message, from Status) error
+	// Delete soft deletes the message, so it is hidden from reads and stats.
+	// It returns domain.ErrNotFound if the message doesn't exist or is deleted already.
+	Delete(ctx context.Context, id int) error
+	// Purge hard deletes up to limit messages that were deleted or processed before the time.
+	// It returns the number of purged messages.
+	Purge(ctx context.Context, before time.Time, limit int) (int, error)
 }
 
+//go:generate mockery --name Producer
 type Producer interface {
-	Produce(msg *Message)
+	Produce(msgs ...*Message) error
 }
 
 type Error struct {
@@ -40,7 +76,7 @@ type ErrorWithID struct {
 }
 
 func (e *ErrorWithID) Error() string {
-	return fmt.Sprintf("message with %q id: %v", e.ID, e.Err)
+	return fmt.Sprintf("message with %d id: %v", e.ID, e.Err)
 }
 
 func (e *ErrorWithID) Unwrap() error {
@@ -0,0 +1,43 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventSource is an autogenerated mock type for the EventSource type
+type EventSource struct {
+	mock.Mock
+}
+
+// Listen provides a mock function with given fields: ctx, handle
+func (_m *EventSource) Listen(ctx context.Context, handle func(message.Event)) error {
+	ret := _m.Called(ctx, handle)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(message.Event)) error); ok {
+		r0 = rf(ctx, handle)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewEventSource creates a new instance of EventSource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventSource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventSource {
+	mock := &EventSource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
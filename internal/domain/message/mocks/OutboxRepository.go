@@ -0,0 +1,77 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// EnqueueScheduled provides a mock function with given fields: ctx, limit
+func (_m *OutboxRepository) EnqueueScheduled(ctx context.Context, limit int) (int, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RelayPending provides a mock function with given fields: ctx, limit, relay
+func (_m *OutboxRepository) RelayPending(ctx context.Context, limit int, relay func([]*message.Message) error) (int, error) {
+	ret := _m.Called(ctx, limit, relay)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, func([]*message.Message) error) (int, error)); ok {
+		return rf(ctx, limit, relay)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, func([]*message.Message) error) int); ok {
+		r0 = rf(ctx, limit, relay)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, func([]*message.Message) error) error); ok {
+		r1 = rf(ctx, limit, relay)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,48 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Producer is an autogenerated mock type for the Producer type
+type Producer struct {
+	mock.Mock
+}
+
+// Produce provides a mock function with given fields: msgs
+func (_m *Producer) Produce(msgs ...*message.Message) error {
+	_va := make([]interface{}, len(msgs))
+	for _i := range msgs {
+		_va[_i] = msgs[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...*message.Message) error); ok {
+		r0 = rf(msgs...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewProducer creates a new instance of Producer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProducer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Producer {
+	mock := &Producer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,215 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, msg
+func (_m *Repository) Create(ctx context.Context, msg *message.Message) error {
+	ret := _m.Called(ctx, msg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *message.Message) error); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateBatch provides a mock function with given fields: ctx, msgs
+func (_m *Repository) CreateBatch(ctx context.Context, msgs []*message.Message) error {
+	ret := _m.Called(ctx, msgs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*message.Message) error); ok {
+		r0 = rf(ctx, msgs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *Repository) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *Repository) GetByID(ctx context.Context, id int) (*message.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *message.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*message.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *message.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, filter
+func (_m *Repository) GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 *message.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, message.StatsFilter) (*message.Stats, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, message.StatsFilter) *message.Stats); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Stats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, message.StatsFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *Repository) List(ctx context.Context, filter message.ListFilter) ([]*message.Message, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*message.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, message.ListFilter) ([]*message.Message, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, message.ListFilter) []*message.Message); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*message.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, message.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before, limit
+func (_m *Repository) Purge(ctx context.Context, before time.Time, limit int) (int, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) (int, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, query
+func (_m *Repository) Search(ctx context.Context, query message.SearchQuery) ([]*message.SearchHit, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*message.SearchHit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, message.SearchQuery) ([]*message.SearchHit, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, message.SearchQuery) []*message.SearchHit); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*message.SearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, message.SearchQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, msg, from
+func (_m *Repository) UpdateStatus(ctx context.Context, msg *message.Message, from message.Status) error {
+	ret := _m.Called(ctx, msg, from)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *message.Message, message.Status) error); ok {
+		r0 = rf(ctx, msg, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
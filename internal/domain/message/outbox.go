@@ -0,0 +1,33 @@
+package message
+
+import (
+	"context"
+	"fmt"
+)
+
+//go:generate mockery --name OutboxRepository
+
+// OutboxRepository gives access to the messages that are written to the outbox
+// in the same transaction as Repository.Create and still have to be produced.
+// Messages with SendAt in the future are written to the outbox by EnqueueScheduled when the time comes.
+type OutboxRepository interface {
+	// RelayPending locks up to limit pending outbox entries, passes their messages to relay
+	// and deletes the entries if relay returns no error. Cancelled messages are not passed to relay.
+	// It returns the number of handled entries.
+	RelayPending(ctx context.Context, limit int, relay func(msgs []*Message) error) (int, error)
+	// EnqueueScheduled writes up to limit pending messages whose SendAt has come to the outbox.
+	// It returns the number of enqueued messages.
+	EnqueueScheduled(ctx context.Context, limit int) (int, error)
+}
+
+type OutboxError struct {
+	Err error
+}
+
+func (e *OutboxError) Error() string {
+	return fmt.Sprintf("message outbox: %v", e.Err)
+}
+
+func (e *OutboxError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,30 @@
+package message
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	// MaxSearchOffset limits the pagination depth, deep pages of ranked results are expensive.
+	MaxSearchOffset = 10000
+)
+
+// SearchQuery is a full-text search over message content.
+// Query is in the web search syntax: "quoted phrase", or, -excluded.
+type SearchQuery struct {
+	Query  string
+	Offset int
+	Limit  int
+}
+
+// SearchHit is a found message. Highlight has the matched fragments of the content
+// with the matched words wrapped in <mark> tags.
+type SearchHit struct {
+	Message   *Message
+	Rank      float64
+	Highlight string
+}
+
+// SearchPage is a part of the hits ordered by rank. NextOffset is zero if there are no more hits.
+type SearchPage struct {
+	Hits       []*SearchHit
+	NextOffset int
+}
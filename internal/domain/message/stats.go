@@ -1,10 +1,133 @@
 package message
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultStatsBuckets is the number of buckets in the series if the window start is not set.
+	DefaultStatsBuckets = 60
+	MaxStatsBuckets     = 1440
+)
 
 type Stats struct {
 	All       int
 	Processed int
+
+	// ByStatus counts messages by their current status. All statuses are present.
+	ByStatus map[Status]int
+
+	// OutboxPending is the number of created messages that are not produced yet.
+	// It is not limited by StatsFilter.
+	OutboxPending int
+
+	// ByLabel is filled only if StatsFilter.GroupByLabel is set. It is ordered by the label value.
+	ByLabel []LabelStats
+
+	// Series is filled only if StatsFilter.Bucket is set.
+	Series []StatsBucket
+}
+
+// StatsDelta is the change of the totals of Stats between two snapshots.
+type StatsDelta struct {
+	All           int
+	Processed     int
+	OutboxPending int
+	// ByStatus has only the statuses whose count has changed.
+	ByStatus map[Status]int
+}
+
+// Delta returns the change of the totals since the previous snapshot.
+func (s *Stats) Delta(prev *Stats) StatsDelta {
+	d := StatsDelta{
+		All:           s.All - prev.All,
+		Processed:     s.Processed - prev.Processed,
+		OutboxPending: s.OutboxPending - prev.OutboxPending,
+	}
+
+	for _, status := range Statuses {
+		if diff := s.ByStatus[status] - prev.ByStatus[status]; diff != 0 {
+			if d.ByStatus == nil {
+				d.ByStatus = make(map[Status]int)
+			}
+			d.ByStatus[status] = diff
+		}
+	}
+
+	return d
+}
+
+func (d StatsDelta) IsZero() bool {
+	return d.All == 0 && d.Processed == 0 && d.OutboxPending == 0 && len(d.ByStatus) == 0
+}
+
+// StatsFilter limits stats to the [From, To) window. Created messages and ByStatus are counted
+// by creation time, processed messages by processing time. Nil bounds are not limited.
+type StatsFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Bucket Bucket
+
+	// Labels limits stats to messages that have all of these labels.
+	Labels Labels
+	// GroupByLabel is the label key to group stats by in Stats.ByLabel.
+	GroupByLabel string
+}
+
+// LabelStats is the stats of messages with the same value of StatsFilter.GroupByLabel.
+// Messages without the label are grouped under the empty value.
+type LabelStats struct {
+	Value     string
+	All       int
+	Processed int
+}
+
+type StatsBucket struct {
+	Start     time.Time
+	Created   int
+	Processed int
+
+	// Latency is nil if no messages were processed in the bucket.
+	Latency *Latency
+}
+
+// Latency is the percentiles of time between message creation and processing.
+type Latency struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+type Bucket string
+
+const (
+	BucketNone   Bucket = ""
+	BucketMinute Bucket = "minute"
+	BucketHour   Bucket = "hour"
+	BucketDay    Bucket = "day"
+)
+
+func (b Bucket) Valid() bool {
+	switch b {
+	case BucketNone, BucketMinute, BucketHour, BucketDay:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b Bucket) Duration() time.Duration {
+	switch b {
+	case BucketMinute:
+		return time.Minute
+	case BucketHour:
+		return time.Hour
+	case BucketDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
 }
 
 type StatsError struct {
@@ -0,0 +1,26 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStats_Delta(t *testing.T) {
+	prev := &Stats{
+		All: 10, Processed: 4, OutboxPending: 2,
+		ByStatus: map[Status]int{StatusPending: 2, StatusQueued: 4, StatusProcessed: 4},
+	}
+	curr := &Stats{
+		All: 12, Processed: 7, OutboxPending: 1,
+		ByStatus: map[Status]int{StatusPending: 3, StatusQueued: 2, StatusProcessed: 7},
+	}
+
+	d := curr.Delta(prev)
+	assert.Equal(t, StatsDelta{
+		All: 2, Processed: 3, OutboxPending: -1,
+		ByStatus: map[Status]int{StatusPending: 1, StatusQueued: -2, StatusProcessed: 3},
+	}, d)
+	assert.False(t, d.IsZero())
+
+	assert.True(t, curr.Delta(curr).IsZero())
+}
@@ -0,0 +1,86 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Status is the lifecycle state of a message.
+type Status string
+
+const (
+	// StatusPending is stored, but not produced to Kafka yet.
+	StatusPending Status = "pending"
+	// StatusQueued is produced to Kafka and waits for the processor.
+	StatusQueued Status = "queued"
+	// StatusProcessing is reported by the processor when it starts processing.
+	StatusProcessing Status = "processing"
+	StatusProcessed  Status = "processed"
+	StatusFailed     Status = "failed"
+	StatusCancelled  Status = "cancelled"
+	// StatusExpired is not produced or processed before message.Message.ExpiresAt.
+	StatusExpired Status = "expired"
+)
+
+// Statuses are all known statuses in the lifecycle order.
+var Statuses = []Status{
+	StatusPending, StatusQueued, StatusProcessing,
+	StatusProcessed, StatusFailed, StatusCancelled, StatusExpired,
+}
+
+// transitions are the allowed status changes. Processor events can arrive before the outbox relay
+// marks the message as queued, so they are allowed from pending too.
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusQueued, StatusProcessing, StatusProcessed, StatusFailed, StatusCancelled, StatusExpired},
+	StatusQueued:     {StatusProcessing, StatusProcessed, StatusFailed, StatusCancelled, StatusExpired},
+	StatusProcessing: {StatusProcessed, StatusFailed, StatusExpired},
+	StatusFailed:     {StatusQueued, StatusProcessing, StatusProcessed, StatusExpired},
+	StatusProcessed:  {},
+	StatusCancelled:  {},
+	StatusExpired:    {},
+}
+
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the status can be changed to another one.
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether the status can't be changed anymore.
+func (s Status) IsFinal() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+// IsResult reports whether the status is a result of processing reported by the processor.
+// Clients are notified about results by webhooks.
+func (s Status) IsResult() bool {
+	return s == StatusProcessed || s == StatusFailed || s == StatusExpired
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%v: from %q to %q", ErrInvalidTransition, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
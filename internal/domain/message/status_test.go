@@ -0,0 +1,50 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tcases := []struct {
+		From    Status
+		To      Status
+		Allowed bool
+	}{
+		{From: StatusPending, To: StatusQueued, Allowed: true},
+		{From: StatusPending, To: StatusProcessed, Allowed: true},
+		{From: StatusPending, To: StatusCancelled, Allowed: true},
+		{From: StatusQueued, To: StatusProcessing, Allowed: true},
+		{From: StatusQueued, To: StatusPending, Allowed: false},
+		{From: StatusProcessing, To: StatusProcessed, Allowed: true},
+		{From: StatusProcessing, To: StatusCancelled, Allowed: false},
+		{From: StatusFailed, To: StatusQueued, Allowed: true},
+		{From: StatusProcessed, To: StatusFailed, Allowed: false},
+		{From: StatusProcessed, To: StatusProcessed, Allowed: false},
+		{From: StatusCancelled, To: StatusQueued, Allowed: false},
+		{From: StatusProcessing, To: StatusExpired, Allowed: true},
+		{From: StatusExpired, To: StatusProcessed, Allowed: false},
+		{From: Status("unknown"), To: StatusQueued, Allowed: false},
+	}
+
+	for _, tc := range tcases {
+		assert.Equal(t, tc.Allowed, tc.From.CanTransitionTo(tc.To), "%s -> %s", tc.From, tc.To)
+	}
+}
+
+func TestStatus_IsFinal(t *testing.T) {
+	for _, s := range Statuses {
+		want := s == StatusProcessed || s == StatusCancelled || s == StatusExpired
+		assert.Equal(t, want, s.IsFinal(), s)
+	}
+
+	assert.False(t, Status("unknown").IsFinal())
+	assert.False(t, Status("unknown").Valid())
+}
+
+func TestStatus_IsResult(t *testing.T) {
+	for _, s := range Statuses {
+		want := s == StatusProcessed || s == StatusFailed || s == StatusExpired
+		assert.Equal(t, want, s.IsResult(), s)
+	}
+}
@@ -0,0 +1,123 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is an invalid field of a message to create.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// FieldErrors lists all invalid fields of the message, so the client can fix them at once.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *FieldErrors) Add(field, msg string) {
+	*e = append(*e, FieldError{Field: field, Message: msg})
+}
+
+// Draft is a message to create as a client sends it, before it is validated.
+type Draft struct {
+	Content   string
+	Labels    Labels
+	SendAt    *time.Time
+	ExpiresAt *time.Time
+	// TTL is counted from the creation, only one of ExpiresAt and TTL can be set.
+	TTL         *time.Duration
+	CallbackURL string
+}
+
+// Message converts the validated draft, TTL is counted from now.
+func (d *Draft) Message() *Message {
+	expiresAt := d.ExpiresAt
+	if d.TTL != nil {
+		at := time.Now().Add(*d.TTL)
+		expiresAt = &at
+	}
+
+	return &Message{
+		Content:     d.Content,
+		Labels:      d.Labels,
+		SendAt:      d.SendAt,
+		ExpiresAt:   expiresAt,
+		CallbackURL: d.CallbackURL,
+	}
+}
+
+// ValidationRules is the configurable rules for messages, they are the same for all APIs.
+// The zero value accepts any content.
+type ValidationRules struct {
+	// MaxContentLength is the maximum content length in bytes, unlimited if zero.
+	MaxContentLength int
+	RequireContent   bool
+	// RequireUTF8 rejects content with invalid UTF-8. The JSON decoder replaces invalid bytes
+	// with U+FFFD, so the replacement character is rejected too.
+	RequireUTF8 bool
+	// DenyPatterns rejects content matching any of the patterns.
+	DenyPatterns []*regexp.Regexp
+}
+
+// CompileDenyPatterns compiles the deny-list from the config.
+func CompileDenyPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("deny pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Validate checks the content against the rules, and the labels, callback URL and expiration of the draft.
+func (r ValidationRules) Validate(d *Draft) FieldErrors {
+	var fe FieldErrors
+
+	switch content := d.Content; {
+	case r.RequireContent && strings.TrimSpace(content) == "":
+		fe.Add("content", "must not be empty")
+	case r.MaxContentLength > 0 && len(content) > r.MaxContentLength:
+		fe.Add("content", fmt.Sprintf("must be at most %d bytes", r.MaxContentLength))
+	case r.RequireUTF8 && (!utf8.ValidString(content) || strings.ContainsRune(content, utf8.RuneError)):
+		fe.Add("content", "must be valid UTF-8")
+	default:
+		for _, re := range r.DenyPatterns {
+			if re.MatchString(content) {
+				fe.Add("content", "contains denied text")
+				break
+			}
+		}
+	}
+
+	if err := d.Labels.Validate(); err != nil {
+		fe.Add("labels", err.Error())
+	}
+	if err := ValidateCallbackURL(d.CallbackURL); err != nil {
+		fe.Add("callback_url", err.Error())
+	}
+
+	if d.TTL != nil {
+		switch {
+		case d.ExpiresAt != nil:
+			fe.Add("ttl", "only one of expires_at and ttl can be set")
+		case *d.TTL <= 0:
+			fe.Add("ttl", "must be positive")
+		}
+	}
+
+	return fe
+}
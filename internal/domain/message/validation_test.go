@@ -0,0 +1,73 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestValidationRules_Validate(t *testing.T) {
+	rules := ValidationRules{
+		MaxContentLength: 10,
+		RequireContent:   true,
+		RequireUTF8:      true,
+		DenyPatterns:     []*regexp.Regexp{regexp.MustCompile(`(?i)spam`)},
+	}
+	ttl := time.Hour
+	negativeTTL := -time.Minute
+	expiresAt := time.Now().Add(time.Hour)
+
+	tcases := []struct {
+		Name     string
+		Draft    Draft
+		Expected FieldErrors
+	}{
+		{Name: "valid", Draft: Draft{Content: "hello", TTL: &ttl}},
+		{Name: "empty", Draft: Draft{Content: " "}, Expected: FieldErrors{{"content", "must not be empty"}}},
+		{
+			Name:     "too long",
+			Draft:    Draft{Content: "hello world"},
+			Expected: FieldErrors{{"content", "must be at most 10 bytes"}},
+		},
+		{
+			Name:     "invalid utf-8",
+			Draft:    Draft{Content: "\xff"},
+			Expected: FieldErrors{{"content", "must be valid UTF-8"}},
+		},
+		{Name: "denied", Draft: Draft{Content: "SPAM"}, Expected: FieldErrors{{"content", "contains denied text"}}},
+		{
+			Name: "all fields",
+			Draft: Draft{
+				Content: "hi", Labels: Labels{"-": "x"}, CallbackURL: "ftp://example.com", ExpiresAt: &expiresAt, TTL: &ttl,
+			},
+			Expected: FieldErrors{
+				{"labels", Labels{"-": "x"}.Validate().Error()},
+				{"callback_url", "invalid callback url: scheme must be http or https"},
+				{"ttl", "only one of expires_at and ttl can be set"},
+			},
+		},
+		{
+			Name:     "negative ttl",
+			Draft:    Draft{Content: "hi", TTL: &negativeTTL},
+			Expected: FieldErrors{{"ttl", "must be positive"}},
+		},
+	}
+
+	for _, tc := range tcases {
+		assert.Equal(t, tc.Expected, rules.Validate(&tc.Draft), tc.Name)
+	}
+
+	assert.Empty(t, ValidationRules{}.Validate(&Draft{}), "the zero value accepts any content")
+}
+
+func TestDraft_Message(t *testing.T) {
+	ttl := time.Hour
+	msg := (&Draft{Content: "hello", Labels: Labels{"a": "b"}, TTL: &ttl}).Message()
+
+	assert.Equal(t, "hello", msg.Content)
+	assert.Equal(t, Labels{"a": "b"}, msg.Labels)
+	if assert.NotNil(t, msg.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(ttl), *msg.ExpiresAt, time.Minute)
+	}
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Repository keeps request counters shared by all replicas. A counter is kept
+// for the current fixed window of the key and the window before it.
+type Repository interface {
+	// Increment atomically adds amount to the counter of the key in the window that starts at window
+	// and lasts length. The counter of the previous window is kept. It returns the counters
+	// after the increment like Get.
+	Increment(ctx context.Context, key string, window time.Time, length time.Duration,
+		amount int) (curr, prev int, err error)
+	// Get returns the counters of the key in the window that starts at window and the previous one.
+	Get(ctx context.Context, key string, window time.Time, length time.Duration) (curr, prev int, err error)
+}
+
+type Error struct {
+	Key string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rate limit key %q: %v", e.Key, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
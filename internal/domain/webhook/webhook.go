@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"messagio_assignment/internal/domain/message"
+	"time"
+)
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	// StatusPending waits for the next attempt.
+	StatusPending Status = "pending"
+	// StatusSucceeded got a 2xx response.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed used all attempts, it is sent again only if redelivered.
+	StatusFailed Status = "failed"
+)
+
+var Statuses = []Status{StatusPending, StatusSucceeded, StatusFailed}
+
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusSucceeded, StatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Delivery is a webhook about the processing result of a message. Deliveries are enqueued by
+// message.Repository.UpdateStatus and kept as the delivery log after they are sent.
+type Delivery struct {
+	ID        int
+	MessageID int
+	URL       string
+	// MessageStatus is the result the webhook is about and EventAt is when it was reached.
+	MessageStatus message.Status
+	EventAt       time.Time
+
+	Status   Status
+	Attempts int
+	// NextAttemptAt is when a pending delivery is sent.
+	NextAttemptAt time.Time
+	LastAttemptAt *time.Time
+	// LastResponseCode is the HTTP status of the last attempt, zero if no response was received.
+	LastResponseCode int
+	LastError        string
+
+	CreatedAt   time.Time
+	DeliveredAt *time.Time
+}
+
+// RetryPolicy is the exponential backoff of failed attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts before the delivery fails.
+	MaxAttempts int
+	// MinBackoff is the delay after the first failed attempt, it doubles after each next one.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay.
+	MaxBackoff time.Duration
+}
+
+// Backoff returns the delay after the attempt with the number, starting from 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// Record applies the result of an attempt made at the time. code is the response status, zero if there was none.
+// A failed delivery is retried after the backoff until the policy runs out of attempts.
+func (d *Delivery) Record(at time.Time, code int, err error, p RetryPolicy) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.LastResponseCode = code
+	d.LastError = ""
+
+	if err == nil {
+		d.Status = StatusSucceeded
+		d.DeliveredAt = &at
+		return
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= p.MaxAttempts {
+		d.Status = StatusFailed
+		return
+	}
+	d.Status = StatusPending
+	d.NextAttemptAt = at.Add(p.Backoff(d.Attempts))
+}
+
+// ListFilter selects deliveries. Zero fields don't filter.
+type ListFilter struct {
+	MessageID int
+	Status    Status
+	// AfterID is the cursor, deliveries are ordered by ID.
+	AfterID int
+	Limit   int
+}
+
+// Page is a page of deliveries. NextAfterID is the cursor of the next page, zero if it is the last one.
+type Page struct {
+	Deliveries  []*Delivery
+	NextAfterID int
+}
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+//go:generate mockery --name Repository
+type Repository interface {
+	// ClaimDue returns up to limit pending deliveries whose next attempt has come and postpones
+	// their next attempt by lease, so other replicas don't send them at the same time.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error)
+	// SaveAttempt stores the result of the attempt applied by Delivery.Record.
+	SaveAttempt(ctx context.Context, d *Delivery) error
+	List(ctx context.Context, filter ListFilter) ([]*Delivery, error)
+	// Redeliver makes the delivery pending with no attempts, so it is sent right away with all attempts again.
+	// It returns domain.ErrNotFound if the delivery doesn't exist.
+	Redeliver(ctx context.Context, id int) (*Delivery, error)
+}
+
+//go:generate mockery --name Sender
+
+// Sender sends the webhook of the delivery. It returns the response status, zero if there was no response,
+// and an error if the delivery has to be retried.
+type Sender interface {
+	Send(ctx context.Context, d *Delivery) (int, error)
+}
+
+type Error struct {
+	ID  int
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.ID == 0 {
+		return fmt.Sprintf("webhook delivery: %v", e.Err)
+	}
+	return fmt.Sprintf("webhook delivery with %d id: %v", e.ID, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
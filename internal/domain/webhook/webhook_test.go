@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, MinBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	assert.Equal(t, 10*time.Second, p.Backoff(1))
+	assert.Equal(t, 20*time.Second, p.Backoff(2))
+	assert.Equal(t, 40*time.Second, p.Backoff(3))
+	assert.Equal(t, time.Minute, p.Backoff(4))
+	assert.Equal(t, time.Minute, p.Backoff(100))
+}
+
+func TestDelivery_Record(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 2, MinBackoff: time.Second, MaxBackoff: time.Minute}
+	at := time.Date(2024, 8, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("succeeded", func(t *testing.T) {
+		d := &Delivery{Status: StatusPending, LastError: "timeout"}
+		d.Record(at, 204, nil, p)
+
+		assert.Equal(t, StatusSucceeded, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		assert.Equal(t, 204, d.LastResponseCode)
+		assert.Empty(t, d.LastError)
+		assert.Equal(t, &at, d.DeliveredAt)
+	})
+
+	t.Run("retried_then_failed", func(t *testing.T) {
+		d := &Delivery{Status: StatusPending}
+
+		d.Record(at, 500, errors.New("unexpected status 500"), p)
+		assert.Equal(t, StatusPending, d.Status)
+		assert.Equal(t, at.Add(time.Second), d.NextAttemptAt)
+		assert.Equal(t, "unexpected status 500", d.LastError)
+
+		d.Record(at.Add(time.Second), 0, errors.New("connection refused"), p)
+		assert.Equal(t, StatusFailed, d.Status)
+		assert.Equal(t, 2, d.Attempts)
+		assert.Equal(t, 0, d.LastResponseCode)
+		assert.Nil(t, d.DeliveredAt)
+	})
+}
@@ -0,0 +1,344 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/domain/ratelimit"
+	"sync"
+	"time"
+)
+
+// Backends, where the counters are kept.
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// StoreTimeout bounds the store calls.
+const StoreTimeout = time.Second
+
+// Route names of the message API, the keys of Tier. The REST and gRPC APIs have the same routes.
+const (
+	RouteCreateMsg   = "create_msg"
+	RouteCreateBatch = "create_batch"
+	RouteGetMsg      = "get_msg"
+	RouteCancelMsg   = "cancel_msg"
+	RouteDeleteMsg   = "delete_msg"
+	RouteListMsg     = "list_msg"
+	RouteSearch      = "search"
+	RouteGetStats    = "get_stats"
+	RouteEvents      = "events"
+)
+
+var routes = map[string]bool{
+	RouteCreateMsg:   true,
+	RouteCreateBatch: true,
+	RouteGetMsg:      true,
+	RouteCancelMsg:   true,
+	RouteDeleteMsg:   true,
+	RouteListMsg:     true,
+	RouteSearch:      true,
+	RouteGetStats:    true,
+	RouteEvents:      true,
+}
+
+// Limit is the limit of a route for one client.
+type Limit struct {
+	// PerMinute is the sustained number of requests per minute. Zero disables the limit.
+	PerMinute int
+	// Burst is the number of requests a client can make at once, PerMinute if zero.
+	Burst int
+}
+
+// Window returns the sliding window of the limit, so that Burst requests are allowed
+// in the window and the sustained rate is PerMinute.
+func (l Limit) Window() (int, time.Duration) {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = l.PerMinute
+	}
+
+	return burst, time.Duration(burst) * time.Minute / time.Duration(l.PerMinute)
+}
+
+// Tier is the limits of routes by route name. Routes without a limit are not limited.
+type Tier map[string]Limit
+
+// Config sets the limits of clients. Clients are the authenticated principal,
+// the value of KeyHeader or the IP, in this order. The zero value disables limits.
+type Config struct {
+	// KeyHeader identifies anonymous clients, e.g. a header set by the load balancer.
+	KeyHeader string
+	Tiers     map[string]Tier
+	// DefaultTier is the tier of clients that are not in Clients.
+	DefaultTier string
+	// Clients maps client keys to tier names. The keys are the principal subject
+	// (e.g. apikey:1 or jwt:crm), client:<KeyHeader value> or ip:<IP>.
+	Clients map[string]string
+	// Store keeps the counters. NewConfig sets it to the store shared by all replicas or to the counters
+	// in memory of this replica, so the limiters of the config count the calls of all APIs together.
+	// If it is nil, each limiter counts in memory separately.
+	Store ratelimit.Repository
+}
+
+// NewConfig converts the config of the rate limit tiers.
+// The store is used if the postgres backend is selected. The config must be shared by the APIs,
+// otherwise they count calls separately with the memory backend.
+func NewConfig(cfg config.RateLimit, store ratelimit.Repository) (Config, error) {
+	tiers := make(map[string]Tier, len(cfg.Tiers))
+	for name, routes := range cfg.Tiers {
+		tier := make(Tier, len(routes))
+		for route, limit := range routes {
+			tier[route] = Limit{PerMinute: limit.PerMinute, Burst: limit.Burst}
+		}
+		tiers[name] = tier
+	}
+
+	res := Config{
+		KeyHeader:   cfg.KeyHeader,
+		Tiers:       tiers,
+		DefaultTier: cfg.DefaultTier,
+		Clients:     cfg.Clients,
+	}
+
+	switch cfg.Backend {
+	case "", BackendMemory:
+		res.Store = newLocalCounter()
+	case BackendPostgres:
+		if store == nil {
+			return Config{}, fmt.Errorf("rate limit: %s backend has no store", cfg.Backend)
+		}
+		res.Store = store
+	default:
+		return Config{}, fmt.Errorf("rate limit: unknown backend %q", cfg.Backend)
+	}
+
+	return res, res.Validate()
+}
+
+// Validate checks that the tiers of clients exist and the routes are known.
+func (c Config) Validate() error {
+	if _, ok := c.Tiers[c.DefaultTier]; !ok && c.DefaultTier != "" {
+		return fmt.Errorf("rate limit: default tier %q is not defined", c.DefaultTier)
+	}
+
+	for client, tier := range c.Clients {
+		if _, ok := c.Tiers[tier]; !ok {
+			return fmt.Errorf("rate limit: tier %q of client %q is not defined", tier, client)
+		}
+	}
+
+	for name, tier := range c.Tiers {
+		for route, limit := range tier {
+			if !routes[route] {
+				return fmt.Errorf("rate limit: tier %q: unknown route %q", name, route)
+			}
+			if limit.PerMinute < 0 || limit.Burst < 0 {
+				return fmt.Errorf("rate limit: tier %q: route %q: limits must not be negative", name, route)
+			}
+			if limit.PerMinute == 0 {
+				continue
+			}
+			// Retry-After has a precision of seconds.
+			if _, window := limit.Window(); window < time.Second {
+				return fmt.Errorf("rate limit: tier %q: route %q: burst must be at least per_minute/60, "+
+					"so the window is at least a second", name, route)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Tier returns the tier name of the client.
+func (c Config) Tier(client string) string {
+	if tier, ok := c.Clients[client]; ok {
+		return tier
+	}
+	return c.DefaultTier
+}
+
+// RouteLimiter limits calls to the route for each client, the REST and gRPC APIs share it.
+// With a shared store the calls of all replicas are counted together.
+type RouteLimiter struct {
+	cfg    Config
+	route  string
+	limits map[string]routeLimit
+}
+
+// Decision is the result of RouteLimiter.Allow.
+type Decision struct {
+	Allowed bool
+	// Limit is the number of calls allowed in the window, zero if the client is not limited.
+	Limit int
+	// Remaining is the number of calls left in the window.
+	Remaining int
+	// Reset is when the current window ends.
+	Reset time.Time
+	// RetryAfter is how long to wait before retrying a call that is not allowed.
+	RetryAfter time.Duration
+}
+
+// routeLimit is the limit of the route in a tier.
+type routeLimit struct {
+	requests int
+	window   time.Duration
+	counter  ratelimit.Repository
+}
+
+// NewRouteLimiter returns the limiter of the route, it doesn't limit clients of tiers without the route limit.
+func (c Config) NewRouteLimiter(route string) *RouteLimiter {
+	limits := make(map[string]routeLimit, len(c.Tiers))
+	for name, tier := range c.Tiers {
+		limit, ok := tier[route]
+		if !ok || limit.PerMinute == 0 {
+			continue
+		}
+
+		requests, window := limit.Window()
+		counter := c.Store
+		if counter == nil {
+			counter = newLocalCounter()
+		}
+		limits[name] = routeLimit{requests: requests, window: window, counter: counter}
+	}
+
+	return &RouteLimiter{cfg: c, route: route, limits: limits}
+}
+
+// Allow counts the call of the client if it is within the limit of the client tier.
+// Otherwise, it returns the decision with how long to wait before retrying.
+// The call is counted with one atomic increment, so concurrent calls of all replicas are limited together.
+// A call over the limit is not counted.
+func (l *RouteLimiter) Allow(ctx context.Context, client string) (Decision, error) {
+	limit, ok := l.limits[l.cfg.Tier(client)]
+	if !ok {
+		return Decision{Allowed: true}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, StoreTimeout)
+	defer cancel()
+
+	// The sliding window of httprate.
+	key := l.route + ":" + client
+	now := time.Now().UTC()
+	currentWindow := now.Truncate(limit.window)
+
+	curr, prev, err := limit.counter.Increment(ctx, key, currentWindow, limit.window, 1)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	// the rate before this call
+	curr--
+	elapsed := now.Sub(currentWindow)
+	rate := int(math.Round(float64(prev)*float64(limit.window-elapsed)/float64(limit.window) + float64(curr)))
+
+	d := Decision{Limit: limit.requests, Reset: currentWindow.Add(limit.window)}
+	if rate+1 <= limit.requests {
+		d.Allowed = true
+		d.Remaining = limit.requests - rate - 1
+		return d, nil
+	}
+
+	if _, _, err := limit.counter.Increment(ctx, key, currentWindow, limit.window, -1); err != nil {
+		return Decision{}, err
+	}
+	d.RetryAfter = retryAfter(limit.requests, limit.window, elapsed, curr, prev)
+	return d, nil
+}
+
+// retryAfter returns how long to wait until the sliding window rate allows one more request,
+// rounded up to seconds. The rate is prev*(window-elapsed)/window + curr, it decreases while
+// the previous window slides out, and then the current counter becomes the previous one.
+func retryAfter(requests int, window, elapsed time.Duration, curr, prev int) time.Duration {
+	// The call is allowed while the rounded rate is less than requests.
+	threshold := float64(requests) - 0.5
+
+	var wait time.Duration
+	if room := threshold - float64(curr); room > 0 && prev > 0 {
+		// the weight of the previous window has to drop to room/prev in this window
+		wait = time.Duration((1-room/float64(prev))*float64(window)) - elapsed
+	} else {
+		// and in the next window, curr is the previous counter
+		wait = window - elapsed
+		if float64(curr) >= threshold {
+			wait += time.Duration((1 - threshold/float64(curr)) * float64(window))
+		}
+	}
+
+	wait = max(wait, 0).Truncate(time.Second) + time.Second
+	return min(wait, 2*window)
+}
+
+// localCounter is ratelimit.Repository in memory for limiters of one replica.
+// It keeps one counter per key like pgstore.RateLimitRepoPG.
+type localCounter struct {
+	mu       sync.Mutex
+	counters map[string]*localWindow
+	swept    time.Time
+}
+
+type localWindow struct {
+	start      time.Time
+	curr, prev int
+}
+
+func newLocalCounter() *localCounter {
+	return &localCounter{counters: make(map[string]*localWindow)}
+}
+
+func (c *localCounter) Increment(_ context.Context, key string, window time.Time,
+	length time.Duration, amount int) (int, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The counters of clients that have not called since the previous window are not needed.
+	if window.After(c.swept) {
+		for k, w := range c.counters {
+			if w.start.Before(window.Add(-length)) {
+				delete(c.counters, k)
+			}
+		}
+		c.swept = window
+	}
+
+	w, ok := c.counters[key]
+	switch {
+	case !ok:
+		w = &localWindow{start: window}
+		c.counters[key] = w
+	case w.start.Equal(window.Add(-length)):
+		w.start, w.curr, w.prev = window, 0, w.curr
+	case w.start.Before(window):
+		w.start, w.curr, w.prev = window, 0, 0
+	}
+	w.curr += amount
+
+	return c.get(w, window, length)
+}
+
+func (c *localCounter) Get(_ context.Context, key string, window time.Time,
+	length time.Duration) (int, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, ok := c.counters[key]
+	if !ok {
+		return 0, 0, nil
+	}
+	return c.get(w, window, length)
+}
+
+func (c *localCounter) get(w *localWindow, window time.Time, length time.Duration) (int, int, error) {
+	switch {
+	case !w.start.Before(window):
+		return w.curr, w.prev, nil
+	case w.start.Equal(window.Add(-length)):
+		return 0, w.curr, nil
+	default:
+		return 0, 0, nil
+	}
+}
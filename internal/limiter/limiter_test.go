@@ -0,0 +1,235 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/domain/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestLimit_Window(t *testing.T) {
+	tcases := []struct {
+		Name             string
+		Limit            Limit
+		ExpectedRequests int
+		ExpectedWindow   time.Duration
+	}{
+		{
+			Name:             "no_burst",
+			Limit:            Limit{PerMinute: 50},
+			ExpectedRequests: 50,
+			ExpectedWindow:   time.Minute,
+		},
+		{
+			Name:             "burst",
+			Limit:            Limit{PerMinute: 60, Burst: 10},
+			ExpectedRequests: 10,
+			ExpectedWindow:   10 * time.Second,
+		},
+		{
+			Name:             "burst_above_limit",
+			Limit:            Limit{PerMinute: 30, Burst: 60},
+			ExpectedRequests: 60,
+			ExpectedWindow:   2 * time.Minute,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			requests, window := tc.Limit.Window()
+			assert.Equal(t, tc.ExpectedRequests, requests)
+			assert.Equal(t, tc.ExpectedWindow, window)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tiers := map[string]Tier{"default": {RouteGetMsg: {PerMinute: 1}}}
+
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Tiers: tiers, DefaultTier: "default"}.Validate())
+	assert.Error(t, Config{Tiers: tiers, DefaultTier: "premium"}.Validate())
+	assert.Error(t, Config{Tiers: tiers, Clients: map[string]string{"apikey:1": "premium"}}.Validate())
+	assert.Error(t, Config{Tiers: map[string]Tier{"default": {"unknown": {PerMinute: 1}}}}.Validate())
+	assert.Error(t, Config{Tiers: map[string]Tier{"default": {RouteGetMsg: {PerMinute: -1}}}}.Validate())
+	assert.NoError(t, Config{Tiers: map[string]Tier{"default": {RouteGetMsg: {PerMinute: 6000, Burst: 100}}}}.Validate())
+	assert.Error(t, Config{Tiers: map[string]Tier{"default": {RouteGetMsg: {PerMinute: 6000, Burst: 10}}}}.Validate(),
+		"the window is less than a second")
+}
+
+func TestNewConfig_Backend(t *testing.T) {
+	store := newLocalCounter()
+	cfg := config.RateLimit{
+		DefaultTier: "default",
+		Tiers:       map[string]map[string]config.RateLimitRoute{"default": {RouteGetMsg: {PerMinute: 1}}},
+	}
+
+	got, err := NewConfig(cfg, store)
+	require.NoError(t, err)
+	assert.IsType(t, &localCounter{}, got.Store, "memory is the default backend")
+	assert.NotSame(t, store, got.Store)
+
+	// The limiters of the REST and gRPC APIs count the calls together.
+	first, second := got.NewRouteLimiter(RouteGetMsg), got.NewRouteLimiter(RouteGetMsg)
+	d, err := first.Allow(context.Background(), "apikey:1")
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+	d, err = second.Allow(context.Background(), "apikey:1")
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+
+	cfg.Backend = BackendPostgres
+	got, err = NewConfig(cfg, store)
+	require.NoError(t, err)
+	assert.Same(t, store, got.Store)
+
+	_, err = NewConfig(cfg, nil)
+	assert.Error(t, err)
+
+	cfg.Backend = "redis"
+	_, err = NewConfig(cfg, store)
+	assert.Error(t, err)
+}
+
+// errStore is ratelimit.Repository that is down.
+type errStore struct{}
+
+func (errStore) Increment(context.Context, string, time.Time, time.Duration, int) (int, int, error) {
+	return 0, 0, errors.New("db is down")
+}
+
+func (errStore) Get(context.Context, string, time.Time, time.Duration) (int, int, error) {
+	return 0, 0, errors.New("db is down")
+}
+
+func TestRouteLimiter(t *testing.T) {
+	store := newLocalCounter()
+	cfg := Config{
+		DefaultTier: "default",
+		Tiers: map[string]Tier{
+			"default":   {RouteGetMsg: {PerMinute: 2}},
+			"unlimited": {},
+		},
+		Clients: map[string]string{"apikey:2": "unlimited"},
+	}
+
+	for name, store := range map[string]ratelimit.Repository{"memory": nil, "store": store} {
+		t.Run(name, func(t *testing.T) {
+			cfg.Store = store
+			limiter := cfg.NewRouteLimiter(RouteGetMsg)
+			ctx := context.Background()
+
+			for remaining := 1; remaining >= 0; remaining-- {
+				d, err := limiter.Allow(ctx, "apikey:1")
+				require.NoError(t, err)
+				assert.True(t, d.Allowed)
+				assert.Equal(t, 2, d.Limit)
+				assert.Equal(t, remaining, d.Remaining)
+				assert.WithinRange(t, d.Reset, time.Now(), time.Now().Add(time.Minute), "the window ends")
+			}
+
+			for i := 0; i < 2; i++ {
+				d, err := limiter.Allow(ctx, "apikey:1")
+				require.NoError(t, err)
+				assert.False(t, d.Allowed)
+				assert.Zero(t, d.Remaining)
+				// until the previous window weight of 2 calls drops below 1.5
+				assert.Greater(t, d.RetryAfter, 15*time.Second)
+				assert.LessOrEqual(t, d.RetryAfter, 76*time.Second)
+			}
+
+			counter := limiter.limits["default"].counter
+			window := time.Now().UTC().Truncate(time.Minute)
+			curr, _, err := counter.Get(ctx, "get_msg:apikey:1", window, time.Minute)
+			require.NoError(t, err)
+			if curr != 0 { // the window may have just rolled
+				assert.Equal(t, 2, curr, "calls over the limit are not counted")
+			}
+
+			for i := 0; i < 3; i++ {
+				d, err := limiter.Allow(ctx, "apikey:2")
+				require.NoError(t, err)
+				assert.Equal(t, Decision{Allowed: true}, d, "client of a tier without the route limit is limited")
+			}
+		})
+	}
+
+	t.Run("store_error", func(t *testing.T) {
+		cfg.Store = errStore{}
+		_, err := cfg.NewRouteLimiter(RouteGetMsg).Allow(context.Background(), "apikey:1")
+		assert.Error(t, err)
+	})
+
+	// The HTTP limiter uses the same counter keys.
+	for key := range store.counters {
+		assert.Equal(t, "get_msg:apikey:1", key)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tcases := []struct {
+		Name     string
+		Requests int
+		Elapsed  time.Duration
+		Curr     int
+		Prev     int
+		Expected time.Duration
+	}{
+		{
+			Name:     "previous_window_slides_out",
+			Requests: 10, Elapsed: 15 * time.Second, Curr: 2, Prev: 10,
+			// 10*(60-e)/60 < 7.5 at e > 15s
+			Expected: time.Second,
+		},
+		{
+			Name:     "previous_window_slides_out_later",
+			Requests: 10, Elapsed: 0, Curr: 5, Prev: 10,
+			// 10*(60-e)/60 < 4.5 at e > 33s
+			Expected: 34 * time.Second,
+		},
+		{
+			Name:     "next_window",
+			Requests: 2, Elapsed: 20 * time.Second, Curr: 2, Prev: 0,
+			// 40s to the next window, then 2*(60-e)/60 < 1.5 at e > 15s
+			Expected: 56 * time.Second,
+		},
+		{
+			Name:     "next_window_over_limit",
+			Requests: 2, Elapsed: 0, Curr: 10, Prev: 10,
+			Expected: 112 * time.Second,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, retryAfter(tc.Requests, time.Minute, tc.Elapsed, tc.Curr, tc.Prev))
+		})
+	}
+}
+
+func TestLocalCounter(t *testing.T) {
+	ctx := context.Background()
+	length := time.Minute
+	window := time.Now().UTC().Truncate(length)
+	counter := newLocalCounter()
+
+	curr, prev, err := counter.Increment(ctx, "a", window, length, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 0}, []int{curr, prev})
+
+	curr, prev, err = counter.Increment(ctx, "a", window.Add(length), length, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, []int{curr, prev})
+
+	curr, prev, err = counter.Get(ctx, "a", window.Add(2*length), length)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, []int{curr, prev})
+
+	_, _, err = counter.Increment(ctx, "b", window.Add(5*length), length, 1)
+	require.NoError(t, err)
+	assert.NotContains(t, counter.counters, "a", "old counters are removed")
+}
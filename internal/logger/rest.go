@@ -4,11 +4,16 @@ import (
 	"context"
 	"github.com/go-chi/chi/v5/middleware"
 	"log/slog"
+	"messagio_assignment/internal/domain/auth"
 )
 
 func ForRest(logger *slog.Logger, handler string, reqCtx context.Context) *slog.Logger {
-	return logger.With(
+	logger = logger.With(
 		slog.String("handler", handler),
 		slog.String("request_id", middleware.GetReqID(reqCtx)),
 	)
+	if principal, ok := auth.PrincipalFrom(reqCtx); ok {
+		logger = logger.With(slog.String("principal", principal.Subject))
+	}
+	return logger
 }
@@ -0,0 +1,194 @@
+package grpcapi
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"messagio_assignment/api/messagiov1"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"time"
+)
+
+var statusToPB = map[message.Status]messagiov1.Status{
+	message.StatusPending:    messagiov1.Status_STATUS_PENDING,
+	message.StatusQueued:     messagiov1.Status_STATUS_QUEUED,
+	message.StatusProcessing: messagiov1.Status_STATUS_PROCESSING,
+	message.StatusProcessed:  messagiov1.Status_STATUS_PROCESSED,
+	message.StatusFailed:     messagiov1.Status_STATUS_FAILED,
+	message.StatusCancelled:  messagiov1.Status_STATUS_CANCELLED,
+	message.StatusExpired:    messagiov1.Status_STATUS_EXPIRED,
+}
+
+var statusFromPB = func() map[messagiov1.Status]message.Status {
+	res := make(map[messagiov1.Status]message.Status, len(statusToPB))
+	for s, pb := range statusToPB {
+		res[pb] = s
+	}
+	return res
+}()
+
+var bucketFromPB = map[messagiov1.Bucket]message.Bucket{
+	messagiov1.Bucket_BUCKET_UNSPECIFIED: message.BucketNone,
+	messagiov1.Bucket_BUCKET_MINUTE:      message.BucketMinute,
+	messagiov1.Bucket_BUCKET_HOUR:        message.BucketHour,
+	messagiov1.Bucket_BUCKET_DAY:         message.BucketDay,
+}
+
+// draftFromPB converts the request to the draft, so that it is validated by the same rules as in the REST API.
+func draftFromPB(req *messagiov1.CreateMessageRequest) *message.Draft {
+	draft := &message.Draft{
+		Content:     req.GetContent(),
+		Labels:      req.GetLabels(),
+		SendAt:      timeFromPB(req.GetSendAt()),
+		ExpiresAt:   timeFromPB(req.GetExpiresAt()),
+		CallbackURL: req.GetCallbackUrl(),
+	}
+	if req.GetTtl() != nil {
+		ttl := req.GetTtl().AsDuration()
+		draft.TTL = &ttl
+	}
+	return draft
+}
+
+// listFilter converts the request, it returns all invalid fields, like the validation of created messages.
+func listFilter(req *messagiov1.ListMessagesRequest) (message.ListFilter, message.FieldErrors) {
+	filter := message.ListFilter{
+		Labels:      req.GetLabels(),
+		CreatedFrom: timeFromPB(req.GetCreatedFrom()),
+		CreatedTo:   timeFromPB(req.GetCreatedTo()),
+		Limit:       int(req.GetPageSize()),
+	}
+	var fe message.FieldErrors
+
+	if req.GetStatus() != messagiov1.Status_STATUS_UNSPECIFIED {
+		status, ok := statusFromPB[req.GetStatus()]
+		if ok {
+			filter.Status = &status
+		} else {
+			fe.Add("status", fmt.Sprintf("unknown status %v", req.GetStatus()))
+		}
+	}
+	if err := message.Labels(req.GetLabels()).Validate(); err != nil {
+		fe.Add("labels", err.Error())
+	}
+	if req.MinId != nil {
+		minID := int(req.GetMinId())
+		filter.MinID = &minID
+	}
+	if req.MaxId != nil {
+		maxID := int(req.GetMaxId())
+		filter.MaxID = &maxID
+	}
+	if req.GetPageSize() < 0 {
+		fe.Add("page_size", "must not be negative")
+	}
+	if req.GetPageToken() != "" {
+		cursor, err := domain.DecodeCursor(req.GetPageToken())
+		if err != nil {
+			fe.Add("page_token", err.Error())
+		}
+		filter.AfterID = cursor.AfterID
+	}
+
+	return filter, fe
+}
+
+func statsFilter(req *messagiov1.GetStatsRequest) (message.StatsFilter, message.FieldErrors) {
+	var fe message.FieldErrors
+
+	bucket, ok := bucketFromPB[req.GetBucket()]
+	if !ok {
+		fe.Add("bucket", fmt.Sprintf("unknown bucket %v", req.GetBucket()))
+	}
+	if err := message.Labels(req.GetLabels()).Validate(); err != nil {
+		fe.Add("labels", err.Error())
+	}
+
+	return message.StatsFilter{
+		From:         timeFromPB(req.GetFrom()),
+		To:           timeFromPB(req.GetTo()),
+		Bucket:       bucket,
+		Labels:       req.GetLabels(),
+		GroupByLabel: req.GetGroupByLabel(),
+	}, fe
+}
+
+func messageToPB(msg *message.Message) *messagiov1.Message {
+	return &messagiov1.Message{
+		Id:          int64(msg.ID),
+		Content:     msg.Content,
+		Status:      statusToPB[msg.Status],
+		Labels:      msg.Labels,
+		SendAt:      timeToPB(msg.SendAt),
+		ExpiresAt:   timeToPB(msg.ExpiresAt),
+		CallbackUrl: msg.CallbackURL,
+		CreatedAt:   timestamppb.New(msg.CreatedAt),
+		ProcessedAt: timeToPB(msg.ProcessedAt),
+	}
+}
+
+func pageToPB(page *message.Page) *messagiov1.ListMessagesResponse {
+	resp := &messagiov1.ListMessagesResponse{Messages: make([]*messagiov1.Message, len(page.Messages))}
+	for i, msg := range page.Messages {
+		resp.Messages[i] = messageToPB(msg)
+	}
+	if page.NextAfterID != 0 {
+		resp.NextPageToken = domain.Cursor{AfterID: page.NextAfterID}.Encode()
+	}
+	return resp
+}
+
+func statsToPB(stats *message.Stats) *messagiov1.Stats {
+	resp := &messagiov1.Stats{
+		All:           int64(stats.All),
+		Processed:     int64(stats.Processed),
+		ByStatus:      make(map[string]int64, len(stats.ByStatus)),
+		OutboxPending: int64(stats.OutboxPending),
+	}
+	for status, n := range stats.ByStatus {
+		resp.ByStatus[status.String()] = int64(n)
+	}
+	for _, ls := range stats.ByLabel {
+		resp.ByLabel = append(resp.ByLabel, &messagiov1.LabelStats{
+			Value: ls.Value, All: int64(ls.All), Processed: int64(ls.Processed),
+		})
+	}
+	for _, b := range stats.Series {
+		bucket := &messagiov1.StatsBucket{
+			Start: timestamppb.New(b.Start), Created: int64(b.Created), Processed: int64(b.Processed),
+		}
+		if b.Latency != nil {
+			bucket.Latency = &messagiov1.Latency{
+				P50: durationpb.New(b.Latency.P50),
+				P95: durationpb.New(b.Latency.P95),
+				P99: durationpb.New(b.Latency.P99),
+			}
+		}
+		resp.Series = append(resp.Series, bucket)
+	}
+	return resp
+}
+
+func eventToPB(ev message.Event) *messagiov1.MessageEvent {
+	return &messagiov1.MessageEvent{
+		MessageId: int64(ev.MessageID),
+		Status:    statusToPB[ev.Status],
+		At:        timestamppb.New(ev.At),
+	}
+}
+
+func timeFromPB(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func timeToPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
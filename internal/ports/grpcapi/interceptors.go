@@ -0,0 +1,270 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/logger"
+	"net"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RequestIDHeader is the metadata key of the request id, the same as the header of the REST API.
+	RequestIDHeader = "x-request-id"
+	// RetryAfterHeader is the metadata key with seconds to wait before retrying a rate limited call.
+	RetryAfterHeader = "retry-after"
+	// APIKeyHeader is the metadata key of the API key, the same as the header of the REST API.
+	APIKeyHeader = "x-api-key"
+)
+
+// interceptor wraps the handling of a call, so the same code serves unary and stream calls.
+type interceptor func(ctx context.Context, method string, handle func(ctx context.Context) error) error
+
+func unary(i interceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := i(ctx, info.FullMethod, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func stream(i interceptor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// serverStream replaces the context of the stream with the one of the interceptors.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestIDPrefix makes request ids unique across replicas, like in chi middleware.RequestID.
+var requestIDPrefix = func() string {
+	hostname, err := os.Hostname()
+	if hostname == "" || err != nil {
+		hostname = "localhost"
+	}
+
+	var buf [12]byte
+	_, _ = rand.Read(buf[:])
+	b64 := strings.NewReplacer("+", "", "/", "").Replace(base64.StdEncoding.EncodeToString(buf[:]))
+
+	return fmt.Sprintf("%s/%s", hostname, b64[:10])
+}()
+
+// requestID takes the request id from the metadata or generates it. It is put into the context
+// like chi middleware.RequestID does, so logger.ForRest logs it, and is sent back in the header.
+func requestID() interceptor {
+	return func(ctx context.Context, _ string, handle func(ctx context.Context) error) error {
+		var id string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(RequestIDHeader); len(ids) > 0 {
+				id = ids[0]
+			}
+		}
+		if id == "" {
+			id = fmt.Sprintf("%s-%06d", requestIDPrefix, middleware.NextRequestID())
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+		return handle(context.WithValue(ctx, middleware.RequestIDKey, id))
+	}
+}
+
+// logCalls logs calls like rest.LogMiddleware logs requests.
+func logCalls(log *slog.Logger) interceptor {
+	log = log.With(
+		slog.String("component", "middleware/grpc_log"),
+	)
+
+	return func(ctx context.Context, method string, handle func(ctx context.Context) error) error {
+		entry := log.With(
+			slog.String("request_id", middleware.GetReqID(ctx)),
+			slog.String("method", method),
+		)
+		if p, ok := peer.FromContext(ctx); ok {
+			entry = entry.With(slog.String("remote_addr", p.Addr.String()))
+		}
+		entry.Info("request received")
+
+		before := time.Now()
+		err := handle(ctx)
+
+		entry.Info("request completed",
+			slog.String("code", status.Code(err).String()),
+			slog.String("duration", time.Since(before).String()),
+		)
+		return err
+	}
+}
+
+// recoverer turns panics into Internal errors, so the server keeps running, like chi middleware.Recoverer.
+func recoverer(log *slog.Logger) interceptor {
+	return func(ctx context.Context, method string, handle func(ctx context.Context) error) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error("panic is recovered",
+					slog.String("request_id", middleware.GetReqID(ctx)),
+					slog.String("method", method),
+					slog.Any("panic", rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err = newStatus(domain.CodeInternal, nil)
+			}
+		}()
+
+		return handle(ctx)
+	}
+}
+
+// reflectionPrefix is the method prefix of the reflection service, it describes the API to anyone.
+const reflectionPrefix = "/grpc.reflection."
+
+// authenticate authenticates calls with the x-api-key or authorization metadata and checks
+// the scope of the method, like rest.Auth. Methods without a scope are not allowed, except reflection.
+// Calls are anonymous if authenticator is nil.
+func authenticate(authenticator Authenticator, scopes map[string]auth.Scope, log *slog.Logger) interceptor {
+	log = log.With(
+		slog.String("component", "ports/grpcapi/auth"),
+	)
+
+	return func(ctx context.Context, method string, handle func(ctx context.Context) error) error {
+		if authenticator == nil || strings.HasPrefix(method, reflectionPrefix) {
+			return handle(ctx)
+		}
+
+		log := logger.ForRest(log, "auth", ctx)
+
+		scope, ok := scopes[method]
+		if !ok {
+			return status.Error(codes.Unimplemented, "unknown method")
+		}
+
+		token, err := callToken(ctx)
+		if err != nil {
+			log.Warn("failed to authenticate", logger.Err(err))
+			return toStatus(err)
+		}
+
+		principal, err := authenticator.Authenticate(ctx, token)
+		if err != nil {
+			logError(log, "failed to authenticate", err)
+			return toStatus(err)
+		}
+
+		if !principal.HasScope(scope) {
+			log.Warn("scope is not allowed",
+				slog.String("principal", principal.Subject), slog.String("scope", scope.String()))
+			return toStatus(fmt.Errorf("%w: %s scope is required", domain.ErrForbidden, scope))
+		}
+
+		return handle(auth.WithPrincipal(ctx, principal))
+	}
+}
+
+// callToken returns the x-api-key metadata or the Bearer token of the authorization metadata.
+func callToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if keys := md.Get(APIKeyHeader); len(keys) > 0 && keys[0] != "" {
+		return keys[0], nil
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return "", fmt.Errorf("%w: no credentials", domain.ErrUnauthenticated)
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("%w: authorization must be Bearer token", domain.ErrUnauthenticated)
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+// rateLimit limits calls of each client by the limits of the REST route of the method,
+// so the tiers in the config apply to both APIs. It must be used after authenticate.
+func rateLimit(cfg limiter.Config, routes map[string]string, log *slog.Logger) interceptor {
+	log = log.With(
+		slog.String("component", "ports/grpcapi/rate_limit"),
+	)
+
+	limiters := make(map[string]*limiter.RouteLimiter, len(routes))
+	for method, route := range routes {
+		limiters[method] = cfg.NewRouteLimiter(route)
+	}
+
+	return func(ctx context.Context, method string, handle func(ctx context.Context) error) error {
+		limiter, ok := limiters[method]
+		if !ok {
+			return handle(ctx)
+		}
+
+		client := clientKey(ctx, cfg.KeyHeader)
+		d, err := limiter.Allow(ctx, client)
+		if err != nil {
+			logger.ForRest(log, "rate limit", ctx).Error("failed to check rate limit", logger.Err(err))
+			return handle(ctx)
+		}
+		if !d.Allowed {
+			retryAfter := strconv.Itoa(int(d.RetryAfter.Seconds()))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, retryAfter))
+			return newStatus(domain.CodeRateLimited, nil)
+		}
+
+		return handle(ctx)
+	}
+}
+
+// clientKey returns the rate limit key of the client like the REST API does:
+// the principal subject, client:<key header value> or ip:<IP>.
+func clientKey(ctx context.Context, keyHeader string) string {
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		return p.Subject
+	}
+
+	if keyHeader != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if v := md.Get(keyHeader); len(v) > 0 && v[0] != "" {
+			return "client:" + v[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:"
+}
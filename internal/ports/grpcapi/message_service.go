@@ -0,0 +1,262 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"messagio_assignment/api/messagiov1"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+)
+
+//go:generate mockery --name MessageUsecase
+type MessageUsecase interface {
+	CreateMessage(ctx context.Context, msg *message.Message) error
+	GetMessage(ctx context.Context, id int) (*message.Message, error)
+	ListMessages(ctx context.Context, filter message.ListFilter) (*message.Page, error)
+	GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error)
+}
+
+//go:generate mockery --name Authenticator
+type Authenticator interface {
+	// Authenticate returns the principal of the token or domain.ErrUnauthenticated.
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+//go:generate mockery --name EventSubscriber
+type EventSubscriber interface {
+	// Subscribe returns the events of the message, of all messages if id is zero.
+	// The channel is closed by cancel or on shutdown.
+	Subscribe(id int) (events <-chan message.Event, cancel func())
+}
+
+// MessageService implements messagiov1.MessageServiceServer with the same usecase and rules as the REST API.
+type MessageService struct {
+	messagiov1.UnimplementedMessageServiceServer
+
+	uc         MessageUsecase
+	events     EventSubscriber
+	validation message.ValidationRules
+
+	Log *slog.Logger
+}
+
+func NewMessageService(uc MessageUsecase, events EventSubscriber, validation message.ValidationRules,
+	log *slog.Logger) *MessageService {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	log = log.With(
+		slog.String("component", "ports/grpcapi/message_service"),
+	)
+
+	return &MessageService{uc: uc, events: events, validation: validation, Log: log}
+}
+
+func (s *MessageService) CreateMessage(ctx context.Context,
+	req *messagiov1.CreateMessageRequest) (*messagiov1.Message, error) {
+	log := logger.ForRest(s.Log, "create message", ctx)
+
+	draft := draftFromPB(req)
+	if fe := s.validation.Validate(draft); len(fe) > 0 {
+		log.Warn("invalid request", logger.Err(fe))
+		return nil, newStatus(domain.CodeValidation, fe)
+	}
+
+	msg := draft.Message()
+	if err := s.uc.CreateMessage(ctx, msg); err != nil {
+		log.Error("failed to create message", logger.Err(err))
+		if _, ok := domain.ErrorCode(err); !ok {
+			// the cause is only logged, it can have internal details
+			err = domain.ErrNotCreated
+		}
+		return nil, toStatus(err)
+	}
+
+	log.Info("message is created", slog.Int("id", msg.ID))
+
+	return messageToPB(msg), nil
+}
+
+func (s *MessageService) GetMessage(ctx context.Context, req *messagiov1.GetMessageRequest) (*messagiov1.Message, error) {
+	log := logger.ForRest(s.Log, "get message", ctx)
+
+	msg, err := s.uc.GetMessage(ctx, int(req.GetId()))
+	if err != nil {
+		logError(log, "failed to get message", err)
+		return nil, toStatus(err)
+	}
+
+	return messageToPB(msg), nil
+}
+
+func (s *MessageService) ListMessages(ctx context.Context,
+	req *messagiov1.ListMessagesRequest) (*messagiov1.ListMessagesResponse, error) {
+	log := logger.ForRest(s.Log, "list messages", ctx)
+
+	filter, fe := listFilter(req)
+	if len(fe) > 0 {
+		log.Warn("invalid request", logger.Err(fe))
+		return nil, newStatus(domain.CodeBadRequest, fe)
+	}
+
+	page, err := s.uc.ListMessages(ctx, filter)
+	if err != nil {
+		logError(log, "failed to list messages", err)
+		return nil, toStatus(err)
+	}
+
+	log.Info("messages are listed", slog.Int("count", len(page.Messages)))
+
+	return pageToPB(page), nil
+}
+
+func (s *MessageService) GetStats(ctx context.Context, req *messagiov1.GetStatsRequest) (*messagiov1.Stats, error) {
+	log := logger.ForRest(s.Log, "get stats", ctx)
+
+	filter, fe := statsFilter(req)
+	if len(fe) > 0 {
+		log.Warn("invalid request", logger.Err(fe))
+		return nil, newStatus(domain.CodeBadRequest, fe)
+	}
+
+	stats, err := s.uc.GetStats(ctx, filter)
+	if err != nil {
+		logError(log, "failed to get stats", err)
+		return nil, toStatus(err)
+	}
+
+	return statsToPB(stats), nil
+}
+
+// WatchMessage sends the current status and then the status changes until a final status,
+// the client goes away or the server shuts down.
+func (s *MessageService) WatchMessage(req *messagiov1.WatchMessageRequest,
+	stream messagiov1.MessageService_WatchMessageServer) error {
+	ctx := stream.Context()
+	log := logger.ForRest(s.Log, "watch message", ctx)
+
+	if s.events == nil {
+		return status.Error(codes.Unimplemented, "message events are disabled")
+	}
+
+	id := int(req.GetId())
+	// Subscribe before getting the message, so a change between them is not missed.
+	events, cancel := s.events.Subscribe(id)
+	defer cancel()
+
+	msg, err := s.uc.GetMessage(ctx, id)
+	if err != nil {
+		logError(log, "failed to get message", err)
+		return toStatus(err)
+	}
+
+	current := message.Event{MessageID: msg.ID, Status: msg.Status, At: msg.CreatedAt}
+	if msg.ProcessedAt != nil {
+		current.At = *msg.ProcessedAt
+	}
+	if err := stream.Send(eventToPB(current)); err != nil || current.Status.IsFinal() {
+		return err
+	}
+
+	log.Info("watch is started")
+	defer log.Info("watch is finished")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return toStatus(ctx.Err())
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if err := stream.Send(eventToPB(ev)); err != nil {
+				return err
+			}
+			if ev.Status.IsFinal() {
+				return nil
+			}
+		}
+	}
+}
+
+// logError logs client errors as warnings and the others as errors.
+func logError(log *slog.Logger, msg string, err error) {
+	if _, ok := domain.ErrorCode(err); ok {
+		log.Warn(msg, logger.Err(err))
+		return
+	}
+	log.Error(msg, logger.Err(err))
+}
+
+var grpcCodes = map[domain.Code]codes.Code{
+	domain.CodeAlreadyExists:        codes.AlreadyExists,
+	domain.CodeNotCreated:           codes.FailedPrecondition,
+	domain.CodeNotFound:             codes.NotFound,
+	domain.CodeConflict:             codes.FailedPrecondition,
+	domain.CodeInvalidArgument:      codes.InvalidArgument,
+	domain.CodeUnauthenticated:      codes.Unauthenticated,
+	domain.CodeForbidden:            codes.PermissionDenied,
+	domain.CodeIdempotencyKeyReused: codes.FailedPrecondition,
+	domain.CodeRateLimited:          codes.ResourceExhausted,
+	domain.CodeBadRequest:           codes.InvalidArgument,
+	domain.CodeValidation:           codes.InvalidArgument,
+	domain.CodePayloadTooLarge:      codes.ResourceExhausted,
+	domain.CodeInternal:             codes.Internal,
+}
+
+// toStatus converts the error to the gRPC status with the domain code in the ErrorInfo reason,
+// the same code as in REST problems. Error texts can have internal details like wrapped causes,
+// so they are only logged and the message is the detail of the code.
+func toStatus(err error) error {
+	if code, ok := domain.ErrorCode(err); ok {
+		return newStatus(code, nil)
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, "the call is canceled")
+	}
+	return newStatus(domain.CodeInternal, nil)
+}
+
+// ErrorDomain is the ErrorInfo domain of the API errors.
+const ErrorDomain = "messagio"
+
+// newStatus returns the status of the code with the code detail as the message and the code
+// in the ErrorInfo reason. Invalid fields are in the BadRequest field violations,
+// like the fields of REST validation problems.
+func newStatus(code domain.Code, fields message.FieldErrors) error {
+	msg := code.Detail()
+	if msg == "" {
+		msg = "internal error"
+	}
+	st := status.New(grpcCodes[code], msg)
+
+	var (
+		info     = &errdetails.ErrorInfo{Reason: string(code), Domain: ErrorDomain}
+		detailed *status.Status
+		err      error
+	)
+	if len(fields) == 0 {
+		detailed, err = st.WithDetails(info)
+	} else {
+		detailed, err = st.WithDetails(info, badRequest(fields))
+	}
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func badRequest(fields message.FieldErrors) *errdetails.BadRequest {
+	res := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(fields))}
+	for i, fe := range fields {
+		res.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message}
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventSubscriber is an autogenerated mock type for the EventSubscriber type
+type EventSubscriber struct {
+	mock.Mock
+}
+
+// Subscribe provides a mock function with given fields: id
+func (_m *EventSubscriber) Subscribe(id int) (<-chan message.Event, func()) {
+	ret := _m.Called(id)
+
+	var r0 <-chan message.Event
+	var r1 func()
+	if rf, ok := ret.Get(0).(func(int) (<-chan message.Event, func())); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int) <-chan message.Event); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan message.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) func()); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
+// NewEventSubscriber creates a new instance of EventSubscriber. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventSubscriber(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventSubscriber {
+	mock := &EventSubscriber{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
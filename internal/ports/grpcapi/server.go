@@ -0,0 +1,79 @@
+package grpcapi
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
+	"messagio_assignment/api/messagiov1"
+	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/logger"
+)
+
+// methodScopes is the scope required by each method.
+var methodScopes = map[string]auth.Scope{
+	messagiov1.MessageService_CreateMessage_FullMethodName: auth.ScopeMessagesWrite,
+	messagiov1.MessageService_GetMessage_FullMethodName:    auth.ScopeMessagesRead,
+	messagiov1.MessageService_ListMessages_FullMethodName:  auth.ScopeMessagesRead,
+	messagiov1.MessageService_GetStats_FullMethodName:      auth.ScopeMessagesRead,
+	messagiov1.MessageService_WatchMessage_FullMethodName:  auth.ScopeMessagesRead,
+}
+
+// methodRoutes is the REST route of each method, its rate limit applies to the method.
+var methodRoutes = map[string]string{
+	messagiov1.MessageService_CreateMessage_FullMethodName: limiter.RouteCreateMsg,
+	messagiov1.MessageService_GetMessage_FullMethodName:    limiter.RouteGetMsg,
+	messagiov1.MessageService_ListMessages_FullMethodName:  limiter.RouteListMsg,
+	messagiov1.MessageService_GetStats_FullMethodName:      limiter.RouteGetStats,
+	messagiov1.MessageService_WatchMessage_FullMethodName:  limiter.RouteEvents,
+}
+
+// NewServer creates the gRPC server. Message validation is taken from the handlers config of the HTTP server
+// and rateLimitCfg is shared with it, so both APIs behave the same and count calls together.
+// Calls are anonymous if authenticator is nil. WatchMessage is unimplemented if events is nil.
+func NewServer(grpcCfg config.GRPCServer, httpCfg config.HTTPServer, msgUC MessageUsecase,
+	authenticator Authenticator, rateLimitCfg limiter.Config, events EventSubscriber,
+	log *slog.Logger) (*grpc.Server, error) {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	validation := httpCfg.Handlers.Message.Validation
+	denyPatterns, err := message.CompileDenyPatterns(validation.DenyPatterns)
+	if err != nil {
+		return nil, err
+	}
+	validationRules := message.ValidationRules{
+		MaxContentLength: validation.MaxContentLength,
+		RequireContent:   validation.RequireContent,
+		RequireUTF8:      validation.RequireUTF8,
+		DenyPatterns:     denyPatterns,
+	}
+
+	// The same order as the chi middlewares: request id, logging, panic recovery, auth and rate limit.
+	interceptors := []interceptor{
+		requestID(),
+		logCalls(log),
+		recoverer(log),
+		authenticate(authenticator, methodScopes, log),
+		rateLimit(rateLimitCfg, methodRoutes, log),
+	}
+
+	var opts []grpc.ServerOption
+	if grpcCfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(grpcCfg.MaxRecvMsgSize))
+	}
+	for _, i := range interceptors {
+		opts = append(opts, grpc.ChainUnaryInterceptor(unary(i)), grpc.ChainStreamInterceptor(stream(i)))
+	}
+
+	server := grpc.NewServer(opts...)
+	messagiov1.RegisterMessageServiceServer(server, NewMessageService(msgUC, events, validationRules, log))
+	if grpcCfg.Reflection {
+		reflection.Register(server)
+	}
+
+	return server, nil
+}
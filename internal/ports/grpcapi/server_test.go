@@ -0,0 +1,363 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"io"
+	"messagio_assignment/api/messagiov1"
+	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/ports/grpcapi/mocks"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	client messagiov1.MessageServiceClient
+	uc     *mocks.MessageUsecase
+	events *mocks.EventSubscriber
+}
+
+// newTestServer serves the API over an in-memory connection. Calls are anonymous if authenticator is nil.
+func newTestServer(t *testing.T, httpCfg config.HTTPServer, authenticator Authenticator) *testServer {
+	uc := mocks.NewMessageUsecase(t)
+	events := mocks.NewEventSubscriber(t)
+
+	rateLimitCfg, err := limiter.NewConfig(httpCfg.Handlers.Message.RateLimit, nil)
+	require.NoError(t, err)
+	server, err := NewServer(config.GRPCServer{}, httpCfg, uc, authenticator, rateLimitCfg, events, nil)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testServer{client: messagiov1.NewMessageServiceClient(conn), uc: uc, events: events}
+}
+
+// assertStatus checks the gRPC code and the domain code in the ErrorInfo reason.
+func assertStatus(t *testing.T, err error, code codes.Code, reason domain.Code) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status error: %v", err)
+	assert.Equal(t, code, st.Code(), st.Message())
+
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+	require.NotNil(t, info, "no error info")
+	assert.Equal(t, string(reason), info.GetReason())
+	assert.Equal(t, ErrorDomain, info.GetDomain())
+}
+
+func TestMessageService_CreateMessage(t *testing.T) {
+	var httpCfg config.HTTPServer
+	httpCfg.Handlers.Message.Validation.RequireContent = true
+
+	s := newTestServer(t, httpCfg, nil)
+	ctx := context.Background()
+
+	t.Run("created", func(t *testing.T) {
+		s.uc.On("CreateMessage", mock.Anything, mock.MatchedBy(func(msg *message.Message) bool {
+			return msg.Content == "hello" && msg.Labels["team"] == "crm" && msg.ExpiresAt != nil
+		})).Run(func(args mock.Arguments) {
+			msg := args.Get(1).(*message.Message)
+			msg.ID = 42
+			msg.Status = message.StatusPending
+		}).Return(nil).Once()
+
+		msg, err := s.client.CreateMessage(ctx, &messagiov1.CreateMessageRequest{
+			Content: "hello",
+			Labels:  map[string]string{"team": "crm"},
+			Ttl:     durationpb.New(time.Hour),
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, msg.GetId())
+		assert.Equal(t, messagiov1.Status_STATUS_PENDING, msg.GetStatus())
+		assert.NotNil(t, msg.GetExpiresAt())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := s.client.CreateMessage(ctx, &messagiov1.CreateMessageRequest{
+			Content: " ", Ttl: durationpb.New(-time.Hour),
+		})
+		assertStatus(t, err, codes.InvalidArgument, domain.CodeValidation)
+		assert.Equal(t, domain.CodeValidation.Detail(), status.Convert(err).Message())
+		assert.Equal(t, []*errdetails.BadRequest_FieldViolation{
+			{Field: "content", Description: "must not be empty"},
+			{Field: "ttl", Description: "must be positive"},
+		}, fieldViolations(t, err))
+	})
+
+	t.Run("internal error is not exposed", func(t *testing.T) {
+		s.uc.On("CreateMessage", mock.Anything, mock.Anything).Return(errors.New("db password is wrong")).Once()
+
+		_, err := s.client.CreateMessage(ctx, &messagiov1.CreateMessageRequest{Content: "hello"})
+		assertStatus(t, err, codes.FailedPrecondition, domain.CodeNotCreated)
+		assert.NotContains(t, err.Error(), "password")
+	})
+}
+
+// fieldViolations returns the BadRequest field violations of the status error.
+func fieldViolations(t *testing.T, err error) []*errdetails.BadRequest_FieldViolation {
+	t.Helper()
+
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			return br.GetFieldViolations()
+		}
+	}
+	return nil
+}
+
+func TestMessageService_GetMessage(t *testing.T) {
+	s := newTestServer(t, config.HTTPServer{}, nil)
+	ctx := context.Background()
+
+	createdAt := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	s.uc.On("GetMessage", mock.Anything, 42).
+		Return(&message.Message{ID: 42, Content: "hello", Status: message.StatusProcessed, CreatedAt: createdAt}, nil).
+		Once()
+	s.uc.On("GetMessage", mock.Anything, 43).
+		Return(nil, &message.ErrorWithID{ID: 43, Err: domain.ErrNotFound}).Once()
+	s.uc.On("GetMessage", mock.Anything, 44).Return(nil, errors.New("db error")).Once()
+
+	msg, err := s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 42})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.GetContent())
+	assert.Equal(t, messagiov1.Status_STATUS_PROCESSED, msg.GetStatus())
+	assert.Equal(t, createdAt, msg.GetCreatedAt().AsTime())
+	assert.Nil(t, msg.GetProcessedAt())
+
+	_, err = s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 43})
+	assertStatus(t, err, codes.NotFound, domain.CodeNotFound)
+	assert.Equal(t, domain.CodeNotFound.Detail(), status.Convert(err).Message(), "the error text is not exposed")
+
+	_, err = s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 44})
+	assertStatus(t, err, codes.Internal, domain.CodeInternal)
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+}
+
+func TestMessageService_ListMessages(t *testing.T) {
+	s := newTestServer(t, config.HTTPServer{}, nil)
+	ctx := context.Background()
+
+	processed := message.StatusProcessed
+	minID := 10
+	s.uc.On("ListMessages", mock.Anything, message.ListFilter{
+		Status: &processed, Labels: message.Labels{"team": "crm"}, MinID: &minID, AfterID: 11, Limit: 2,
+	}).Return(&message.Page{
+		Messages:    []*message.Message{{ID: 12}, {ID: 13}},
+		NextAfterID: 13,
+	}, nil).Once()
+	s.uc.On("ListMessages", mock.Anything, message.ListFilter{AfterID: 13}).
+		Return(&message.Page{Messages: []*message.Message{{ID: 14}}}, nil).Once()
+
+	first := int64(10)
+	resp, err := s.client.ListMessages(ctx, &messagiov1.ListMessagesRequest{
+		Status:    messagiov1.Status_STATUS_PROCESSED,
+		Labels:    map[string]string{"team": "crm"},
+		MinId:     &first,
+		PageSize:  2,
+		PageToken: domain.Cursor{AfterID: 11}.Encode(),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetMessages(), 2)
+	require.NotEmpty(t, resp.GetNextPageToken())
+
+	resp, err = s.client.ListMessages(ctx, &messagiov1.ListMessagesRequest{PageToken: resp.GetNextPageToken()})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetMessages(), 1)
+	assert.Empty(t, resp.GetNextPageToken())
+
+	_, err = s.client.ListMessages(ctx, &messagiov1.ListMessagesRequest{PageToken: "not a token", PageSize: -1})
+	assertStatus(t, err, codes.InvalidArgument, domain.CodeBadRequest)
+	assert.Equal(t, []*errdetails.BadRequest_FieldViolation{
+		{Field: "page_size", Description: "must not be negative"},
+		{Field: "page_token", Description: "invalid cursor"},
+	}, fieldViolations(t, err))
+}
+
+func TestMessageService_GetStats(t *testing.T) {
+	s := newTestServer(t, config.HTTPServer{}, nil)
+	ctx := context.Background()
+
+	start := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	s.uc.On("GetStats", mock.Anything, message.StatsFilter{Bucket: message.BucketHour, GroupByLabel: "team"}).
+		Return(&message.Stats{
+			All: 3, Processed: 2,
+			ByStatus: map[message.Status]int{message.StatusProcessed: 2, message.StatusPending: 1},
+			ByLabel:  []message.LabelStats{{Value: "crm", All: 3, Processed: 2}},
+			Series: []message.StatsBucket{
+				{Start: start, Created: 3, Processed: 2, Latency: &message.Latency{P50: time.Second}},
+			},
+		}, nil).Once()
+	s.uc.On("GetStats", mock.Anything, message.StatsFilter{GroupByLabel: "Team!"}).
+		Return(nil, &message.StatsError{Err: domain.ErrInvalidArgument}).Once()
+
+	stats, err := s.client.GetStats(ctx, &messagiov1.GetStatsRequest{
+		Bucket: messagiov1.Bucket_BUCKET_HOUR, GroupByLabel: "team",
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, stats.GetAll())
+	assert.Equal(t, map[string]int64{"processed": 2, "pending": 1}, stats.GetByStatus())
+	require.Len(t, stats.GetSeries(), 1)
+	assert.Equal(t, time.Second, stats.GetSeries()[0].GetLatency().GetP50().AsDuration())
+
+	_, err = s.client.GetStats(ctx, &messagiov1.GetStatsRequest{GroupByLabel: "Team!"})
+	assertStatus(t, err, codes.InvalidArgument, domain.CodeInvalidArgument)
+}
+
+func TestMessageService_WatchMessage(t *testing.T) {
+	s := newTestServer(t, config.HTTPServer{}, nil)
+	ctx := context.Background()
+
+	at := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	events := make(chan message.Event, 2)
+	events <- message.Event{MessageID: 1, Status: message.StatusProcessing, At: at.Add(time.Second)}
+	events <- message.Event{MessageID: 1, Status: message.StatusProcessed, At: at.Add(2 * time.Second)}
+
+	cancelled := make(chan struct{})
+	s.events.On("Subscribe", 1).Return((<-chan message.Event)(events), func() { close(cancelled) }).Once()
+	s.uc.On("GetMessage", mock.Anything, 1).
+		Return(&message.Message{ID: 1, Status: message.StatusQueued, CreatedAt: at}, nil).Once()
+
+	stream, err := s.client.WatchMessage(ctx, &messagiov1.WatchMessageRequest{Id: 1})
+	require.NoError(t, err)
+
+	var got []messagiov1.Status
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, ev.GetMessageId())
+		got = append(got, ev.GetStatus())
+	}
+
+	assert.Equal(t, []messagiov1.Status{
+		messagiov1.Status_STATUS_QUEUED, messagiov1.Status_STATUS_PROCESSING, messagiov1.Status_STATUS_PROCESSED,
+	}, got)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("subscription is not cancelled")
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	authenticator := mocks.NewAuthenticator(t)
+	reader := &auth.Principal{Subject: "apikey:1", Scopes: []auth.Scope{auth.ScopeMessagesRead}}
+	authenticator.On("Authenticate", mock.Anything, "msg_reader").Return(reader, nil)
+	authenticator.On("Authenticate", mock.Anything, "msg_unknown").
+		Return(nil, auth.ErrUnknownToken)
+
+	s := newTestServer(t, config.HTTPServer{}, authenticator)
+
+	_, err := s.client.GetMessage(context.Background(), &messagiov1.GetMessageRequest{Id: 1})
+	assertStatus(t, err, codes.Unauthenticated, domain.CodeUnauthenticated)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer msg_unknown")
+	_, err = s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 1})
+	assertStatus(t, err, codes.Unauthenticated, domain.CodeUnauthenticated)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "msg_reader")
+	_, err = s.client.CreateMessage(ctx, &messagiov1.CreateMessageRequest{Content: "hello"})
+	assertStatus(t, err, codes.PermissionDenied, domain.CodeForbidden)
+
+	s.uc.On("GetMessage", mock.MatchedBy(func(ctx context.Context) bool {
+		p, ok := auth.PrincipalFrom(ctx)
+		return ok && p.Subject == "apikey:1"
+	}), 1).Return(&message.Message{ID: 1}, nil).Once()
+
+	_, err = s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 1})
+	require.NoError(t, err)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	var httpCfg config.HTTPServer
+	httpCfg.Handlers.Message.RateLimit = config.RateLimit{
+		KeyHeader:   "X-Client-ID",
+		DefaultTier: "default",
+		Tiers: map[string]map[string]config.RateLimitRoute{
+			"default": {limiter.RouteGetMsg: {PerMinute: 1}},
+		},
+	}
+
+	s := newTestServer(t, httpCfg, nil)
+	s.uc.On("GetMessage", mock.Anything, 1).Return(&message.Message{ID: 1}, nil).Twice()
+
+	first := metadata.AppendToOutgoingContext(context.Background(), "x-client-id", "first")
+	second := metadata.AppendToOutgoingContext(context.Background(), "x-client-id", "second")
+
+	_, err := s.client.GetMessage(first, &messagiov1.GetMessageRequest{Id: 1})
+	require.NoError(t, err)
+
+	var header metadata.MD
+	_, err = s.client.GetMessage(first, &messagiov1.GetMessageRequest{Id: 1}, grpc.Header(&header))
+	assertStatus(t, err, codes.ResourceExhausted, domain.CodeRateLimited)
+	// until the sliding window rate drops below the limit
+	require.Len(t, header.Get(RetryAfterHeader), 1)
+	retryAfter, err := strconv.Atoi(header.Get(RetryAfterHeader)[0])
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, retryAfter, 30)
+	assert.LessOrEqual(t, retryAfter, 91)
+
+	// Other clients and methods are not limited.
+	_, err = s.client.GetMessage(second, &messagiov1.GetMessageRequest{Id: 1})
+	require.NoError(t, err)
+}
+
+func TestServer_RequestIDAndRecovery(t *testing.T) {
+	s := newTestServer(t, config.HTTPServer{}, nil)
+
+	s.uc.On("GetMessage", mock.Anything, 1).Run(func(args mock.Arguments) {
+		panic("bug")
+	}).Return(nil, nil).Once()
+	s.uc.On("GetMessage", mock.Anything, 2).Return(&message.Message{ID: 2}, nil).Once()
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDHeader, "req-1")
+	_, err := s.client.GetMessage(ctx, &messagiov1.GetMessageRequest{Id: 1}, grpc.Header(&header))
+	assertStatus(t, err, codes.Internal, domain.CodeInternal)
+	assert.Equal(t, []string{"req-1"}, header.Get(RequestIDHeader))
+
+	// The server is still serving after the panic, the request id is generated.
+	_, err = s.client.GetMessage(context.Background(), &messagiov1.GetMessageRequest{Id: 2}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, header.Get(RequestIDHeader), 1)
+	assert.Contains(t, header.Get(RequestIDHeader)[0], requestIDPrefix)
+}
@@ -2,10 +2,26 @@ package dto
 
 import (
 	"encoding/json"
+	"fmt"
+	"messagio_assignment/internal/domain/message"
 )
 
 type MessageValue struct {
 	ID int `json:"id"`
+	// Status is reported by the processor, processed if it is empty.
+	Status string `json:"status,omitempty"`
+}
+
+// DomainStatus returns the reported status. The processor can report only its own statuses.
+func (v *MessageValue) DomainStatus() (message.Status, error) {
+	switch status := message.Status(v.Status); status {
+	case "":
+		return message.StatusProcessed, nil
+	case message.StatusProcessing, message.StatusProcessed, message.StatusFailed:
+		return status, nil
+	default:
+		return "", fmt.Errorf("status %q can't be reported by the processor", v.Status)
+	}
 }
 
 func MessageValueFromBytes(data []byte) (*MessageValue, error) {
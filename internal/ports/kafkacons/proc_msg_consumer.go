@@ -12,7 +12,7 @@ import (
 )
 
 type MessagesUsecase interface {
-	UpdateProcessedMessage(ctx context.Context, msg *message.Message) error
+	UpdateMessageStatus(ctx context.Context, id int, status message.Status) (*message.Message, error)
 }
 
 type ProcessedMsgConsumer struct {
@@ -121,16 +121,21 @@ func (c *ProcessedMsgConsumer) HandleMessage(ctx context.Context, log *slog.Logg
 		return err
 	}
 
-	msg := &message.Message{
-		ID:        mv.ID,
-		Processed: true,
+	status, err := mv.DomainStatus()
+	if err != nil {
+		log.Warn("message value status", logger.Err(err))
+		return err
 	}
 
-	err = c.msgUC.UpdateProcessedMessage(ctx, msg)
+	msg, err := c.msgUC.UpdateMessageStatus(ctx, mv.ID, status)
 	if err != nil {
-		log.Error("update processed message", logger.Err(err))
+		log.Error("update message status", logger.Err(err),
+			slog.Int("id", mv.ID), slog.String("status", status.String()))
 		return err
 	}
+	if msg.Status == message.StatusExpired {
+		log.Info("message expired before processing", slog.Int("id", mv.ID), slog.String("status", status.String()))
+	}
 
 	return nil
 }
@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/logger"
+	"messagio_assignment/internal/ports/rest/dto"
+	"net/http"
+	"strconv"
+)
+
+//go:generate mockery --name APIKeyUsecase
+type APIKeyUsecase interface {
+	CreateKey(ctx context.Context, name string, scopes []auth.Scope, callbackURL string) (*apikey.Key, string, error)
+	ListKeys(ctx context.Context) ([]*apikey.Key, error)
+	RevokeKey(ctx context.Context, id int) error
+}
+
+// APIKeyHandler manages API keys, all its endpoints require the admin scope.
+type APIKeyHandler struct {
+	uc   APIKeyUsecase
+	auth *Auth
+
+	responder
+}
+
+func NewAPIKeyHandler(uc APIKeyUsecase, auth *Auth, log *slog.Logger) *APIKeyHandler {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	log = log.With(
+		slog.String("component", "ports/rest/apikey_handler"),
+	)
+
+	return &APIKeyHandler{uc: uc, auth: auth, responder: responder{Log: log}}
+}
+
+func (h *APIKeyHandler) SetupRoutes(r chi.Router) {
+	r.Route("/admin/api-keys", func(r chi.Router) {
+		r.Use(h.auth.Require(auth.ScopeAdmin))
+		r.Post("/", h.CreateKey())
+		r.Get("/", h.ListKeys())
+		r.Delete("/{id}", h.RevokeKey())
+	})
+}
+
+// CreateKey godoc
+//
+//	@Summary		Create an API key
+//	@Description	create an API key with scopes, the key is returned only once
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Param			key	body		dto.CreateAPIKeyReq	true	"Create API key"
+//	@Success		201	{object}	dto.CreateAPIKeyResp
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		422	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//	@Router			/admin/api-keys [post]
+func (h *APIKeyHandler) CreateKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "create api key", r.Context())
+
+		var keyReq dto.CreateAPIKeyReq
+		if err := json.NewDecoder(r.Body).Decode(&keyReq); err != nil {
+			log.Warn("failed to decode request body", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		key, secret, err := h.uc.CreateKey(r.Context(), keyReq.Name, keyReq.DomainScopes(), keyReq.CallbackURL)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidArgument):
+				log.Warn("invalid api key", logger.Err(err))
+				h.error(w, r, http.StatusUnprocessableEntity, err)
+			default:
+				log.Error("failed to create api key", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("api key is created", slog.Int("id", key.ID), slog.String("prefix", key.Prefix))
+
+		resp := dto.CreateAPIKeyResp{Key: secret}
+		resp.FromDomain(key)
+
+		h.respond(w, http.StatusCreated, resp)
+	}
+}
+
+// ListKeys godoc
+//
+//	@Summary		List API keys
+//	@Description	list API keys including revoked ones, secrets are never returned
+//	@Tags			admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Success		200	{object}	dto.ListAPIKeysResp
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//	@Router			/admin/api-keys [get]
+func (h *APIKeyHandler) ListKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "list api keys", r.Context())
+
+		keys, err := h.uc.ListKeys(r.Context())
+		if err != nil {
+			log.Error("failed to list api keys", logger.Err(err))
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := dto.ListAPIKeysResp{Items: make([]dto.APIKeyResp, len(keys))}
+		for i, key := range keys {
+			resp.Items[i].FromDomain(key)
+		}
+
+		h.respond(w, http.StatusOK, resp)
+	}
+}
+
+// RevokeKey godoc
+//
+//	@Summary		Revoke an API key
+//	@Description	revoke an API key, requests with it are rejected right away
+//	@Tags			admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"API key ID"
+//	@Success		204
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem	"Key is not found or is already revoked"
+//	@Failure		500	{object}	dto.Problem
+//	@Router			/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "revoke api key", r.Context())
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Warn("failed to parse api key id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = h.uc.RevokeKey(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("api key is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
+			default:
+				log.Error("failed to revoke api key", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("api key is revoked", slog.Int("id", id))
+
+		h.respond(w, http.StatusNoContent, nil)
+	}
+}
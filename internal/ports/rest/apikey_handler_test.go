@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/ports/rest/dto"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newAPIKeyTestServer(t *testing.T) (*httpexpect.Expect, *mocks.APIKeyUsecase, *mocks.Authenticator) {
+	uc := mocks.NewAPIKeyUsecase(t)
+	authenticator := mocks.NewAuthenticator(t)
+
+	router := chi.NewRouter()
+	NewAPIKeyHandler(uc, NewAuth(authenticator, nil), nil).SetupRoutes(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return httpexpect.Default(t, server.URL), uc, authenticator
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	createdAt := time.Date(2024, 8, 15, 12, 0, 0, 0, time.UTC)
+	admin := &auth.Principal{Subject: "apikey:1", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	writer := &auth.Principal{Subject: "apikey:2", Scopes: []auth.Scope{auth.ScopeMessagesWrite}}
+
+	t.Run("create key", func(t *testing.T) {
+		e, uc, authenticator := newAPIKeyTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+		uc.On("CreateKey", mock.Anything, "crm", []auth.Scope{auth.ScopeMessagesWrite}, "https://crm.example.com/hooks").
+			Return(&apikey.Key{
+				ID:          7,
+				Name:        "crm",
+				Prefix:      "msg_abcdef",
+				Scopes:      []auth.Scope{auth.ScopeMessagesWrite},
+				CallbackURL: "https://crm.example.com/hooks",
+				CreatedAt:   createdAt,
+			}, "msg_secret", nil).Once()
+
+		var gotResp dto.CreateAPIKeyResp
+		e.POST("/admin/api-keys").
+			WithHeader(APIKeyHeader, "msg_admin").
+			WithJSON(dto.CreateAPIKeyReq{
+				Name:        "crm",
+				Scopes:      []string{"messages:write"},
+				CallbackURL: "https://crm.example.com/hooks",
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			Decode(&gotResp)
+
+		assert.Equal(t, dto.CreateAPIKeyResp{
+			APIKeyResp: dto.APIKeyResp{
+				ID:          7,
+				Name:        "crm",
+				Prefix:      "msg_abcdef",
+				Scopes:      []string{"messages:write"},
+				CallbackURL: "https://crm.example.com/hooks",
+				CreatedAt:   createdAt,
+			},
+			Key: "msg_secret",
+		}, gotResp)
+	})
+
+	t.Run("create invalid key", func(t *testing.T) {
+		e, uc, authenticator := newAPIKeyTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+		uc.On("CreateKey", mock.Anything, "crm", []auth.Scope{"messages:everything"}, "").
+			Return(nil, "", &apikey.Error{Err: domain.ErrInvalidArgument}).Once()
+
+		obj := e.POST("/admin/api-keys").
+			WithHeader(APIKeyHeader, "msg_admin").
+			WithJSON(dto.CreateAPIKeyReq{Name: "crm", Scopes: []string{"messages:everything"}}).
+			Expect().
+			Status(http.StatusUnprocessableEntity).
+			JSON(contentOpts(true)).Object()
+
+		obj.Value("code").IsEqual(domain.CodeInvalidArgument)
+	})
+
+	t.Run("list keys", func(t *testing.T) {
+		e, uc, authenticator := newAPIKeyTestServer(t)
+
+		revokedAt := createdAt.Add(time.Hour)
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+		uc.On("ListKeys", mock.Anything).Return([]*apikey.Key{
+			{ID: 1, Name: "admin", Prefix: "msg_aaaaaa", Scopes: []auth.Scope{auth.ScopeAdmin}, CreatedAt: createdAt},
+			{ID: 2, Name: "old", Prefix: "msg_bbbbbb", Scopes: []auth.Scope{auth.ScopeMessagesRead},
+				CreatedAt: createdAt, RevokedAt: &revokedAt},
+		}, nil).Once()
+
+		obj := e.GET("/admin/api-keys").
+			WithHeader("Authorization", "Bearer msg_admin").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		items := obj.Value("items").Array()
+		items.Length().IsEqual(2)
+		items.Value(0).Object().NotContainsKey("key").NotContainsKey("revoked_at")
+		items.Value(1).Object().HasValue("prefix", "msg_bbbbbb").ContainsKey("revoked_at")
+	})
+
+	t.Run("revoke key", func(t *testing.T) {
+		e, uc, authenticator := newAPIKeyTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Twice()
+		uc.On("RevokeKey", mock.Anything, 2).Return(nil).Once()
+		uc.On("RevokeKey", mock.Anything, 3).Return(&apikey.Error{ID: 3, Err: domain.ErrNotFound}).Once()
+
+		e.DELETE("/admin/api-keys/2").
+			WithHeader(APIKeyHeader, "msg_admin").
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.DELETE("/admin/api-keys/3").
+			WithHeader(APIKeyHeader, "msg_admin").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("admin scope is required", func(t *testing.T) {
+		e, _, authenticator := newAPIKeyTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_writer").Return(writer, nil).Once()
+
+		e.GET("/admin/api-keys").
+			WithHeader(APIKeyHeader, "msg_writer").
+			Expect().
+			Status(http.StatusForbidden)
+
+		e.GET("/admin/api-keys").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		e, uc, authenticator := newAPIKeyTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+		uc.On("ListKeys", mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		e.GET("/admin/api-keys").
+			WithHeader(APIKeyHeader, "msg_admin").
+			Expect().
+			Status(http.StatusInternalServerError)
+	})
+}
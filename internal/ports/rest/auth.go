@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/logger"
+	"net/http"
+	"strings"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+//go:generate mockery --name Authenticator
+type Authenticator interface {
+	// Authenticate returns the principal of the token or domain.ErrUnauthenticated.
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// Authenticators tries authenticators in order until one of them knows the token kind.
+type Authenticators []Authenticator
+
+func (as Authenticators) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	for _, a := range as {
+		principal, err := a.Authenticate(ctx, token)
+		if errors.Is(err, auth.ErrUnknownToken) {
+			continue
+		}
+		return principal, err
+	}
+
+	return nil, auth.ErrUnknownToken
+}
+
+// Auth authenticates requests with a Bearer token or the X-API-Key header and checks scopes.
+// Handlers with nil Auth are anonymous.
+type Auth struct {
+	authenticator Authenticator
+
+	responder
+}
+
+func NewAuth(authenticator Authenticator, log *slog.Logger) *Auth {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	log = log.With(
+		slog.String("component", "ports/rest/auth"),
+	)
+
+	return &Auth{authenticator: authenticator, responder: responder{Log: log}}
+}
+
+// Require authenticates the request and checks that the principal has the scope.
+// The principal is put into the request context.
+func (a *Auth) Require(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := logger.ForRest(a.Log, "auth", r.Context())
+
+			principal, ok := auth.PrincipalFrom(r.Context())
+			if !ok {
+				token, err := requestToken(r)
+				if err != nil {
+					a.unauthenticated(w, r, err)
+					return
+				}
+
+				principal, err = a.authenticator.Authenticate(r.Context(), token)
+				if err != nil {
+					if !errors.Is(err, domain.ErrUnauthenticated) {
+						log.Error("failed to authenticate", logger.Err(err))
+						a.error(w, r, http.StatusInternalServerError, err)
+						return
+					}
+
+					log.Warn("failed to authenticate", logger.Err(err))
+					a.unauthenticated(w, r, err)
+					return
+				}
+
+				r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+			}
+
+			if !principal.HasScope(scope) {
+				log.Warn("scope is not allowed",
+					slog.String("principal", principal.Subject), slog.String("scope", scope.String()))
+				a.error(w, r, http.StatusForbidden, fmt.Errorf("%w: %s scope is required", domain.ErrForbidden, scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (a *Auth) unauthenticated(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	a.error(w, r, http.StatusUnauthorized, err)
+}
+
+// requestToken returns the Bearer token or the X-API-Key header.
+func requestToken(r *http.Request) (string, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", fmt.Errorf("%w: no credentials", domain.ErrUnauthenticated)
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("%w: Authorization header must be Bearer token", domain.ErrUnauthenticated)
+	}
+
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuth_Require(t *testing.T) {
+	reader := &auth.Principal{Subject: "apikey:1", Scopes: []auth.Scope{auth.ScopeMessagesRead}}
+	admin := &auth.Principal{Subject: "apikey:2", Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+	tcases := []struct {
+		Name           string
+		Method         string
+		Headers        map[string]string
+		ExpectedStatus int
+		ExpectedCode   domain.Code
+		MockInit       func(a *mocks.Authenticator, uc *mocks.MessageUsecase)
+	}{
+		{
+			Name:           "no credentials",
+			Method:         http.MethodGet,
+			ExpectedStatus: http.StatusUnauthorized,
+			ExpectedCode:   domain.CodeUnauthenticated,
+			MockInit:       func(_ *mocks.Authenticator, _ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:           "not bearer",
+			Method:         http.MethodGet,
+			Headers:        map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			ExpectedStatus: http.StatusUnauthorized,
+			ExpectedCode:   domain.CodeUnauthenticated,
+			MockInit:       func(_ *mocks.Authenticator, _ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:           "unknown key",
+			Method:         http.MethodGet,
+			Headers:        map[string]string{APIKeyHeader: "msg_unknown"},
+			ExpectedStatus: http.StatusUnauthorized,
+			ExpectedCode:   domain.CodeUnauthenticated,
+			MockInit: func(a *mocks.Authenticator, _ *mocks.MessageUsecase) {
+				a.On("Authenticate", mock.Anything, "msg_unknown").
+					Return(nil, domain.ErrUnauthenticated).Once()
+			},
+		},
+		{
+			Name:           "bearer with scope",
+			Method:         http.MethodGet,
+			Headers:        map[string]string{"Authorization": "Bearer msg_reader"},
+			ExpectedStatus: http.StatusOK,
+			MockInit: func(a *mocks.Authenticator, uc *mocks.MessageUsecase) {
+				a.On("Authenticate", mock.Anything, "msg_reader").Return(reader, nil).Once()
+				uc.On("GetMessage", mock.Anything, 1).Return(&message.Message{ID: 1}, nil).Once()
+			},
+		},
+		{
+			Name:           "missing scope",
+			Method:         http.MethodDelete,
+			Headers:        map[string]string{APIKeyHeader: "msg_reader"},
+			ExpectedStatus: http.StatusForbidden,
+			ExpectedCode:   domain.CodeForbidden,
+			MockInit: func(a *mocks.Authenticator, _ *mocks.MessageUsecase) {
+				a.On("Authenticate", mock.Anything, "msg_reader").Return(reader, nil).Once()
+			},
+		},
+		{
+			Name:           "admin has all scopes",
+			Method:         http.MethodDelete,
+			Headers:        map[string]string{APIKeyHeader: "msg_admin"},
+			ExpectedStatus: http.StatusNoContent,
+			MockInit: func(a *mocks.Authenticator, uc *mocks.MessageUsecase) {
+				a.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+				uc.On("DeleteMessage", mock.Anything, 1).Return(nil).Once()
+			},
+		},
+		{
+			Name:           "authenticator error",
+			Method:         http.MethodGet,
+			Headers:        map[string]string{APIKeyHeader: "msg_reader"},
+			ExpectedStatus: http.StatusInternalServerError,
+			ExpectedCode:   domain.CodeInternal,
+			MockInit: func(a *mocks.Authenticator, _ *mocks.MessageUsecase) {
+				a.On("Authenticate", mock.Anything, "msg_reader").Return(nil, errors.New("db error")).Once()
+			},
+		},
+	}
+
+	authenticator := mocks.NewAuthenticator(t)
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.Auth = NewAuth(authenticator, nil)
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.MockInit(authenticator, uc)
+
+			resp := e.Request(tc.Method, "/messages/1").
+				WithHeaders(tc.Headers).
+				Expect().
+				Status(tc.ExpectedStatus)
+
+			if tc.ExpectedCode == "" {
+				return
+			}
+
+			if tc.ExpectedStatus == http.StatusUnauthorized {
+				resp.Header("WWW-Authenticate").IsEqual("Bearer")
+			}
+
+			obj := resp.JSON(contentOpts(true)).Object()
+			assertProblem(obj, tc.ExpectedStatus)
+			obj.Value("code").IsEqual(tc.ExpectedCode)
+		})
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	apiKeys := mocks.NewAuthenticator(t)
+	jwts := mocks.NewAuthenticator(t)
+	as := Authenticators{apiKeys, jwts}
+	principal := &auth.Principal{Subject: "jwt:user-1"}
+
+	apiKeys.On("Authenticate", mock.Anything, "a.b.c").Return(nil, auth.ErrUnknownToken).Once()
+	jwts.On("Authenticate", mock.Anything, "a.b.c").Return(principal, nil).Once()
+	got, err := as.Authenticate(context.Background(), "a.b.c")
+	require.NoError(t, err)
+	assert.Same(t, principal, got)
+
+	rejected := fmt.Errorf("%w: revoked", domain.ErrUnauthenticated)
+	apiKeys.On("Authenticate", mock.Anything, "msg_revoked").Return(nil, rejected).Once()
+	_, err = as.Authenticate(context.Background(), "msg_revoked")
+	assert.ErrorIs(t, err, rejected, "the first authenticator that knows the token decides")
+
+	apiKeys.On("Authenticate", mock.Anything, "other").Return(nil, auth.ErrUnknownToken).Once()
+	jwts.On("Authenticate", mock.Anything, "other").Return(nil, auth.ErrUnknownToken).Once()
+	_, err = as.Authenticate(context.Background(), "other")
+	assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+}
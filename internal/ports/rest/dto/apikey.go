@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+	"time"
+)
+
+type CreateAPIKeyReq struct {
+	Name   string   `json:"name" example:"crm"`
+	Scopes []string `json:"scopes" example:"messages:read,messages:write"`
+	// CallbackURL is the default webhook URL of messages created with the key.
+	CallbackURL string `json:"callback_url,omitempty" example:"https://crm.example.com/hooks/messages"`
+}
+
+func (r *CreateAPIKeyReq) DomainScopes() []auth.Scope {
+	scopes := make([]auth.Scope, len(r.Scopes))
+	for i, s := range r.Scopes {
+		scopes[i] = auth.Scope(s)
+	}
+	return scopes
+}
+
+type APIKeyResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix" example:"msg_Xy3kQ9"`
+	Scopes      []string   `json:"scopes"`
+	CallbackURL string     `json:"callback_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (r *APIKeyResp) FromDomain(key *apikey.Key) {
+	r.ID = key.ID
+	r.Name = key.Name
+	r.Prefix = key.Prefix
+	r.Scopes = make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		r.Scopes[i] = s.String()
+	}
+	r.CallbackURL = key.CallbackURL
+	r.CreatedAt = key.CreatedAt
+	r.RevokedAt = key.RevokedAt
+}
+
+// CreateAPIKeyResp has the secret of the key, it is shown only once.
+type CreateAPIKeyResp struct {
+	APIKeyResp
+	Key string `json:"key"`
+}
+
+type ListAPIKeysResp struct {
+	Items []APIKeyResp `json:"items"`
+}
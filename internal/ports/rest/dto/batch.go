@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"messagio_assignment/internal/domain"
+)
+
+var ErrNotObject = fmt.Errorf("%w: message must be a JSON object", domain.ErrInvalidArgument)
+
+// CreateMessagesReq is decoded item by item, so one malformed message doesn't fail the whole batch.
+type CreateMessagesReq []json.RawMessage
+
+// DecodeItem decodes the i-th message of the batch into msgReq, the request DTO of the API version.
+// Disallowed unknown fields are returned as message.FieldErrors, other decoding errors are domain.ErrInvalidArgument.
+func (r CreateMessagesReq) DecodeItem(i int, msgReq any, disallowUnknownFields bool) error {
+	raw := bytes.TrimSpace(r[i])
+	if len(raw) == 0 || raw[0] != '{' {
+		return ErrNotObject
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(msgReq); err != nil {
+		if fe, ok := UnknownFieldError(err); ok {
+			return fe
+		}
+		return fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	return nil
+}
+
+type CreateMessagesResp struct {
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Items   []CreateMessagesItemResp `json:"items"`
+}
+
+// CreateMessagesItemResp has the id of the created message or the error, in the order of the request.
+// Code and Error are the code and the detail of the problem of a single message with the same error.
+// Fields lists invalid fields if the message failed validation.
+type CreateMessagesItemResp struct {
+	ID     int          `json:"id,omitempty"`
+	Code   string       `json:"code,omitempty" example:"validation_failed"`
+	Error  string       `json:"error,omitempty" example:"The request has invalid fields."`
+	Fields []FieldError `json:"fields,omitempty"`
+}
@@ -1,5 +1,19 @@
 package dto
 
-type HTTPError struct {
-	Error string `json:"error"`
+// Problem is the error response in the RFC 7807 problem+json format.
+// Code is the stable error code, Type is the code as a URI. Title and Detail are fixed for the code.
+type Problem struct {
+	Type      string `json:"type" example:"urn:messagio:problem:not_found"`
+	Title     string `json:"title" example:"Not found"`
+	Status    int    `json:"status" example:"404"`
+	Detail    string `json:"detail,omitempty" example:"The resource is not found."`
+	Instance  string `json:"instance,omitempty" example:"/messages/42"`
+	Code      string `json:"code" example:"not_found"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ValidationProblem is the response for a request with invalid fields, the only problem with request details.
+type ValidationProblem struct {
+	Problem
+	Fields []FieldError `json:"fields"`
 }
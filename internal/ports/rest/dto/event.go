@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"messagio_assignment/internal/domain/message"
+	"time"
+)
+
+// MessageEventResp is the data of the SSE event "status".
+type MessageEventResp struct {
+	ID     int       `json:"id"`
+	Status string    `json:"status" enums:"pending,queued,processing,processed,failed,cancelled,expired"`
+	At     time.Time `json:"at"`
+}
+
+func (r *MessageEventResp) FromDomain(ev message.Event) {
+	r.ID = ev.MessageID
+	r.Status = ev.Status.String()
+	r.At = ev.At
+}
@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ListMessagesReq struct {
+	Status      *message.Status
+	Labels      message.Labels
+	MinID       *int
+	MaxID       *int
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Cursor      domain.Cursor
+	Limit       int
+}
+
+// FromQuery parses query parameters. Times are in RFC 3339 format.
+func (r *ListMessagesReq) FromQuery(q url.Values) error {
+	var err error
+
+	if r.Status, err = parseOptional(q, "status", parseStatus); err != nil {
+		return err
+	}
+	if r.Labels, err = parseLabels(q); err != nil {
+		return err
+	}
+	if r.MinID, err = parseOptional(q, "min_id", strconv.Atoi); err != nil {
+		return err
+	}
+	if r.MaxID, err = parseOptional(q, "max_id", strconv.Atoi); err != nil {
+		return err
+	}
+	if r.CreatedFrom, err = parseOptional(q, "created_from", parseTime); err != nil {
+		return err
+	}
+	if r.CreatedTo, err = parseOptional(q, "created_to", parseTime); err != nil {
+		return err
+	}
+
+	limit, err := parseOptional(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+	if limit != nil {
+		r.Limit = *limit
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		r.Cursor, err = domain.DecodeCursor(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ListMessagesReq) ToDomain() message.ListFilter {
+	return message.ListFilter{
+		Status:      r.Status,
+		Labels:      r.Labels,
+		MinID:       r.MinID,
+		MaxID:       r.MaxID,
+		CreatedFrom: r.CreatedFrom,
+		CreatedTo:   r.CreatedTo,
+		AfterID:     r.Cursor.AfterID,
+		Limit:       r.Limit,
+	}
+}
+
+type ListMessagesResp struct {
+	Messages   []GetMessageResp `json:"messages"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func (r *ListMessagesResp) FromDomain(page *message.Page) {
+	r.Messages = make([]GetMessageResp, len(page.Messages))
+	for i, msg := range page.Messages {
+		r.Messages[i].FromDomain(msg)
+	}
+
+	r.NextCursor = ""
+	if page.NextAfterID != 0 {
+		r.NextCursor = domain.Cursor{AfterID: page.NextAfterID}.Encode()
+	}
+}
+
+func parseStatus(s string) (message.Status, error) {
+	status := message.Status(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("unknown status %q", s)
+	}
+	return status, nil
+}
+
+// parseLabels parses repeated label=key:value query parameters.
+func parseLabels(q url.Values) (message.Labels, error) {
+	values := q["label"]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(message.Labels, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("query parameter %q: %q is not key:value", "label", v)
+		}
+		labels[key] = value
+	}
+
+	if err := labels.Validate(); err != nil {
+		return nil, fmt.Errorf("query parameter %q: %w", "label", err)
+	}
+
+	return labels, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+// parseOptional returns nil if the query parameter is empty.
+func parseOptional[T any](q url.Values, key string, parse func(string) (T, error)) (*T, error) {
+	s := q.Get(key)
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("query parameter %q: %w", key, err)
+	}
+
+	return &v, nil
+}
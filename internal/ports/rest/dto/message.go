@@ -1,27 +1,117 @@
 package dto
 
-import "messagio_assignment/internal/domain/message"
+import (
+	"fmt"
+	"messagio_assignment/internal/domain/message"
+	"net/url"
+	"time"
+)
 
 type CreateMessageReq struct {
-	Content   string `json:"content"`
-	Processed bool   `json:"processed,omitempty"`
+	Content string            `json:"content"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// SendAt delays producing the message until the time.
+	SendAt *time.Time `json:"send_at,omitempty"`
+	// ExpiresAt or TTL drops the message if it is not produced in time.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty" example:"1h30m"`
+	// CallbackURL receives a webhook with the processing result. The URL of the API key is used if it is empty.
+	CallbackURL string `json:"callback_url,omitempty" example:"https://crm.example.com/hooks/messages"`
 }
 
-func (r *CreateMessageReq) ToDomain() *message.Message {
-	return &message.Message{
-		Content:   r.Content,
-		Processed: r.Processed,
+// Draft converts the request to the domain draft, which is validated by message.ValidationRules.
+// It returns the errors of the fields that can't be represented in the draft.
+func (r *CreateMessageReq) Draft() (*message.Draft, message.FieldErrors) {
+	draft := &message.Draft{
+		Content:     r.Content,
+		Labels:      r.Labels,
+		SendAt:      r.SendAt,
+		ExpiresAt:   r.ExpiresAt,
+		CallbackURL: r.CallbackURL,
+	}
+
+	var fe message.FieldErrors
+	if r.TTL != "" {
+		ttl, err := time.ParseDuration(r.TTL)
+		if err != nil {
+			fe.Add("ttl", err.Error())
+		} else {
+			draft.TTL = &ttl
+		}
 	}
+
+	return draft, fe
+}
+
+// ToDomain converts the validated request, TTL is counted from now.
+func (r *CreateMessageReq) ToDomain() *message.Message {
+	draft, _ := r.Draft()
+	return draft.Message()
 }
 
 type CreateMessageResp struct {
-	ID        int    `json:"id"`
-	Content   string `json:"content"`
-	Processed bool   `json:"processed"`
+	ID          int               `json:"id"`
+	Content     string            `json:"content"`
+	Status      string            `json:"status" enums:"pending,queued,processing,processed,failed,cancelled,expired"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	SendAt      *time.Time        `json:"send_at,omitempty"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	CallbackURL string            `json:"callback_url,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ProcessedAt *time.Time        `json:"processed_at,omitempty"`
 }
 
 func (r *CreateMessageResp) FromDomain(msg *message.Message) {
 	r.ID = msg.ID
 	r.Content = msg.Content
-	r.Processed = msg.Processed
+	r.Status = msg.Status.String()
+	r.Labels = msg.Labels
+	r.SendAt = msg.SendAt
+	r.ExpiresAt = msg.ExpiresAt
+	r.CallbackURL = msg.CallbackURL
+	r.CreatedAt = msg.CreatedAt
+	r.ProcessedAt = msg.ProcessedAt
+}
+
+type GetMessageReq struct {
+	// Wait is how long to wait for a processing result, zero means no waiting.
+	Wait time.Duration
+}
+
+func (r *GetMessageReq) FromQuery(q url.Values) error {
+	wait, err := parseOptional(q, "wait", time.ParseDuration)
+	if err != nil {
+		return err
+	}
+	if wait != nil {
+		if *wait < 0 {
+			return fmt.Errorf("query parameter %q: must not be negative", "wait")
+		}
+		r.Wait = *wait
+	}
+	return nil
+}
+
+type GetMessageResp struct {
+	ID          int               `json:"id"`
+	Content     string            `json:"content"`
+	Status      string            `json:"status" enums:"pending,queued,processing,processed,failed,cancelled,expired"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	SendAt      *time.Time        `json:"send_at,omitempty"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	CallbackURL string            `json:"callback_url,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ProcessedAt *time.Time        `json:"processed_at,omitempty"`
+}
+
+func (r *GetMessageResp) FromDomain(msg *message.Message) {
+	r.ID = msg.ID
+	r.Content = msg.Content
+	r.Status = msg.Status.String()
+	r.Labels = msg.Labels
+	r.SendAt = msg.SendAt
+	r.ExpiresAt = msg.ExpiresAt
+	r.CallbackURL = msg.CallbackURL
+	r.CreatedAt = msg.CreatedAt
+	r.ProcessedAt = msg.ProcessedAt
 }
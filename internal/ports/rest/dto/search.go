@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"net/url"
+	"strconv"
+)
+
+type SearchMessagesReq struct {
+	Query  string
+	Cursor domain.Cursor
+	Limit  int
+}
+
+func (r *SearchMessagesReq) FromQuery(q url.Values) error {
+	r.Query = q.Get("q")
+
+	limit, err := parseOptional(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+	if limit != nil {
+		r.Limit = *limit
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		r.Cursor, err = domain.DecodeCursor(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SearchMessagesReq) ToDomain() message.SearchQuery {
+	return message.SearchQuery{
+		Query:  r.Query,
+		Offset: r.Cursor.Offset,
+		Limit:  r.Limit,
+	}
+}
+
+type SearchMessagesResp struct {
+	Hits       []SearchHitResp `json:"hits"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (r *SearchMessagesResp) FromDomain(page *message.SearchPage) {
+	r.Hits = make([]SearchHitResp, len(page.Hits))
+	for i, hit := range page.Hits {
+		r.Hits[i].FromDomain(hit)
+	}
+
+	r.NextCursor = ""
+	if page.NextOffset != 0 {
+		r.NextCursor = domain.Cursor{Offset: page.NextOffset}.Encode()
+	}
+}
+
+type SearchHitResp struct {
+	Message GetMessageResp `json:"message"`
+	Rank    float64        `json:"rank"`
+	// Highlight has the matched fragments of the content with the matched words in <mark> tags.
+	// The content is not escaped.
+	Highlight string `json:"highlight"`
+}
+
+func (r *SearchHitResp) FromDomain(hit *message.SearchHit) {
+	r.Message.FromDomain(hit.Message)
+	r.Rank = hit.Rank
+	r.Highlight = hit.Highlight
+}
@@ -1,13 +1,169 @@
 package dto
 
-import "messagio_assignment/internal/domain/message"
+import (
+	"fmt"
+	"messagio_assignment/internal/domain/message"
+	"net/url"
+	"time"
+)
+
+type GetStatsReq struct {
+	From         *time.Time
+	To           *time.Time
+	Bucket       message.Bucket
+	Labels       message.Labels
+	GroupByLabel string
+}
+
+// FromQuery parses query parameters. Times are in RFC 3339 format.
+func (r *GetStatsReq) FromQuery(q url.Values) error {
+	var err error
+
+	if r.From, err = parseOptional(q, "from", parseTime); err != nil {
+		return err
+	}
+	if r.To, err = parseOptional(q, "to", parseTime); err != nil {
+		return err
+	}
+
+	r.Bucket = message.Bucket(q.Get("bucket"))
+	if !r.Bucket.Valid() {
+		return fmt.Errorf("query parameter %q: unknown bucket %q", "bucket", r.Bucket)
+	}
+
+	if r.Labels, err = parseLabels(q); err != nil {
+		return err
+	}
+	r.GroupByLabel = q.Get("group_by_label")
+
+	return nil
+}
+
+func (r *GetStatsReq) ToDomain() message.StatsFilter {
+	return message.StatsFilter{
+		From:         r.From,
+		To:           r.To,
+		Bucket:       r.Bucket,
+		Labels:       r.Labels,
+		GroupByLabel: r.GroupByLabel,
+	}
+}
 
 type GetStatsResp struct {
-	All       int `json:"all"`
-	Processed int `json:"processed"`
+	All           int            `json:"all"`
+	Processed     int            `json:"processed"`
+	OutboxPending int            `json:"outbox_pending"`
+	ByStatus      map[string]int `json:"by_status"`
+
+	Series  []StatsBucketResp `json:"series,omitempty"`
+	ByLabel []LabelStatsResp  `json:"by_label,omitempty"`
 }
 
 func (r *GetStatsResp) FromDomain(stats *message.Stats) {
 	r.All = stats.All
 	r.Processed = stats.Processed
+	r.OutboxPending = stats.OutboxPending
+
+	r.ByStatus = make(map[string]int, len(stats.ByStatus))
+	for status, count := range stats.ByStatus {
+		r.ByStatus[status.String()] = count
+	}
+
+	r.Series = nil
+	if stats.Series != nil {
+		r.Series = make([]StatsBucketResp, len(stats.Series))
+		for i := range stats.Series {
+			r.Series[i].FromDomain(&stats.Series[i])
+		}
+	}
+
+	r.ByLabel = nil
+	if stats.ByLabel != nil {
+		r.ByLabel = make([]LabelStatsResp, len(stats.ByLabel))
+		for i := range stats.ByLabel {
+			r.ByLabel[i].FromDomain(&stats.ByLabel[i])
+		}
+	}
+}
+
+// LabelStatsResp is the stats of messages with the same value of the group_by_label label.
+type LabelStatsResp struct {
+	Value     string `json:"value"`
+	All       int    `json:"all"`
+	Processed int    `json:"processed"`
+}
+
+func (r *LabelStatsResp) FromDomain(group *message.LabelStats) {
+	r.Value = group.Value
+	r.All = group.All
+	r.Processed = group.Processed
+}
+
+type StatsBucketResp struct {
+	Start     time.Time `json:"start"`
+	Created   int       `json:"created"`
+	Processed int       `json:"processed"`
+
+	Latency *LatencyResp `json:"latency,omitempty"`
+}
+
+func (r *StatsBucketResp) FromDomain(bucket *message.StatsBucket) {
+	r.Start = bucket.Start
+	r.Created = bucket.Created
+	r.Processed = bucket.Processed
+
+	r.Latency = nil
+	if bucket.Latency != nil {
+		r.Latency = &LatencyResp{}
+		r.Latency.FromDomain(bucket.Latency)
+	}
+}
+
+// LatencyResp is the processing latency percentiles in milliseconds.
+type LatencyResp struct {
+	P50 float64 `json:"p50_ms"`
+	P95 float64 `json:"p95_ms"`
+	P99 float64 `json:"p99_ms"`
+}
+
+func (r *LatencyResp) FromDomain(latency *message.Latency) {
+	r.P50 = durationToMs(latency.P50)
+	r.P95 = durationToMs(latency.P95)
+	r.P99 = durationToMs(latency.P99)
+}
+
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+const (
+	StatsStreamSnapshot = "snapshot"
+	StatsStreamDelta    = "delta"
+)
+
+// StatsStreamResp is a message of the stats WebSocket. Stats is set for the "snapshot" type,
+// Delta is set for the "delta" type.
+type StatsStreamResp struct {
+	Type  string          `json:"type" enums:"snapshot,delta"`
+	Stats *GetStatsResp   `json:"stats,omitempty"`
+	Delta *StatsDeltaResp `json:"delta,omitempty"`
+}
+
+// StatsDeltaResp is the change of the stats since the previous message, statuses without changes are omitted.
+type StatsDeltaResp struct {
+	All           int            `json:"all"`
+	Processed     int            `json:"processed"`
+	OutboxPending int            `json:"outbox_pending"`
+	ByStatus      map[string]int `json:"by_status"`
+}
+
+func (r *StatsDeltaResp) FromDomain(delta message.StatsDelta) {
+	r.All = delta.All
+	r.Processed = delta.Processed
+	r.OutboxPending = delta.OutboxPending
+
+	r.ByStatus = make(map[string]int, len(delta.ByStatus))
+	for status, count := range delta.ByStatus {
+		r.ByStatus[status.String()] = count
+	}
 }
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"errors"
+	"messagio_assignment/internal/domain/message"
+	"strconv"
+	"strings"
+)
+
+// FieldError is an invalid field of the request in the responses.
+type FieldError struct {
+	Field   string `json:"field" example:"content"`
+	Message string `json:"message" example:"must not be empty"`
+}
+
+// NewFieldErrors converts the field errors of the domain message for the responses.
+func NewFieldErrors(errs message.FieldErrors) []FieldError {
+	fe := make([]FieldError, len(errs))
+	for i, e := range errs {
+		fe[i] = FieldError{Field: e.Field, Message: e.Message}
+	}
+	return fe
+}
+
+// unknownFieldPrefix is the prefix of the json.Decoder error for a disallowed unknown field.
+// The error has no type, so it is matched by text.
+const unknownFieldPrefix = "json: unknown field "
+
+// UnknownFieldError converts the decoding error of an unknown field to message.FieldErrors.
+func UnknownFieldError(err error) (message.FieldErrors, bool) {
+	field, ok := strings.CutPrefix(err.Error(), unknownFieldPrefix)
+	if !ok {
+		return nil, false
+	}
+	if unquoted, err := strconv.Unquote(field); err == nil {
+		field = unquoted
+	}
+
+	return message.FieldErrors{{Field: field, Message: "unknown field"}}, true
+}
+
+// AsFieldErrors unwraps message.FieldErrors from the error.
+func AsFieldErrors(err error) (message.FieldErrors, bool) {
+	var fe message.FieldErrors
+	if errors.As(err, &fe) {
+		return fe, true
+	}
+	return nil, false
+}
@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/webhook"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ListDeliveriesReq struct {
+	MessageID *int
+	Status    *webhook.Status
+	Cursor    domain.Cursor
+	Limit     int
+}
+
+func (r *ListDeliveriesReq) FromQuery(q url.Values) error {
+	var err error
+
+	if r.MessageID, err = parseOptional(q, "message_id", strconv.Atoi); err != nil {
+		return err
+	}
+	if r.Status, err = parseOptional(q, "status", parseDeliveryStatus); err != nil {
+		return err
+	}
+
+	limit, err := parseOptional(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+	if limit != nil {
+		r.Limit = *limit
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		r.Cursor, err = domain.DecodeCursor(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ListDeliveriesReq) ToDomain() webhook.ListFilter {
+	filter := webhook.ListFilter{AfterID: r.Cursor.AfterID, Limit: r.Limit}
+	if r.MessageID != nil {
+		filter.MessageID = *r.MessageID
+	}
+	if r.Status != nil {
+		filter.Status = *r.Status
+	}
+	return filter
+}
+
+func parseDeliveryStatus(s string) (webhook.Status, error) {
+	status := webhook.Status(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("unknown status %q", s)
+	}
+	return status, nil
+}
+
+type DeliveryResp struct {
+	ID            int       `json:"id"`
+	MessageID     int       `json:"message_id"`
+	URL           string    `json:"url" example:"https://crm.example.com/hooks/messages"`
+	MessageStatus string    `json:"message_status" enums:"processed,failed,expired"`
+	EventAt       time.Time `json:"event_at"`
+
+	Status           string     `json:"status" enums:"pending,succeeded,failed"`
+	Attempts         int        `json:"attempts"`
+	NextAttemptAt    *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt    *time.Time `json:"last_attempt_at,omitempty"`
+	LastResponseCode int        `json:"last_response_code,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// FromDomain sets NextAttemptAt only for pending deliveries, others are not attempted anymore.
+func (r *DeliveryResp) FromDomain(d *webhook.Delivery) {
+	r.ID = d.ID
+	r.MessageID = d.MessageID
+	r.URL = d.URL
+	r.MessageStatus = d.MessageStatus.String()
+	r.EventAt = d.EventAt
+
+	r.Status = d.Status.String()
+	r.Attempts = d.Attempts
+	r.NextAttemptAt = nil
+	if d.Status == webhook.StatusPending {
+		next := d.NextAttemptAt
+		r.NextAttemptAt = &next
+	}
+	r.LastAttemptAt = d.LastAttemptAt
+	r.LastResponseCode = d.LastResponseCode
+	r.LastError = d.LastError
+
+	r.CreatedAt = d.CreatedAt
+	r.DeliveredAt = d.DeliveredAt
+}
+
+type ListDeliveriesResp struct {
+	Deliveries []DeliveryResp `json:"deliveries"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (r *ListDeliveriesResp) FromDomain(page *webhook.Page) {
+	r.Deliveries = make([]DeliveryResp, len(page.Deliveries))
+	for i, d := range page.Deliveries {
+		r.Deliveries[i].FromDomain(d)
+	}
+
+	r.NextCursor = ""
+	if page.NextAfterID != 0 {
+		r.NextCursor = domain.Cursor{AfterID: page.NextAfterID}.Encode()
+	}
+}
@@ -0,0 +1,188 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//go:generate mockery --name EventSubscriber
+type EventSubscriber interface {
+	// Subscribe returns the events of the message, of all messages if id is zero.
+	// The channel is closed by cancel or on shutdown.
+	Subscribe(id int) (events <-chan message.Event, cancel func())
+}
+
+const (
+	EventStreamContentType = "text/event-stream"
+	// sseEventStatus is the SSE event name of message status changes.
+	sseEventStatus = "status"
+	// sseHeartbeatInterval is how often a comment is sent to keep idle streams open through proxies.
+	sseHeartbeatInterval = 15 * time.Second
+)
+
+// StreamMessageEvents godoc
+//
+//	@Summary		Stream status changes of a message
+//	@Description	Server-Sent Events stream. The first "status" event is the current status,
+//	@Description	the next ones are sent when the message is processed. The stream ends after a final status.
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		text/event-stream
+//	@Param			id	path		int	true	"Message ID"
+//	@Success		200	{object}	dto.MessageEventResp	"data of the status events"
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/{id}/events [get]
+func (h *MessageHandler) StreamMessageEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "stream message events", r.Context())
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Warn("failed to parse message id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Subscribe before getting the message, so a change between them is not missed.
+		events, cancel := h.Events.Subscribe(id)
+		defer cancel()
+
+		msg, err := h.uc.GetMessage(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("message is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
+			default:
+				log.Error("failed to get message", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		current := message.Event{MessageID: msg.ID, Status: msg.Status, At: msg.CreatedAt}
+		if msg.ProcessedAt != nil {
+			current.At = *msg.ProcessedAt
+		}
+
+		h.stream(w, r, log, events, &current)
+	}
+}
+
+// StreamEvents godoc
+//
+//	@Summary		Stream status changes of all messages
+//	@Description	Server-Sent Events stream with a "status" event each time a message is created or processed.
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		text/event-stream
+//	@Success		200	{object}	dto.MessageEventResp	"data of the status events"
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/events [get]
+func (h *MessageHandler) StreamEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "stream events", r.Context())
+
+		events, cancel := h.Events.Subscribe(0)
+		defer cancel()
+
+		h.stream(w, r, log, events, nil)
+	}
+}
+
+// stream writes the first event and the events from the channel until the client goes away,
+// the channel is closed or a message of the single message stream reaches a final status.
+func (h *MessageHandler) stream(w http.ResponseWriter, r *http.Request, log *slog.Logger,
+	events <-chan message.Event, first *message.Event) {
+	rc := http.NewResponseController(w)
+	// The server write timeout is for regular responses, the stream lasts until it ends.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn("failed to reset write deadline", logger.Err(err))
+	}
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(ev message.Event) bool {
+		data, err := json.Marshal(h.DTOs.MessageEventResp(ev))
+		if err != nil {
+			log.Error("failed to marshal event", logger.Err(err))
+			return false
+		}
+
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventStatus, data)
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			log.Info("failed to write event", logger.Err(err))
+			return false
+		}
+		return true
+	}
+
+	if first != nil {
+		if !send(*first) || first.Status.IsFinal() {
+			return
+		}
+	} else if err := rc.Flush(); err != nil {
+		log.Info("failed to flush stream", logger.Err(err))
+		return
+	}
+
+	log.Info("stream is started")
+	defer log.Info("stream is finished")
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok || !send(ev) {
+				return
+			}
+			if first != nil && ev.Status.IsFinal() {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
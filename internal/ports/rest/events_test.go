@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func sseEvent(id int, status message.Status, at time.Time) string {
+	return fmt.Sprintf("event: status\ndata: {\"id\":%d,\"status\":%q,\"at\":%q}\n\n",
+		id, status, at.Format(time.RFC3339Nano))
+}
+
+func TestMessageHandler_StreamMessageEvents(t *testing.T) {
+	createdAt := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	processedAt := createdAt.Add(1500 * time.Millisecond)
+
+	tcases := []struct {
+		Name           string
+		ID             string
+		ExpectedStatus int
+		ExpectedBody   string
+		MockInit       func(uc *mocks.MessageUsecase, es *mocks.EventSubscriber)
+	}{
+		{
+			Name:           "already_processed",
+			ID:             "42",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   sseEvent(42, message.StatusProcessed, processedAt),
+			MockInit: func(uc *mocks.MessageUsecase, es *mocks.EventSubscriber) {
+				es.On("Subscribe", 42).Return((<-chan message.Event)(make(chan message.Event)), func() {}).Once()
+				uc.On("GetMessage", mock.Anything, 42).Return(&message.Message{
+					ID: 42, Status: message.StatusProcessed, CreatedAt: createdAt, ProcessedAt: &processedAt,
+				}, nil).Once()
+			},
+		},
+		{
+			Name:           "processed_later",
+			ID:             "42",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody: sseEvent(42, message.StatusQueued, createdAt) +
+				sseEvent(42, message.StatusProcessing, processedAt) +
+				sseEvent(42, message.StatusProcessed, processedAt),
+			MockInit: func(uc *mocks.MessageUsecase, es *mocks.EventSubscriber) {
+				events := make(chan message.Event, 3)
+				events <- message.Event{MessageID: 42, Status: message.StatusProcessing, At: processedAt}
+				events <- message.Event{MessageID: 42, Status: message.StatusProcessed, At: processedAt}
+				// The stream ends after the final status, so this event is not sent.
+				events <- message.Event{MessageID: 42, Status: message.StatusFailed, At: processedAt}
+
+				es.On("Subscribe", 42).Return((<-chan message.Event)(events), func() {}).Once()
+				uc.On("GetMessage", mock.Anything, 42).Return(&message.Message{
+					ID: 42, Status: message.StatusQueued, CreatedAt: createdAt,
+				}, nil).Once()
+			},
+		},
+		{
+			Name:           "not_found",
+			ID:             "42",
+			ExpectedStatus: http.StatusNotFound,
+			MockInit: func(uc *mocks.MessageUsecase, es *mocks.EventSubscriber) {
+				es.On("Subscribe", 42).Return((<-chan message.Event)(make(chan message.Event)), func() {}).Once()
+				uc.On("GetMessage", mock.Anything, 42).
+					Return(nil, &message.ErrorWithID{ID: 42, Err: domain.ErrNotFound}).Once()
+			},
+		},
+		{
+			Name:           "invalid_id",
+			ID:             "abc",
+			ExpectedStatus: http.StatusBadRequest,
+			MockInit:       func(_ *mocks.MessageUsecase, _ *mocks.EventSubscriber) {},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	es := mocks.NewEventSubscriber(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.Events = es
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.MockInit(uc, es)
+
+			resp := e.GET("/messages/{id}/events", tc.ID).
+				Expect().
+				Status(tc.ExpectedStatus)
+
+			if tc.ExpectedStatus != http.StatusOK {
+				assertProblem(resp.JSON(contentOpts(true)).Object(), tc.ExpectedStatus)
+				return
+			}
+
+			resp.HasContentType(EventStreamContentType)
+			resp.Header("Cache-Control").IsEqual("no-cache")
+			resp.Body().IsEqual(tc.ExpectedBody)
+		})
+	}
+}
+
+func TestMessageHandler_StreamEvents(t *testing.T) {
+	at := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+
+	uc := mocks.NewMessageUsecase(t)
+	es := mocks.NewEventSubscriber(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.Events = es
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	events := make(chan message.Event, 2)
+	events <- message.Event{MessageID: 1, Status: message.StatusProcessed, At: at}
+	events <- message.Event{MessageID: 2, Status: message.StatusExpired, At: at}
+	// The hub closes the channel on shutdown, so the stream ends.
+	close(events)
+
+	cancelled := false
+	es.On("Subscribe", 0).Return((<-chan message.Event)(events), func() { cancelled = true }).Once()
+
+	httpexpect.Default(t, server.URL).GET("/messages/events").
+		Expect().
+		Status(http.StatusOK).
+		HasContentType(EventStreamContentType).
+		Body().IsEqual(sseEvent(1, message.StatusProcessed, at) + sseEvent(2, message.StatusExpired, at))
+
+	if !cancelled {
+		t.Error("subscription is not cancelled")
+	}
+}
+
+func TestMessageHandler_EventsDisabled(t *testing.T) {
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	httpexpect.Default(t, server.URL).GET("/messages/1/events").
+		Expect().
+		Status(http.StatusNotFound)
+}
@@ -13,20 +13,30 @@ type ChiHandler interface {
 }
 
 type Handler struct {
-	Router     chi.Router
-	msgHandler ChiHandler
-	Log        *slog.Logger
+	Router   chi.Router
+	versions []APIVersion
+	handlers []ChiHandler
+	Log      *slog.Logger
+
+	// aliases has the routes of the first version that are served without the version prefix.
+	aliases     chi.Router
+	deprecation Deprecation
 }
 
-func NewHandler(router chi.Router, mh ChiHandler, log *slog.Logger) *Handler {
+// NewHandler sets up routes of the API versions and other handlers, e.g. the admin ones.
+// The routes of the first version are also served without the version prefix with the deprecation headers.
+func NewHandler(router chi.Router, versions []APIVersion, deprecation Deprecation, log *slog.Logger,
+	others ...ChiHandler) *Handler {
 	if log == nil {
 		log = logger.NewEraseLogger()
 	}
 
 	h := &Handler{
-		Router:     router,
-		msgHandler: mh,
-		Log:        log,
+		Router:      router,
+		versions:    versions,
+		handlers:    others,
+		Log:         log,
+		deprecation: deprecation,
 	}
 	h.Configure()
 	return h
@@ -38,11 +48,25 @@ func (h *Handler) Configure() {
 }
 
 func (h *Handler) Routes() {
-	h.Router.Route("/", h.setupOtherRoutes)
+	h.Router.Route("/", func(r chi.Router) {
+		for i, v := range h.versions {
+			versioned := chi.NewRouter()
+			v.Handler.SetupRoutes(versioned)
+			r.Mount("/"+v.Name, versioned)
+
+			if i == 0 {
+				h.aliases = versioned
+			}
+		}
+
+		h.setupOtherRoutes(r)
+	})
 }
 
 func (h *Handler) setupOtherRoutes(r chi.Router) {
-	h.msgHandler.SetupRoutes(r)
+	for _, handler := range h.handlers {
+		handler.SetupRoutes(r)
+	}
 }
 
 func (h *Handler) Middlewares() {
@@ -50,6 +74,7 @@ func (h *Handler) Middlewares() {
 	h.Router.Use(LogMiddleware(h.Log))
 	h.Router.Use(middleware.Recoverer)
 	h.Router.Use(middleware.Heartbeat("/health"))
+	h.Router.Use(h.unversionedAliases)
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -40,7 +40,7 @@ func TestNewHandler(t *testing.T) {
 	handlerMock := &ChiHandlerMock{}
 
 	router := chi.NewRouter()
-	handler := NewHandler(router, handlerMock, nil)
+	handler := NewHandler(router, []APIVersion{{Name: "v1", Handler: handlerMock}}, Deprecation{}, nil)
 
 	require.NotNil(t, handler.Log)
 	assert.True(t, handlerMock.IsRoutesSetup)
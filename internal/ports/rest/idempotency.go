@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/idempotency"
+	"messagio_assignment/internal/logger"
+	"net/http"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength  = 255
+)
+
+//go:generate mockery --name IdempotencyUsecase
+type IdempotencyUsecase interface {
+	Begin(ctx context.Context, key, fingerprint string) (string, *idempotency.Response, error)
+	Complete(ctx context.Context, key, token string, resp idempotency.Response) error
+	Release(ctx context.Context, key, token string) error
+}
+
+// Idempotent replays the stored response for requests with the same Idempotency-Key header.
+// Only successful responses are stored, failed requests can be retried with the same key.
+// Requests without the header and handlers without IdempotencyUsecase are passed as is.
+func (h *MessageHandler) Idempotent(next http.Handler) http.Handler {
+	if h.Idempotency == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log := logger.ForRest(h.Log, "idempotency", r.Context()).
+			With(slog.String("idempotency_key", key))
+
+		if len(key) > MaxIdempotencyKeyLength {
+			h.error(w, r, http.StatusBadRequest,
+				fmt.Errorf("%s header is longer than %d", IdempotencyKeyHeader, MaxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Warn("failed to read request body", logger.Err(err))
+			h.error(w, r, bodyErrorStatus(err), err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		token, resp, err := h.Idempotency.Begin(r.Context(), key, requestFingerprint(r, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrAlreadyExists):
+				log.Warn("request with the key is in progress", logger.Err(err))
+				h.error(w, r, http.StatusConflict, err)
+			case errors.Is(err, idempotency.ErrFingerprintMismatch):
+				log.Warn("key is reused with a different request", logger.Err(err))
+				h.error(w, r, http.StatusUnprocessableEntity, err)
+			default:
+				log.Error("failed to begin idempotent request", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		if resp != nil {
+			log.Info("response is replayed", slog.Int("status", resp.StatusCode))
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(resp.StatusCode)
+			if _, err = w.Write(resp.Body); err != nil {
+				log.Error("response write replayed data error", logger.Err(err))
+			}
+			return
+		}
+
+		// the response is already sent, so the key must be stored even if the client is gone
+		ctx := context.WithoutCancel(r.Context())
+
+		// the key is released if the request fails or next panics, so it can be retried at once
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			err := h.Idempotency.Release(ctx, key, token)
+			switch {
+			case errors.Is(err, idempotency.ErrReservationLost):
+				log.Warn("idempotency key is reserved again by another request", logger.Err(err))
+			case err != nil:
+				log.Error("failed to release idempotency key", logger.Err(err))
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= 200 && rec.status < 300 {
+			// the key is not released if the response is not stored, the request must not be repeated
+			completed = true
+			err = h.Idempotency.Complete(ctx, key, token, idempotency.Response{
+				StatusCode: rec.status,
+				Body:       rec.body.Bytes(),
+			})
+			switch {
+			case errors.Is(err, idempotency.ErrReservationLost):
+				// the request took longer than the reservation timeout, its response is not replayed
+				log.Warn("idempotency key is reserved again by another request", logger.Err(err))
+			case err != nil:
+				log.Error("failed to complete idempotent request", logger.Err(err))
+			}
+		}
+	})
+}
+
+// requestFingerprint identifies the request by method, route and body. The route pattern is used
+// instead of the path, so a retry through an unversioned alias is the same request as through /v1.
+func requestFingerprint(r *http.Request, body []byte) string {
+	route := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(route))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder writes the response and keeps its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,237 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/idempotency"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/dto"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessageHandler_Idempotent(t *testing.T) {
+	msgReq := dto.CreateMessageReq{Content: "some content"}
+
+	storedResp := dto.CreateMessageResp{ID: 7, Content: msgReq.Content}
+	storedBody, err := json.Marshal(storedResp)
+	require.NoError(t, err)
+
+	tcases := []struct {
+		Name             string
+		Key              string
+		ExpectedStatus   int
+		ExpectedReplayed bool
+		ExpectedCode     domain.Code
+		UcMockInit       func(uc *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase)
+	}{
+		{
+			Name:           "without key",
+			Key:            "",
+			ExpectedStatus: http.StatusCreated,
+			UcMockInit: func(uc *mocks.MessageUsecase, _ *mocks.IdempotencyUsecase) {
+				uc.On("CreateMessage", mock.Anything, msgReq.ToDomain()).Return(nil).Once()
+			},
+		},
+		{
+			Name:           "new key",
+			Key:            "new-key",
+			ExpectedStatus: http.StatusCreated,
+			UcMockInit: func(uc *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "new-key", mock.AnythingOfType("string")).
+					Return("token", nil, nil).Once()
+				uc.On("CreateMessage", mock.Anything, msgReq.ToDomain()).Return(nil).Once()
+				iu.On("Complete", mock.Anything, "new-key", "token", mock.MatchedBy(func(resp idempotency.Response) bool {
+					return resp.StatusCode == http.StatusCreated && len(resp.Body) > 0
+				})).Return(nil).Once()
+			},
+		},
+		{
+			Name:             "replay",
+			Key:              "used-key",
+			ExpectedStatus:   http.StatusCreated,
+			ExpectedReplayed: true,
+			UcMockInit: func(_ *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "used-key", mock.AnythingOfType("string")).
+					Return("", &idempotency.Response{StatusCode: http.StatusCreated, Body: storedBody}, nil).Once()
+			},
+		},
+		{
+			Name:           "in progress",
+			Key:            "busy-key",
+			ExpectedStatus: http.StatusConflict,
+			ExpectedCode:   domain.CodeAlreadyExists,
+			UcMockInit: func(_ *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "busy-key", mock.AnythingOfType("string")).
+					Return("", nil, &idempotency.Error{Key: "busy-key", Err: domain.ErrAlreadyExists}).Once()
+			},
+		},
+		{
+			Name:           "different request",
+			Key:            "reused-key",
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedCode:   domain.CodeIdempotencyKeyReused,
+			UcMockInit: func(_ *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "reused-key", mock.AnythingOfType("string")).
+					Return("", nil, &idempotency.Error{Key: "reused-key", Err: idempotency.ErrFingerprintMismatch}).Once()
+			},
+		},
+		{
+			Name:           "failed request releases key",
+			Key:            "failed-key",
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedCode:   domain.CodeNotCreated,
+			UcMockInit: func(uc *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "failed-key", mock.AnythingOfType("string")).
+					Return("token", nil, nil).Once()
+				uc.On("CreateMessage", mock.Anything, msgReq.ToDomain()).
+					Return(&message.Error{Err: errors.New("db error")}).Once()
+				iu.On("Release", mock.Anything, "failed-key", "token").Return(nil).Once()
+			},
+		},
+		{
+			Name:           "reservation taken over",
+			Key:            "slow-key",
+			ExpectedStatus: http.StatusCreated,
+			UcMockInit: func(uc *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "slow-key", mock.AnythingOfType("string")).
+					Return("token", nil, nil).Once()
+				uc.On("CreateMessage", mock.Anything, msgReq.ToDomain()).Return(nil).Once()
+				// the response is sent, but not stored for the key of the other request
+				iu.On("Complete", mock.Anything, "slow-key", "token", mock.Anything).
+					Return(&idempotency.Error{Key: "slow-key", Err: idempotency.ErrReservationLost}).Once()
+			},
+		},
+		{
+			Name:           "storage error",
+			Key:            "some-key",
+			ExpectedStatus: http.StatusInternalServerError,
+			ExpectedCode:   domain.CodeInternal,
+			UcMockInit: func(_ *mocks.MessageUsecase, iu *mocks.IdempotencyUsecase) {
+				iu.On("Begin", mock.Anything, "some-key", mock.AnythingOfType("string")).
+					Return("", nil, errors.New("db error")).Once()
+			},
+		},
+		{
+			Name:           "too long key",
+			Key:            strings.Repeat("k", MaxIdempotencyKeyLength+1),
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedCode:   domain.CodeBadRequest,
+			UcMockInit:     func(_ *mocks.MessageUsecase, _ *mocks.IdempotencyUsecase) {},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	iu := mocks.NewIdempotencyUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.Idempotency = iu
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc, iu)
+
+			req := e.POST("/messages").WithJSON(msgReq)
+			if tc.Key != "" {
+				req = req.WithHeader(IdempotencyKeyHeader, tc.Key)
+			}
+
+			resp := req.Expect().
+				Status(tc.ExpectedStatus).
+				HasContentType(contentOpts(tc.ExpectedCode != "").MediaType)
+
+			if tc.ExpectedCode != "" {
+				obj := resp.JSON(contentOpts(true)).Object()
+				assertProblem(obj, tc.ExpectedStatus)
+				obj.Value("code").IsEqual(tc.ExpectedCode)
+			}
+
+			if !tc.ExpectedReplayed {
+				resp.Headers().NotContainsKey(IdempotentReplayedHeader)
+				return
+			}
+
+			resp.Header(IdempotentReplayedHeader).IsEqual("true")
+			resp.Body().IsEqual(string(storedBody))
+		})
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	post := httptest.NewRequest(http.MethodPost, "/messages", nil)
+	batch := httptest.NewRequest(http.MethodPost, "/messages/batch", nil)
+
+	require.Equal(t, requestFingerprint(post, []byte("body")), requestFingerprint(post, []byte("body")))
+	require.NotEqual(t, requestFingerprint(post, []byte("body")), requestFingerprint(post, []byte("other")))
+	require.NotEqual(t, requestFingerprint(post, []byte("body")), requestFingerprint(batch, []byte("body")))
+}
+
+func TestMessageHandler_IdempotentReleasesOnPanic(t *testing.T) {
+	iu := mocks.NewIdempotencyUsecase(t)
+	mh := NewMessageHandler(chi.NewRouter(), mocks.NewMessageUsecase(t), nil, MessageHandlerConfig{})
+	mh.Idempotency = iu
+
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+	router.With(mh.Idempotent).Post("/messages", func(_ http.ResponseWriter, _ *http.Request) {
+		panic("handler bug")
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	iu.On("Begin", mock.Anything, "panic-key", mock.AnythingOfType("string")).Return("token", nil, nil).Once()
+	iu.On("Release", mock.Anything, "panic-key", "token").Return(nil).Once()
+
+	httpexpect.Default(t, server.URL).POST("/messages").
+		WithHeader(IdempotencyKeyHeader, "panic-key").
+		WithJSON(dto.CreateMessageReq{Content: "some content"}).
+		Expect().
+		Status(http.StatusInternalServerError)
+}
+
+func TestMessageHandler_BodyLimit(t *testing.T) {
+	iu := mocks.NewIdempotencyUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, mocks.NewMessageUsecase(t), nil, MessageHandlerConfig{MaxBodySize: 64})
+	mh.Idempotency = iu
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+	large := dto.CreateMessageReq{Content: strings.Repeat("a", 100)}
+
+	for _, key := range []string{"", "large-key"} {
+		req := e.POST("/messages").WithJSON(large)
+		if key != "" {
+			req = req.WithHeader(IdempotencyKeyHeader, key)
+		}
+
+		obj := req.Expect().
+			Status(http.StatusRequestEntityTooLarge).
+			JSON(contentOpts(true)).Object()
+		obj.Value("code").IsEqual(domain.CodePayloadTooLarge)
+	}
+
+	e.POST("/messages/batch").
+		WithJSON([]dto.CreateMessageReq{large}).
+		Expect().
+		Status(http.StatusRequestEntityTooLarge)
+}
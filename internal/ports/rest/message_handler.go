@@ -4,26 +4,48 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httprate"
 	"log/slog"
 	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
 	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
 	"messagio_assignment/internal/logger"
 	"messagio_assignment/internal/ports/rest/dto"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 //go:generate mockery --name MessageUsecase
 type MessageUsecase interface {
 	CreateMessage(ctx context.Context, msg *message.Message) error
-	GetStats(ctx context.Context) (*message.Stats, error)
+	CreateMessages(ctx context.Context, msgs []*message.Message) error
+	GetMessage(ctx context.Context, id int) (*message.Message, error)
+	CancelMessage(ctx context.Context, id int) (*message.Message, error)
+	DeleteMessage(ctx context.Context, id int) error
+	ListMessages(ctx context.Context, filter message.ListFilter) (*message.Page, error)
+	SearchMessages(ctx context.Context, query message.SearchQuery) (*message.SearchPage, error)
+	GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error)
 }
 
+const (
+	DefaultMaxBatchSize = 1000
+	DefaultMaxBodySize  = 10 << 20
+)
+
 type MessageHandlerConfig struct {
-	CreateMsgPerMinute int
-	GetStatsPerMinute  int
+	// MaxBatchSize is the maximum number of messages in one batch, DefaultMaxBatchSize if zero.
+	MaxBatchSize int
+	// MaxBodySize is the maximum size of message creation requests in bytes, DefaultMaxBodySize if zero.
+	MaxBodySize int64
+	// MaxWait caps the wait query parameter of GetMessage, DefaultMaxWait if zero.
+	// It must be less than the server write timeout, see MaxWaitFor.
+	MaxWait time.Duration
+
+	RateLimit  limiter.Config
+	Validation ValidationConfig
 }
 
 type MessageHandler struct {
@@ -31,7 +53,16 @@ type MessageHandler struct {
 	uc     MessageUsecase
 	cfg    MessageHandlerConfig
 
-	Log *slog.Logger
+	// Idempotency enables Idempotency-Key header support for message creation if it is not nil.
+	Idempotency IdempotencyUsecase
+	// Auth requires messages:read and messages:write scopes if it is not nil.
+	Auth *Auth
+	// Events enables the SSE streams of message status changes if it is not nil.
+	Events EventSubscriber
+	// DTOs are the request and response DTOs of the API version of the handler, V1DTOs by default.
+	DTOs MessageDTOs
+
+	responder
 }
 
 func NewMessageHandler(router chi.Router, uc MessageUsecase,
@@ -43,24 +74,37 @@ func NewMessageHandler(router chi.Router, uc MessageUsecase,
 	log = log.With(
 		slog.String("component", "ports/rest/message_handler"),
 	)
-	return &MessageHandler{router: router, uc: uc, Log: log, cfg: cfg}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = DefaultMaxWait
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &MessageHandler{router: router, uc: uc, DTOs: V1DTOs{}, responder: responder{Log: log}, cfg: cfg}
 }
 
 func (h *MessageHandler) SetupRoutes(r chi.Router) {
+	read, write := h.Auth.Require(auth.ScopeMessagesRead), h.Auth.Require(auth.ScopeMessagesWrite)
+
 	r.Route("/messages", func(r chi.Router) {
-		if h.cfg.CreateMsgPerMinute != 0 {
-			r.Use(httprate.Limit(h.cfg.CreateMsgPerMinute, time.Minute,
-				httprate.WithLimitHandler(h.Limit()),
-			))
+		r.With(write, h.rateLimit(limiter.RouteCreateMsg), h.limitBody, h.Idempotent).Post("/", h.CreateMessage())
+		r.With(write, h.rateLimit(limiter.RouteCreateBatch), h.limitBody, h.Idempotent).Post("/batch", h.CreateMessages())
+		r.With(read, h.rateLimit(limiter.RouteListMsg)).Get("/", h.ListMessages())
+		r.With(read, h.rateLimit(limiter.RouteSearch)).Get("/search", h.SearchMessages())
+		r.With(read, h.rateLimit(limiter.RouteGetMsg)).Get("/{id}", h.GetMessage())
+		if h.Events != nil {
+			r.With(read, h.rateLimit(limiter.RouteEvents)).Get("/events", h.StreamEvents())
+			r.With(read, h.rateLimit(limiter.RouteEvents)).Get("/{id}/events", h.StreamMessageEvents())
 		}
-		r.Post("/", h.CreateMessage())
+		r.With(write, h.rateLimit(limiter.RouteCancelMsg)).Post("/{id}/cancel", h.CancelMessage())
+		r.With(write, h.rateLimit(limiter.RouteDeleteMsg)).Delete("/{id}", h.DeleteMessage())
 	})
 	r.Route("/messages/stats", func(r chi.Router) {
-		if h.cfg.GetStatsPerMinute != 0 {
-			r.Use(httprate.Limit(h.cfg.GetStatsPerMinute, time.Minute,
-				httprate.WithLimitHandler(h.Limit()),
-			))
-		}
+		r.Use(read, h.rateLimit(limiter.RouteGetStats))
 		r.Get("/", h.GetStats())
 	})
 }
@@ -74,123 +118,542 @@ func (h *MessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 //	@Summary		Create a message
 //	@Description	create a message
 //	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
 //	@Param			message body		dto.CreateMessageReq	true	"Create message"
+//	@Param			Idempotency-Key	header	string	false	"Replays the stored response for a retried request with the same key"
 //	@Success		201	{object}	dto.CreateMessageResp
-//	@Failure		400	{object}	dto.HTTPError
-//	@Failure		409	{object}	dto.HTTPError
-//	@Failure		422	{object}	dto.HTTPError
-//	@Failure		429	{object}	dto.HTTPError
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		409	{object}	dto.Problem	"Message already exists or the request with the same Idempotency-Key is in progress"
+//	@Failure		413	{object}	dto.Problem
+//	@Failure		422	{object}	dto.ValidationProblem	"Invalid fields, message is not created or Idempotency-Key is reused with a different request"
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
 //	@Failure		500
 //
-// @Header       all              {string}  X-RateLimit-Limit    "Request limit per minute"
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
 // @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
 // @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
 //
-//	@Router			/messages [post]
+//	@Router			/v1/messages [post]
 func (h *MessageHandler) CreateMessage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.ForRest(h.Log, "create message", r.Context())
 
-		msgReq := dto.CreateMessageReq{}
+		msgReq := h.DTOs.CreateMessageReq()
 
-		if err := json.NewDecoder(r.Body).Decode(&msgReq); err != nil {
+		dec := json.NewDecoder(r.Body)
+		if h.cfg.Validation.DisallowUnknownFields {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(msgReq); err != nil {
 			log.Warn("failed to decode request body", logger.Err(err))
-			h.error(w, http.StatusBadRequest, err)
+			if fe, ok := dto.UnknownFieldError(err); ok {
+				h.validationError(w, r, fe)
+				return
+			}
+			h.error(w, r, bodyErrorStatus(err), err)
+			return
+		}
+		draft, fe := h.validateMessage(msgReq)
+		if len(fe) > 0 {
+			log.Warn("invalid request body", logger.Err(fe))
+			h.validationError(w, r, fe)
 			return
 		}
 
 		log.Info("request body is decoded", slog.Any("msgReq", msgReq))
-		msg := msgReq.ToDomain()
+		msg := draft.Message()
 
 		err := h.uc.CreateMessage(r.Context(), msg)
 		if err != nil {
 			log.Error("failed to create message", logger.Err(err))
+			status, code := createProblem(err)
+			h.problem(w, r, status, code)
+			return
+		}
+
+		log.Info("message is created", slog.Any("msg", msg))
+
+		h.respond(w, http.StatusCreated, h.DTOs.CreateMessageResp(msg))
+	}
+}
+
+// CreateMessages godoc
+//
+//	@Summary		Create messages in batch
+//	@Description	create messages in one transaction, malformed messages are reported per item and skipped
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			messages body	[]dto.CreateMessageReq	true	"Create messages"
+//	@Param			Idempotency-Key	header	string	false	"Replays the stored response for a retried request with the same key"
+//	@Success		201	{object}	dto.CreateMessagesResp	"All messages are created"
+//	@Success		207	{object}	dto.CreateMessagesResp	"Some messages are malformed"
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		409	{object}	dto.Problem
+//	@Failure		413	{object}	dto.Problem
+//	@Failure		422	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/batch [post]
+func (h *MessageHandler) CreateMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "create messages", r.Context())
+
+		var batchReq dto.CreateMessagesReq
+		if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
+			log.Warn("failed to decode request body", logger.Err(err))
+			h.error(w, r, bodyErrorStatus(err), err)
+			return
+		}
+
+		switch {
+		case len(batchReq) == 0:
+			h.error(w, r, http.StatusBadRequest, errors.New("batch is empty"))
+			return
+		case len(batchReq) > h.cfg.MaxBatchSize:
+			h.error(w, r, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("batch has more than %d messages", h.cfg.MaxBatchSize))
+			return
+		}
+
+		items := make([]BatchItem, len(batchReq))
+		msgs := make([]*message.Message, 0, len(batchReq))
+		for i := range batchReq {
+			msgReq := h.DTOs.CreateMessageReq()
+			err := batchReq.DecodeItem(i, msgReq, h.cfg.Validation.DisallowUnknownFields)
+
+			var draft *message.Draft
+			if err == nil {
+				var fe message.FieldErrors
+				if draft, fe = h.validateMessage(msgReq); len(fe) > 0 {
+					err = fe
+				}
+			}
+			if err != nil {
+				log.Warn("malformed message in batch", slog.Int("index", i), logger.Err(err))
+				items[i].Err = err
+				continue
+			}
+
+			// the usecase sets the id of the message of the item
+			items[i].Msg = draft.Message()
+			msgs = append(msgs, items[i].Msg)
+		}
+		malformed := len(batchReq) - len(msgs)
+
+		log.Info("request body is decoded", slog.Int("valid", len(msgs)), slog.Int("malformed", malformed))
+
+		if len(msgs) > 0 {
+			err := h.uc.CreateMessages(r.Context(), msgs)
+			if err != nil {
+				log.Error("failed to create messages", logger.Err(err))
+				status, code := createProblem(err)
+				h.problem(w, r, status, code)
+				return
+			}
+		}
+
+		log.Info("messages are created", slog.Int("count", len(msgs)))
+
+		status := http.StatusCreated
+		if malformed > 0 {
+			status = http.StatusMultiStatus
+		}
+
+		h.respond(w, status, h.DTOs.CreateMessagesResp(items))
+	}
+}
+
+// GetMessage godoc
+//
+//	@Summary		Get a message
+//	@Description	get a message by id. With wait, the request blocks until the message is processed,
+//	@Description	failed, cancelled or expired or the wait elapses, and then returns the current state.
+//	@Description	The wait is capped to fit the server write timeout.
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		int		true	"Message ID"
+//	@Param			wait	query		string	false	"How long to wait for a processing result, e.g. 30s"
+//	@Success		200		{object}	dto.GetMessageResp
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/{id} [get]
+func (h *MessageHandler) GetMessage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "get message", r.Context())
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Warn("failed to parse message id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var getReq dto.GetMessageReq
+		if err := getReq.FromQuery(r.URL.Query()); err != nil {
+			log.Warn("failed to parse query", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var events <-chan message.Event
+		if getReq.Wait > 0 && h.Events != nil {
+			// Subscribe before getting the message, so a change between them is not missed.
+			var cancel func()
+			events, cancel = h.Events.Subscribe(id)
+			defer cancel()
+		}
+
+		msg, err := h.uc.GetMessage(r.Context(), id)
+		if err == nil && getReq.Wait > 0 {
+			msg, err = h.waitMessage(r.Context(), msg, events, min(getReq.Wait, h.cfg.MaxWait))
+		}
+		if err != nil {
 			switch {
-			case errors.Is(err, domain.ErrAlreadyExists):
-				h.error(w, http.StatusConflict, err)
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("message is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
 			default:
-				h.error(w, http.StatusUnprocessableEntity, err) // or InternalError?
+				log.Error("failed to get message", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
 			}
 			return
 		}
 
-		log.Info("message is created", slog.Any("msg", msg))
-
-		var msgResp dto.CreateMessageResp
-		msgResp.FromDomain(msg)
+		log.Info("message is gotten", slog.Any("msg", msg))
 
-		h.respond(w, http.StatusCreated, msgResp)
+		h.respond(w, http.StatusOK, h.DTOs.GetMessageResp(msg))
 	}
 }
 
-// GetStats godoc
+// CancelMessage godoc
 //
-//	@Summary		Get messages stats
-//	@Description	get messages stats
+//	@Summary		Cancel a scheduled message
+//	@Description	cancel a message with send_at before it is sent, so it is never produced
 //	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
 //	@Produce		json
-//	@Success		200	{object}	dto.GetStatsResp
-//	@Failure		429	{object}	dto.HTTPError
-//	@Failure		500	{object}	dto.HTTPError
-//	@Failure		500
+//	@Param			id	path		int	true	"Message ID"
+//	@Success		200	{object}	dto.GetMessageResp
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem
+//	@Failure		409	{object}	dto.Problem	"Message is not scheduled or is already sent"
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
 //
-// @Header       all              {string}  X-RateLimit-Limit    "Request limit per minute"
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
 // @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
 // @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
 //
-//	@Router			/messages/stats [get]
-func (h *MessageHandler) GetStats() http.HandlerFunc {
+//	@Router			/v1/messages/{id}/cancel [post]
+func (h *MessageHandler) CancelMessage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log := logger.ForRest(h.Log, "get stats", r.Context())
+		log := logger.ForRest(h.Log, "cancel message", r.Context())
 
-		stats, err := h.uc.GetStats(r.Context())
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			log.Error("failed to get stats", logger.Err(err))
-			h.error(w, http.StatusInternalServerError, err)
+			log.Warn("failed to parse message id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		log.Info("stats is gotten", slog.Any("stats", stats))
+		msg, err := h.uc.CancelMessage(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("message is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
+			case errors.Is(err, domain.ErrConflict):
+				log.Warn("message can't be cancelled", logger.Err(err))
+				h.error(w, r, http.StatusConflict, err)
+			default:
+				log.Error("failed to cancel message", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
 
-		var statsResp dto.GetStatsResp
-		statsResp.FromDomain(stats)
+		log.Info("message is cancelled", slog.Any("msg", msg))
 
-		h.respond(w, http.StatusOK, statsResp)
+		h.respond(w, http.StatusOK, h.DTOs.GetMessageResp(msg))
 	}
 }
 
-func (h *MessageHandler) Limit() http.HandlerFunc {
+// DeleteMessage godoc
+//
+//	@Summary		Delete a message
+//	@Description	soft delete a message, it is hidden from reads and stats and purged later
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path	int	true	"Message ID"
+//	@Success		204
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/{id} [delete]
+func (h *MessageHandler) DeleteMessage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		h.error(w, http.StatusTooManyRequests, errors.New("too many requests"))
+		log := logger.ForRest(h.Log, "delete message", r.Context())
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Warn("failed to parse message id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = h.uc.DeleteMessage(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("message is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
+			default:
+				log.Error("failed to delete message", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("message is deleted", slog.Int("id", id))
+
+		h.respond(w, http.StatusNoContent, nil)
 	}
 }
 
-func (h *MessageHandler) error(w http.ResponseWriter, code int, err error) {
-	h.respond(w, code, dto.HTTPError{Error: err.Error()})
+// ListMessages godoc
+//
+//	@Summary		List messages
+//	@Description	list messages ordered by id with keyset pagination
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			status			query		string	false	"Filter by status"	Enums(pending, queued, processing, processed, failed, cancelled, expired)
+//	@Param			label			query		[]string	false	"Filter by label key:value, all labels must match"	collectionFormat(multi)
+//	@Param			min_id			query		int		false	"Minimal message id, inclusive"
+//	@Param			max_id			query		int		false	"Maximal message id, inclusive"
+//	@Param			created_from	query		string	false	"Created at or after, RFC 3339"
+//	@Param			created_to		query		string	false	"Created before, RFC 3339"
+//	@Param			limit			query		int		false	"Page size, 50 by default, 500 at most"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Success		200				{object}	dto.ListMessagesResp
+//	@Failure		400				{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429				{object}	dto.Problem
+//	@Failure		500				{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages [get]
+func (h *MessageHandler) ListMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "list messages", r.Context())
+
+		var listReq dto.ListMessagesReq
+		if err := listReq.FromQuery(r.URL.Query()); err != nil {
+			log.Warn("failed to parse query", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		page, err := h.uc.ListMessages(r.Context(), listReq.ToDomain())
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidArgument):
+				log.Warn("invalid list filter", logger.Err(err))
+				h.error(w, r, http.StatusBadRequest, err)
+			default:
+				log.Error("failed to list messages", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("messages are listed", slog.Int("count", len(page.Messages)))
+
+		h.respond(w, http.StatusOK, h.DTOs.ListMessagesResp(page))
+	}
 }
 
-func (h *MessageHandler) respond(w http.ResponseWriter, code int, data interface{}) {
-	var jsonData []byte
-	var err error
+// SearchMessages godoc
+//
+//	@Summary		Search messages
+//	@Description	full-text search over message content, results are ranked by relevance
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			q		query		string	true	"Search query in the web search syntax: quoted phrases, or, -excluded"
+//	@Param			limit	query		int		false	"Page size, 20 by default, 100 at most"
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{object}	dto.SearchMessagesResp
+//	@Failure		400		{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429		{object}	dto.Problem
+//	@Failure		500		{object}	dto.Problem
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/search [get]
+func (h *MessageHandler) SearchMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "search messages", r.Context())
 
-	if data != nil {
-		jsonData, err = json.Marshal(data)
+		var searchReq dto.SearchMessagesReq
+		if err := searchReq.FromQuery(r.URL.Query()); err != nil {
+			log.Warn("failed to parse query", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
 
+		page, err := h.uc.SearchMessages(r.Context(), searchReq.ToDomain())
 		if err != nil {
-			h.Log.Error("json marshal error", slog.String("error", err.Error()))
+			switch {
+			case errors.Is(err, domain.ErrInvalidArgument):
+				log.Warn("invalid search query", logger.Err(err))
+				h.error(w, r, http.StatusBadRequest, err)
+			default:
+				log.Error("failed to search messages", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("messages are found", slog.Int("count", len(page.Hits)))
+
+		h.respond(w, http.StatusOK, h.DTOs.SearchMessagesResp(page))
+	}
+}
+
+// GetStats godoc
+//
+//	@Summary		Get messages stats
+//	@Description	get messages stats, optionally in a time window and with a time series
+//	@Tags			messages
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			from	query		string	false	"Window start, inclusive, RFC 3339"
+//	@Param			to		query		string	false	"Window end, exclusive, RFC 3339"
+//	@Param			bucket	query		string	false	"Series bucket"	Enums(minute, hour, day)
+//	@Param			label	query		[]string	false	"Filter by label key:value, all labels must match"	collectionFormat(multi)
+//	@Param			group_by_label	query	string	false	"Label key to group stats by"
+//	@Success		200		{object}	dto.GetStatsResp
+//	@Failure		400		{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429		{object}	dto.Problem
+//	@Failure		500		{object}	dto.Problem
+//	@Failure		500
+//
+// @Header       all              {string}  X-RateLimit-Limit    "Request limit for the time window of the client tier"
+// @Header       all              {string}  X-RateLimit-Remaining    "The number of requests left for the time window"
+// @Header       all              {string}  X-RateLimit-Reset    "The remaining window before the rate limit resets in UTC epoch seconds"
+// @Header       429              {integer}  Retry-After    "Seconds to wait before retrying the request"
+//
+//	@Router			/v1/messages/stats [get]
+func (h *MessageHandler) GetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "get stats", r.Context())
+
+		var statsReq dto.GetStatsReq
+		if err := statsReq.FromQuery(r.URL.Query()); err != nil {
+			log.Warn("failed to parse query", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
 
-			w.WriteHeader(http.StatusInternalServerError)
+		stats, err := h.uc.GetStats(r.Context(), statsReq.ToDomain())
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidArgument):
+				log.Warn("invalid stats filter", logger.Err(err))
+				h.error(w, r, http.StatusBadRequest, err)
+			default:
+				log.Error("failed to get stats", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
+		log.Info("stats is gotten", slog.Any("stats", stats))
+
+		h.respond(w, http.StatusOK, h.DTOs.GetStatsResp(stats))
 	}
+}
+
+// limitBody limits the request body to MaxBodySize, so it is not read into memory in full
+// by Idempotent or the decoder.
+func (h *MessageHandler) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
 
-	w.WriteHeader(code)
-	_, err = w.Write(jsonData)
-	if err != nil {
-		h.Log.Error("response write json data error", slog.String("error", err.Error()))
+// bodyErrorStatus returns 413 if the body is larger than the limit and 400 otherwise.
+func bodyErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func (h *MessageHandler) Limit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.error(w, r, http.StatusTooManyRequests, domain.ErrRateLimited)
 	}
 }
@@ -9,13 +9,34 @@ import (
 	"github.com/stretchr/testify/require"
 	"messagio_assignment/internal/domain"
 	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
 	"messagio_assignment/internal/ports/rest/dto"
 	"messagio_assignment/internal/ports/rest/mocks"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"testing"
+	"time"
 )
 
+// contentOpts expects problem+json for errors and json otherwise.
+func contentOpts(isError bool) httpexpect.ContentOpts {
+	if isError {
+		return httpexpect.ContentOpts{MediaType: ProblemContentType}
+	}
+	return httpexpect.ContentOpts{MediaType: "application/json"}
+}
+
+// assertProblem checks the problem+json members, instance is the request path.
+func assertProblem(obj *httpexpect.Object, status int) {
+	obj.Keys().ContainsAll("type", "title", "status", "instance", "code")
+	obj.Keys().NotContainsAny("error")
+	obj.Value("status").Number().IsEqual(status)
+	obj.Value("code").String().NotEmpty()
+	obj.Value("type").String().HasPrefix(problemTypePrefix)
+	obj.Value("instance").String().HasPrefix("/messages")
+}
+
 func TestNewMessageHandler(t *testing.T) {
 	t.Run("logger is not nil", func(t *testing.T) {
 		mh := NewMessageHandler(nil, nil, nil, MessageHandlerConfig{})
@@ -24,6 +45,8 @@ func TestNewMessageHandler(t *testing.T) {
 }
 
 func TestMessageHandler_CreateMessage(t *testing.T) {
+	expiresAt := time.Date(2030, 8, 14, 12, 0, 0, 0, time.UTC)
+
 	t.Run("json requests", func(t *testing.T) {
 		tcases := []struct {
 			Name            string
@@ -35,18 +58,48 @@ func TestMessageHandler_CreateMessage(t *testing.T) {
 			{
 				Name: "successful",
 				Message: message.Message{
-					ID:        0,
+					ID:      0,
+					Content: "some content",
+				},
+				ExpectedStatus:  http.StatusCreated,
+				IsErrorExpected: false,
+				UcMockInit: func(uc *mocks.MessageUsecase) {
+					uc.On("CreateMessage", mock.Anything,
+						&message.Message{
+							ID:      0,
+							Content: "some content",
+						}).Return(nil).Once()
+				},
+			},
+			{
+				Name: "with labels",
+				Message: message.Message{
+					Content: "some content",
+					Labels:  message.Labels{"source": "crm"},
+				},
+				ExpectedStatus:  http.StatusCreated,
+				IsErrorExpected: false,
+				UcMockInit: func(uc *mocks.MessageUsecase) {
+					uc.On("CreateMessage", mock.Anything,
+						&message.Message{
+							Content: "some content",
+							Labels:  message.Labels{"source": "crm"},
+						}).Return(nil).Once()
+				},
+			},
+			{
+				Name: "with expires_at",
+				Message: message.Message{
 					Content:   "some content",
-					Processed: true,
+					ExpiresAt: &expiresAt,
 				},
 				ExpectedStatus:  http.StatusCreated,
 				IsErrorExpected: false,
 				UcMockInit: func(uc *mocks.MessageUsecase) {
 					uc.On("CreateMessage", mock.Anything,
 						&message.Message{
-							ID:        0,
 							Content:   "some content",
-							Processed: true,
+							ExpiresAt: &expiresAt,
 						}).Return(nil).Once()
 				},
 			},
@@ -88,18 +141,17 @@ func TestMessageHandler_CreateMessage(t *testing.T) {
 
 				msgReq := dto.CreateMessageReq{
 					Content:   tc.Message.Content,
-					Processed: tc.Message.Processed,
+					Labels:    tc.Message.Labels,
+					ExpiresAt: tc.Message.ExpiresAt,
 				}
 
 				obj := e.POST("/messages").WithJSON(msgReq).
 					Expect().
 					Status(tc.ExpectedStatus).
-					HasContentType("application/json").
-					JSON().Object()
+					JSON(contentOpts(tc.IsErrorExpected)).Object()
 
 				if tc.IsErrorExpected {
-					obj.Keys().ContainsOnly("error")
-					obj.Value("error").String().NotEmpty()
+					assertProblem(obj, tc.ExpectedStatus)
 					return
 				}
 
@@ -108,7 +160,9 @@ func TestMessageHandler_CreateMessage(t *testing.T) {
 				wantResp := dto.CreateMessageResp{
 					ID:        tc.Message.ID,
 					Content:   tc.Message.Content,
-					Processed: tc.Message.Processed,
+					Status:    tc.Message.Status.String(),
+					Labels:    tc.Message.Labels,
+					ExpiresAt: tc.Message.ExpiresAt,
 				}
 
 				var gotResp dto.CreateMessageResp
@@ -132,49 +186,126 @@ func TestMessageHandler_CreateMessage(t *testing.T) {
 
 		obj := e.POST("/messages").WithBytes([]byte("some random")).
 			Expect().
-			HasContentType("application/json").
 			Status(http.StatusBadRequest).
-			JSON().Object()
+			JSON(contentOpts(true)).Object()
+
+		assertProblem(obj, http.StatusBadRequest)
+		obj.Value("code").IsEqual(domain.CodeBadRequest)
+	})
+
+	t.Run("with ttl", func(t *testing.T) {
+		uc := mocks.NewMessageUsecase(t)
+		router := chi.NewRouter()
+		mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+		mh.SetupRoutes(router)
+
+		server := httptest.NewServer(mh)
+		defer server.Close()
+
+		e := httpexpect.Default(t, server.URL)
+
+		before := time.Now()
+		uc.On("CreateMessage", mock.Anything, mock.MatchedBy(func(msg *message.Message) bool {
+			return msg.ExpiresAt != nil && !msg.ExpiresAt.Before(before.Add(time.Hour)) &&
+				msg.ExpiresAt.Before(time.Now().Add(time.Hour))
+		})).Return(nil).Once()
+
+		e.POST("/messages").WithJSON(dto.CreateMessageReq{Content: "some content", TTL: "1h"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			ContainsKey("expires_at")
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		uc := mocks.NewMessageUsecase(t)
+		router := chi.NewRouter()
+		mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+		mh.SetupRoutes(router)
+
+		server := httptest.NewServer(mh)
+		defer server.Close()
+
+		e := httpexpect.Default(t, server.URL)
+
+		for _, msgReq := range []dto.CreateMessageReq{
+			{Content: "some content", TTL: "soon"},
+			{Content: "some content", TTL: "-1h"},
+			{Content: "some content", TTL: "1h", ExpiresAt: &expiresAt},
+		} {
+			obj := e.POST("/messages").WithJSON(msgReq).
+				Expect().
+				Status(http.StatusUnprocessableEntity).
+				JSON(contentOpts(true)).Object()
 
-		obj.Keys().ContainsOnly("error")
-		obj.Value("error").String().NotEmpty()
+			assertProblem(obj, http.StatusUnprocessableEntity)
+			obj.Value("fields").Array().Value(0).Object().HasValue("field", "ttl")
+		}
 	})
 }
 
-func TestMessageHandler_GetStats(t *testing.T) {
+func TestMessageHandler_GetMessage(t *testing.T) {
+	createdAt := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	processedAt := createdAt.Add(1500 * time.Millisecond)
+
 	tcases := []struct {
 		Name            string
-		ExpectedStats   message.Stats
+		ID              string
+		ExpectedMessage message.Message
 		ExpectedStatus  int
 		IsErrorExpected bool
 		UcMockInit      func(uc *mocks.MessageUsecase)
 	}{
 		{
 			Name: "successful",
-			ExpectedStats: message.Stats{
-				All:       42,
-				Processed: 21,
+			ID:   "42",
+			ExpectedMessage: message.Message{
+				ID:          42,
+				Content:     "some content",
+				Status:      message.StatusProcessed,
+				CreatedAt:   createdAt,
+				ProcessedAt: &processedAt,
 			},
 			ExpectedStatus:  http.StatusOK,
 			IsErrorExpected: false,
 			UcMockInit: func(uc *mocks.MessageUsecase) {
-				uc.On("GetStats", mock.Anything).
-					Return(&message.Stats{
-						All:       42,
-						Processed: 21,
+				uc.On("GetMessage", mock.Anything, 42).
+					Return(&message.Message{
+						ID:          42,
+						Content:     "some content",
+						Status:      message.StatusProcessed,
+						CreatedAt:   createdAt,
+						ProcessedAt: &processedAt,
 					}, nil).Once()
 			},
 		},
+		{
+			Name:            "not found",
+			ID:              "43",
+			ExpectedStatus:  http.StatusNotFound,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetMessage", mock.Anything, 43).
+					Return(nil, &message.ErrorWithID{ID: 43, Err: domain.ErrNotFound}).Once()
+			},
+		},
 		{
 			Name:            "some db error",
-			ExpectedStats:   message.Stats{},
+			ID:              "44",
 			ExpectedStatus:  http.StatusInternalServerError,
 			IsErrorExpected: true,
 			UcMockInit: func(uc *mocks.MessageUsecase) {
-				uc.On("GetStats", mock.Anything).
+				uc.On("GetMessage", mock.Anything, 44).
 					Return(nil, errors.New("db error")).Once()
 			},
 		},
+		{
+			Name:            "invalid id",
+			ID:              "not-a-number",
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
 	}
 
 	uc := mocks.NewMessageUsecase(t)
@@ -191,26 +322,27 @@ func TestMessageHandler_GetStats(t *testing.T) {
 		t.Run(tc.Name, func(t *testing.T) {
 			tc.UcMockInit(uc)
 
-			obj := e.GET("/messages/stats").
+			obj := e.GET("/messages/{id}", tc.ID).
 				Expect().
 				Status(tc.ExpectedStatus).
-				HasContentType("application/json").
-				JSON().Object()
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
 
 			if tc.IsErrorExpected {
-				obj.Keys().ContainsOnly("error")
-				obj.Value("error").String().NotEmpty()
+				assertProblem(obj, tc.ExpectedStatus)
 				return
 			}
 
 			obj.Keys().NotContainsAny("error")
 
-			wantResp := dto.GetStatsResp{
-				All:       tc.ExpectedStats.All,
-				Processed: tc.ExpectedStats.Processed,
+			wantResp := dto.GetMessageResp{
+				ID:          tc.ExpectedMessage.ID,
+				Content:     tc.ExpectedMessage.Content,
+				Status:      tc.ExpectedMessage.Status.String(),
+				CreatedAt:   tc.ExpectedMessage.CreatedAt,
+				ProcessedAt: tc.ExpectedMessage.ProcessedAt,
 			}
 
-			var gotResp dto.GetStatsResp
+			var gotResp dto.GetMessageResp
 			obj.Decode(&gotResp)
 
 			assert.Equal(t, wantResp, gotResp)
@@ -218,18 +350,69 @@ func TestMessageHandler_GetStats(t *testing.T) {
 	}
 }
 
-func TestMessageHandler_Limit(t *testing.T) {
-	var (
-		successfulRequests = 6
-		limitedRequests    = 4
-	)
+func TestMessageHandler_CancelMessage(t *testing.T) {
+	sendAt := time.Date(2024, 8, 13, 12, 0, 0, 0, time.UTC)
+
+	tcases := []struct {
+		Name            string
+		ID              string
+		ExpectedMessage message.Message
+		ExpectedStatus  int
+		IsErrorExpected bool
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name: "successful",
+			ID:   "42",
+			ExpectedMessage: message.Message{
+				ID:      42,
+				Content: "later",
+				Status:  message.StatusCancelled,
+				SendAt:  &sendAt,
+			},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CancelMessage", mock.Anything, 42).
+					Return(&message.Message{
+						ID:      42,
+						Content: "later",
+						Status:  message.StatusCancelled,
+						SendAt:  &sendAt,
+					}, nil).Once()
+			},
+		},
+		{
+			Name:            "not found",
+			ID:              "43",
+			ExpectedStatus:  http.StatusNotFound,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CancelMessage", mock.Anything, 43).
+					Return(nil, &message.ErrorWithID{ID: 43, Err: domain.ErrNotFound}).Once()
+			},
+		},
+		{
+			Name:            "already sent",
+			ID:              "44",
+			ExpectedStatus:  http.StatusConflict,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CancelMessage", mock.Anything, 44).
+					Return(nil, &message.ErrorWithID{ID: 44, Err: domain.ErrConflict}).Once()
+			},
+		},
+		{
+			Name:            "invalid id",
+			ID:              "not-a-number",
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+	}
 
 	uc := mocks.NewMessageUsecase(t)
 	router := chi.NewRouter()
-	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
-		CreateMsgPerMinute: successfulRequests,
-		GetStatsPerMinute:  successfulRequests,
-	})
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
 	mh.SetupRoutes(router)
 
 	server := httptest.NewServer(mh)
@@ -237,76 +420,915 @@ func TestMessageHandler_Limit(t *testing.T) {
 
 	e := httpexpect.Default(t, server.URL)
 
-	t.Run("create message", func(t *testing.T) {
-		var (
-			msg           = &message.Message{Content: "some content"}
-			createdStatus = http.StatusCreated
-			limitStatus   = http.StatusTooManyRequests
-		)
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
 
-		for range successfulRequests {
-			uc.On("CreateMessage", mock.Anything,
-				msg).Return(nil).Once()
-		}
+			obj := e.POST("/messages/{id}/cancel", tc.ID).
+				Expect().
+				Status(tc.ExpectedStatus).
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
 
-		msgReq := dto.CreateMessageReq{
-			Content:   msg.Content,
-			Processed: msg.Processed,
-		}
+			if tc.IsErrorExpected {
+				assertProblem(obj, tc.ExpectedStatus)
+				return
+			}
 
-		for range successfulRequests {
-			obj := e.POST("/messages").WithJSON(msgReq).
-				Expect().
-				Status(createdStatus).
-				HasContentType("application/json").
-				JSON().Object()
+			var wantResp dto.GetMessageResp
+			wantResp.FromDomain(&tc.ExpectedMessage)
 
-			obj.Keys().NotContainsAny("error")
-		}
+			var gotResp dto.GetMessageResp
+			obj.Decode(&gotResp)
 
-		for range limitedRequests {
-			obj := e.POST("/messages").WithJSON(msgReq).
-				Expect().
-				Status(limitStatus).
-				HasContentType("application/json").
-				JSON().Object()
+			assert.Equal(t, wantResp, gotResp)
+		})
+	}
+}
 
-			obj.Keys().ContainsOnly("error")
-			obj.Value("error").String().NotEmpty()
-		}
-	})
+func TestMessageHandler_DeleteMessage(t *testing.T) {
+	tcases := []struct {
+		Name            string
+		ID              string
+		ExpectedStatus  int
+		IsErrorExpected bool
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name:           "successful",
+			ID:             "42",
+			ExpectedStatus: http.StatusNoContent,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("DeleteMessage", mock.Anything, 42).Return(nil).Once()
+			},
+		},
+		{
+			Name:            "not found",
+			ID:              "43",
+			ExpectedStatus:  http.StatusNotFound,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("DeleteMessage", mock.Anything, 43).
+					Return(&message.ErrorWithID{ID: 43, Err: domain.ErrNotFound}).Once()
+			},
+		},
+		{
+			Name:            "some db error",
+			ID:              "44",
+			ExpectedStatus:  http.StatusInternalServerError,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("DeleteMessage", mock.Anything, 44).
+					Return(errors.New("db error")).Once()
+			},
+		},
+		{
+			Name:            "invalid id",
+			ID:              "not-a-number",
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+	}
 
-	t.Run("get stats", func(t *testing.T) {
-		var (
-			stats       = &message.Stats{}
-			getStatus   = http.StatusOK
-			limitStatus = http.StatusTooManyRequests
-		)
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
 
-		for range successfulRequests {
-			uc.On("GetStats", mock.Anything).
-				Return(stats, nil).Once()
-		}
+	server := httptest.NewServer(mh)
+	defer server.Close()
 
-		for range successfulRequests {
-			obj := e.GET("/messages/stats").
-				Expect().
-				Status(getStatus).
-				HasContentType("application/json").
-				JSON().Object()
+	e := httpexpect.Default(t, server.URL)
 
-			obj.Keys().NotContainsAny("error")
-		}
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
 
-		for range limitedRequests {
-			obj := e.GET("/messages/stats").
+			resp := e.DELETE("/messages/{id}", tc.ID).
 				Expect().
-				Status(limitStatus).
-				HasContentType("application/json").
-				JSON().Object()
+				Status(tc.ExpectedStatus)
 
-			obj.Keys().ContainsOnly("error")
-			obj.Value("error").String().NotEmpty()
-		}
+			if !tc.IsErrorExpected {
+				resp.NoContent()
+				return
+			}
+
+			obj := resp.JSON(contentOpts(true)).Object()
+			assertProblem(obj, tc.ExpectedStatus)
+		})
+	}
+}
+
+func TestMessageHandler_ListMessages(t *testing.T) {
+	status := message.StatusProcessed
+
+	tcases := []struct {
+		Name            string
+		Query           map[string]any
+		ExpectedPage    message.Page
+		ExpectedStatus  int
+		IsErrorExpected bool
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name:  "successful with next page",
+			Query: map[string]any{"status": "processed", "limit": 2, "cursor": domain.Cursor{AfterID: 10}.Encode()},
+			ExpectedPage: message.Page{
+				Messages: []*message.Message{
+					{ID: 11, Content: "first", Status: message.StatusProcessed},
+					{ID: 15, Content: "second", Status: message.StatusProcessed},
+				},
+				NextAfterID: 15,
+			},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("ListMessages", mock.Anything, message.ListFilter{
+					Status:  &status,
+					AfterID: 10,
+					Limit:   2,
+				}).Return(&message.Page{
+					Messages: []*message.Message{
+						{ID: 11, Content: "first", Status: message.StatusProcessed},
+						{ID: 15, Content: "second", Status: message.StatusProcessed},
+					},
+					NextAfterID: 15,
+				}, nil).Once()
+			},
+		},
+		{
+			Name:           "successful last page",
+			Query:          map[string]any{},
+			ExpectedPage:   message.Page{Messages: []*message.Message{{ID: 1, Content: "only"}}},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("ListMessages", mock.Anything, message.ListFilter{}).
+					Return(&message.Page{Messages: []*message.Message{{ID: 1, Content: "only"}}}, nil).Once()
+			},
+		},
+		{
+			Name:  "successful with label",
+			Query: map[string]any{"label": "source:crm"},
+			ExpectedPage: message.Page{Messages: []*message.Message{
+				{ID: 3, Content: "tagged", Labels: message.Labels{"source": "crm", "customer": "42"}},
+			}},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("ListMessages", mock.Anything, message.ListFilter{Labels: message.Labels{"source": "crm"}}).
+					Return(&message.Page{Messages: []*message.Message{
+						{ID: 3, Content: "tagged", Labels: message.Labels{"source": "crm", "customer": "42"}},
+					}}, nil).Once()
+			},
+		},
+		{
+			Name:            "invalid label",
+			Query:           map[string]any{"label": "source"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "invalid filter",
+			Query:           map[string]any{"min_id": "abc"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "invalid created time",
+			Query:           map[string]any{"created_from": "yesterday"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "invalid cursor",
+			Query:           map[string]any{"cursor": "not a cursor"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "filter rejected by usecase",
+			Query:           map[string]any{"min_id": 10, "max_id": 5},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("ListMessages", mock.Anything, mock.Anything).
+					Return(nil, &message.Error{Err: domain.ErrInvalidArgument}).Once()
+			},
+		},
+		{
+			Name:            "some db error",
+			Query:           map[string]any{},
+			ExpectedStatus:  http.StatusInternalServerError,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("ListMessages", mock.Anything, message.ListFilter{}).
+					Return(nil, errors.New("db error")).Once()
+			},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
+
+			req := e.GET("/messages")
+			for k, v := range tc.Query {
+				req = req.WithQuery(k, v)
+			}
+
+			obj := req.Expect().
+				Status(tc.ExpectedStatus).
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
+
+			if tc.IsErrorExpected {
+				assertProblem(obj, tc.ExpectedStatus)
+				return
+			}
+
+			obj.Keys().NotContainsAny("error")
+
+			var wantResp dto.ListMessagesResp
+			wantResp.FromDomain(&tc.ExpectedPage)
+
+			var gotResp dto.ListMessagesResp
+			obj.Decode(&gotResp)
+
+			assert.Equal(t, wantResp, gotResp)
+
+			if tc.ExpectedPage.NextAfterID != 0 {
+				cursor, err := domain.DecodeCursor(gotResp.NextCursor)
+				require.NoError(t, err)
+				assert.Equal(t, tc.ExpectedPage.NextAfterID, cursor.AfterID)
+			}
+		})
+	}
+}
+
+func TestMessageHandler_SearchMessages(t *testing.T) {
+	tcases := []struct {
+		Name            string
+		Query           map[string]any
+		ExpectedPage    message.SearchPage
+		ExpectedStatus  int
+		IsErrorExpected bool
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name:  "successful with next page",
+			Query: map[string]any{"q": "hello", "limit": 1, "cursor": domain.Cursor{Offset: 3}.Encode()},
+			ExpectedPage: message.SearchPage{
+				Hits: []*message.SearchHit{
+					{Message: &message.Message{ID: 7, Content: "hello world"}, Rank: 0.1, Highlight: "<mark>hello</mark> world"},
+				},
+				NextOffset: 4,
+			},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("SearchMessages", mock.Anything, message.SearchQuery{
+					Query:  "hello",
+					Offset: 3,
+					Limit:  1,
+				}).Return(&message.SearchPage{
+					Hits: []*message.SearchHit{
+						{Message: &message.Message{ID: 7, Content: "hello world"}, Rank: 0.1, Highlight: "<mark>hello</mark> world"},
+					},
+					NextOffset: 4,
+				}, nil).Once()
+			},
+		},
+		{
+			Name:           "nothing found",
+			Query:          map[string]any{"q": "absent"},
+			ExpectedPage:   message.SearchPage{Hits: []*message.SearchHit{}},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("SearchMessages", mock.Anything, message.SearchQuery{Query: "absent"}).
+					Return(&message.SearchPage{Hits: []*message.SearchHit{}}, nil).Once()
+			},
+		},
+		{
+			Name:            "empty query",
+			Query:           map[string]any{},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("SearchMessages", mock.Anything, message.SearchQuery{}).
+					Return(nil, &message.Error{Err: domain.ErrInvalidArgument}).Once()
+			},
+		},
+		{
+			Name:            "invalid cursor",
+			Query:           map[string]any{"q": "hello", "cursor": "not a cursor"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "some db error",
+			Query:           map[string]any{"q": "hello"},
+			ExpectedStatus:  http.StatusInternalServerError,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("SearchMessages", mock.Anything, message.SearchQuery{Query: "hello"}).
+					Return(nil, errors.New("db error")).Once()
+			},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
+
+			req := e.GET("/messages/search")
+			for k, v := range tc.Query {
+				req = req.WithQuery(k, v)
+			}
+
+			obj := req.Expect().
+				Status(tc.ExpectedStatus).
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
+
+			if tc.IsErrorExpected {
+				assertProblem(obj, tc.ExpectedStatus)
+				return
+			}
+
+			obj.Keys().NotContainsAny("error")
+
+			var wantResp dto.SearchMessagesResp
+			wantResp.FromDomain(&tc.ExpectedPage)
+
+			var gotResp dto.SearchMessagesResp
+			obj.Decode(&gotResp)
+
+			assert.Equal(t, wantResp, gotResp)
+
+			if tc.ExpectedPage.NextOffset != 0 {
+				cursor, err := domain.DecodeCursor(gotResp.NextCursor)
+				require.NoError(t, err)
+				assert.Equal(t, tc.ExpectedPage.NextOffset, cursor.Offset)
+			}
+		})
+	}
+}
+
+func TestMessageHandler_GetStats(t *testing.T) {
+	from := time.Date(2024, 8, 7, 12, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Minute)
+
+	tcases := []struct {
+		Name            string
+		Query           map[string]any
+		ExpectedStats   message.Stats
+		ExpectedStatus  int
+		IsErrorExpected bool
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name: "successful",
+			ExpectedStats: message.Stats{
+				All:           42,
+				Processed:     21,
+				OutboxPending: 3,
+				ByStatus: map[message.Status]int{
+					message.StatusQueued:    21,
+					message.StatusProcessed: 21,
+				},
+			},
+			ExpectedStatus:  http.StatusOK,
+			IsErrorExpected: false,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetStats", mock.Anything, message.StatsFilter{}).
+					Return(&message.Stats{
+						All:           42,
+						Processed:     21,
+						OutboxPending: 3,
+						ByStatus: map[message.Status]int{
+							message.StatusQueued:    21,
+							message.StatusProcessed: 21,
+						},
+					}, nil).Once()
+			},
+		},
+		{
+			Name:  "successful with series",
+			Query: map[string]any{"from": from.Format(time.RFC3339), "to": to.Format(time.RFC3339), "bucket": "minute"},
+			ExpectedStats: message.Stats{
+				All:       3,
+				Processed: 1,
+				Series: []message.StatsBucket{
+					{Start: from, Created: 2, Processed: 1, Latency: &message.Latency{
+						P50: 1500 * time.Millisecond, P95: 2 * time.Second, P99: 2 * time.Second,
+					}},
+					{Start: from.Add(time.Minute), Created: 1},
+				},
+			},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetStats", mock.Anything, message.StatsFilter{
+					From:   &from,
+					To:     &to,
+					Bucket: message.BucketMinute,
+				}).Return(&message.Stats{
+					All:       3,
+					Processed: 1,
+					Series: []message.StatsBucket{
+						{Start: from, Created: 2, Processed: 1, Latency: &message.Latency{
+							P50: 1500 * time.Millisecond, P95: 2 * time.Second, P99: 2 * time.Second,
+						}},
+						{Start: from.Add(time.Minute), Created: 1},
+					},
+				}, nil).Once()
+			},
+		},
+		{
+			Name:  "successful by label",
+			Query: map[string]any{"label": "source:crm", "group_by_label": "customer"},
+			ExpectedStats: message.Stats{
+				All:       3,
+				Processed: 2,
+				ByLabel: []message.LabelStats{
+					{Value: "", All: 1, Processed: 0},
+					{Value: "42", All: 2, Processed: 2},
+				},
+			},
+			ExpectedStatus: http.StatusOK,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetStats", mock.Anything, message.StatsFilter{
+					Labels:       message.Labels{"source": "crm"},
+					GroupByLabel: "customer",
+				}).Return(&message.Stats{
+					All:       3,
+					Processed: 2,
+					ByLabel: []message.LabelStats{
+						{Value: "", All: 1, Processed: 0},
+						{Value: "42", All: 2, Processed: 2},
+					},
+				}, nil).Once()
+			},
+		},
+		{
+			Name:            "unknown bucket",
+			Query:           map[string]any{"bucket": "week"},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "invalid window",
+			Query:           map[string]any{"from": to.Format(time.RFC3339), "to": from.Format(time.RFC3339)},
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetStats", mock.Anything, message.StatsFilter{From: &to, To: &from}).
+					Return(nil, &message.StatsError{Err: domain.ErrInvalidArgument}).Once()
+			},
+		},
+		{
+			Name:            "some db error",
+			ExpectedStats:   message.Stats{},
+			ExpectedStatus:  http.StatusInternalServerError,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("GetStats", mock.Anything, message.StatsFilter{}).
+					Return(nil, errors.New("db error")).Once()
+			},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
+
+			req := e.GET("/messages/stats")
+			for k, v := range tc.Query {
+				req = req.WithQuery(k, v)
+			}
+
+			obj := req.Expect().
+				Status(tc.ExpectedStatus).
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
+
+			if tc.IsErrorExpected {
+				assertProblem(obj, tc.ExpectedStatus)
+				return
+			}
+
+			obj.Keys().NotContainsAny("error")
+
+			wantResp := dto.GetStatsResp{
+				All:           tc.ExpectedStats.All,
+				Processed:     tc.ExpectedStats.Processed,
+				OutboxPending: tc.ExpectedStats.OutboxPending,
+				ByStatus:      make(map[string]int),
+			}
+			for status, count := range tc.ExpectedStats.ByStatus {
+				wantResp.ByStatus[status.String()] = count
+			}
+			for _, b := range tc.ExpectedStats.Series {
+				var bucketResp dto.StatsBucketResp
+				bucketResp.FromDomain(&b)
+				wantResp.Series = append(wantResp.Series, bucketResp)
+			}
+			for _, g := range tc.ExpectedStats.ByLabel {
+				var groupResp dto.LabelStatsResp
+				groupResp.FromDomain(&g)
+				wantResp.ByLabel = append(wantResp.ByLabel, groupResp)
+			}
+
+			var gotResp dto.GetStatsResp
+			obj.Decode(&gotResp)
+
+			assert.Equal(t, wantResp, gotResp)
+		})
+	}
+}
+
+func TestMessageHandler_Limit(t *testing.T) {
+	var (
+		successfulRequests = 6
+		limitedRequests    = 4
+	)
+
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
+		RateLimit: limiter.Config{
+			DefaultTier: "default",
+			Tiers: map[string]limiter.Tier{"default": {
+				limiter.RouteCreateMsg: {PerMinute: successfulRequests},
+				limiter.RouteGetStats:  {PerMinute: successfulRequests},
+			}},
+		},
+	})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	t.Run("create message", func(t *testing.T) {
+		var (
+			msg           = &message.Message{Content: "some content"}
+			createdStatus = http.StatusCreated
+			limitStatus   = http.StatusTooManyRequests
+		)
+
+		for range successfulRequests {
+			uc.On("CreateMessage", mock.Anything,
+				msg).Return(nil).Once()
+		}
+
+		msgReq := dto.CreateMessageReq{
+			Content: msg.Content,
+		}
+
+		for range successfulRequests {
+			obj := e.POST("/messages").WithJSON(msgReq).
+				Expect().
+				Status(createdStatus).
+				HasContentType("application/json").
+				JSON().Object()
+
+			obj.Keys().NotContainsAny("error")
+		}
+
+		for range limitedRequests {
+			resp := e.POST("/messages").WithJSON(msgReq).
+				Expect().
+				Status(limitStatus)
+
+			resp.Header("Retry-After").AsNumber().InRange(6, 66)
+			obj := resp.JSON(contentOpts(true)).Object()
+
+			assertProblem(obj, limitStatus)
+			obj.Value("code").IsEqual(domain.CodeRateLimited)
+		}
+	})
+
+	t.Run("get stats", func(t *testing.T) {
+		var (
+			stats       = &message.Stats{}
+			getStatus   = http.StatusOK
+			limitStatus = http.StatusTooManyRequests
+		)
+
+		for range successfulRequests {
+			uc.On("GetStats", mock.Anything, message.StatsFilter{}).
+				Return(stats, nil).Once()
+		}
+
+		for range successfulRequests {
+			obj := e.GET("/messages/stats").
+				Expect().
+				Status(getStatus).
+				HasContentType("application/json").
+				JSON().Object()
+
+			obj.Keys().NotContainsAny("error")
+		}
+
+		for range limitedRequests {
+			resp := e.GET("/messages/stats").
+				Expect().
+				Status(limitStatus)
+
+			resp.Header("Retry-After").AsNumber().InRange(6, 66)
+			obj := resp.JSON(contentOpts(true)).Object()
+
+			assertProblem(obj, limitStatus)
+			obj.Value("code").IsEqual(domain.CodeRateLimited)
+		}
+	})
+}
+
+func TestMessageHandler_CreateMessages(t *testing.T) {
+	// the texts of decoding errors are not exposed
+	invalidItem := dto.CreateMessagesItemResp{
+		Code:  string(domain.CodeInvalidArgument),
+		Error: domain.CodeInvalidArgument.Detail(),
+	}
+
+	tcases := []struct {
+		Name            string
+		Body            string
+		ExpectedStatus  int
+		IsErrorExpected bool
+		ExpectedResp    dto.CreateMessagesResp
+		UcMockInit      func(uc *mocks.MessageUsecase)
+	}{
+		{
+			Name:           "successful",
+			Body:           `[{"content": "first"}, {"content": "second"}]`,
+			ExpectedStatus: http.StatusCreated,
+			ExpectedResp: dto.CreateMessagesResp{
+				Created: 2,
+				Items:   []dto.CreateMessagesItemResp{{ID: 1}, {ID: 2}},
+			},
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CreateMessages", mock.Anything, []*message.Message{
+					{Content: "first"}, {Content: "second"},
+				}).Run(func(args mock.Arguments) {
+					for i, msg := range args.Get(1).([]*message.Message) {
+						msg.ID = i + 1
+					}
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Name:           "partially malformed",
+			Body:           `[{"content": "first"}, "not an object", {"content": 42}, {"content": "last"}]`,
+			ExpectedStatus: http.StatusMultiStatus,
+			ExpectedResp: dto.CreateMessagesResp{
+				Created: 2,
+				Failed:  2,
+				Items: []dto.CreateMessagesItemResp{
+					{ID: 10}, invalidItem, invalidItem, {ID: 11},
+				},
+			},
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CreateMessages", mock.Anything, []*message.Message{
+					{Content: "first"}, {Content: "last"},
+				}).Run(func(args mock.Arguments) {
+					for i, msg := range args.Get(1).([]*message.Message) {
+						msg.ID = i + 10
+					}
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Name:           "invalid labels",
+			Body:           `[{"content": "first", "labels": {"source": "crm"}}, {"content": "second", "labels": {"": "x"}}]`,
+			ExpectedStatus: http.StatusMultiStatus,
+			ExpectedResp: dto.CreateMessagesResp{
+				Created: 1,
+				Failed:  1,
+				Items: []dto.CreateMessagesItemResp{{ID: 12}, {
+					Code:   string(domain.CodeValidation),
+					Error:  domain.CodeValidation.Detail(),
+					Fields: []dto.FieldError{{Field: "labels"}},
+				}},
+			},
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CreateMessages", mock.Anything, []*message.Message{
+					{Content: "first", Labels: message.Labels{"source": "crm"}},
+				}).Run(func(args mock.Arguments) {
+					args.Get(1).([]*message.Message)[0].ID = 12
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Name:           "all malformed",
+			Body:           `[null]`,
+			ExpectedStatus: http.StatusMultiStatus,
+			ExpectedResp: dto.CreateMessagesResp{
+				Failed: 1,
+				Items:  []dto.CreateMessagesItemResp{invalidItem},
+			},
+			UcMockInit: func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "empty batch",
+			Body:            `[]`,
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "too large batch",
+			Body:            `[{}, {}, {}, {}, {}]`,
+			ExpectedStatus:  http.StatusRequestEntityTooLarge,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "not an array",
+			Body:            `{"content": "first"}`,
+			ExpectedStatus:  http.StatusBadRequest,
+			IsErrorExpected: true,
+			UcMockInit:      func(_ *mocks.MessageUsecase) {},
+		},
+		{
+			Name:            "some db error",
+			Body:            `[{"content": "first"}]`,
+			ExpectedStatus:  http.StatusUnprocessableEntity,
+			IsErrorExpected: true,
+			UcMockInit: func(uc *mocks.MessageUsecase) {
+				uc.On("CreateMessages", mock.Anything, []*message.Message{{Content: "first"}}).
+					Return(errors.New("db error")).Once()
+			},
+		},
+	}
+
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{MaxBatchSize: 4})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.UcMockInit(uc)
+
+			obj := e.POST("/messages/batch").
+				WithHeader("Content-Type", "application/json").
+				WithBytes([]byte(tc.Body)).
+				Expect().
+				Status(tc.ExpectedStatus).
+				JSON(contentOpts(tc.IsErrorExpected)).Object()
+
+			if tc.IsErrorExpected {
+				assertProblem(obj, tc.ExpectedStatus)
+				return
+			}
+
+			var gotResp dto.CreateMessagesResp
+			obj.Decode(&gotResp)
+
+			require.Len(t, gotResp.Items, len(tc.ExpectedResp.Items))
+			for i := range tc.ExpectedResp.Items {
+				for j := range gotResp.Items[i].Fields { // only invalid fields are compared
+					assert.NotEmpty(t, gotResp.Items[i].Fields[j].Message)
+					gotResp.Items[i].Fields[j].Message = ""
+				}
+			}
+
+			assert.Equal(t, tc.ExpectedResp, gotResp)
+		})
+	}
+}
+
+func TestMessageHandler_Validation(t *testing.T) {
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
+		Validation: ValidationConfig{
+			Rules: message.ValidationRules{
+				MaxContentLength: 10,
+				RequireContent:   true,
+				RequireUTF8:      true,
+				DenyPatterns:     []*regexp.Regexp{regexp.MustCompile(`(?i)spam`)},
+			},
+			DisallowUnknownFields: true,
+		},
+	})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	t.Run("create message", func(t *testing.T) {
+		tcases := []struct {
+			Name           string
+			Body           string
+			ExpectedFields []string
+		}{
+			{Name: "empty content", Body: `{"content": "  "}`, ExpectedFields: []string{"content"}},
+			{Name: "too long content", Body: `{"content": "more than ten bytes"}`, ExpectedFields: []string{"content"}},
+			{Name: "invalid utf8", Body: "{\"content\": \"bad \xff\"}", ExpectedFields: []string{"content"}},
+			{Name: "denied content", Body: `{"content": "buy SPAM"}`, ExpectedFields: []string{"content"}},
+			{Name: "unknown field", Body: `{"content": "hi", "priority": 1}`, ExpectedFields: []string{"priority"}},
+			{
+				Name:           "invalid callback url",
+				Body:           `{"content": "hi", "callback_url": "ftp://example.com"}`,
+				ExpectedFields: []string{"callback_url"},
+			},
+			{
+				Name:           "several fields",
+				Body:           `{"content": "", "labels": {"": "x"}, "ttl": "soon"}`,
+				ExpectedFields: []string{"content", "labels", "ttl"},
+			},
+		}
+
+		for _, tc := range tcases {
+			t.Run(tc.Name, func(t *testing.T) {
+				obj := e.POST("/messages").
+					WithHeader("Content-Type", "application/json").
+					WithBytes([]byte(tc.Body)).
+					Expect().
+					Status(http.StatusUnprocessableEntity).
+					JSON(contentOpts(true)).Object()
+
+				assertProblem(obj, http.StatusUnprocessableEntity)
+				obj.Value("code").IsEqual(domain.CodeValidation)
+
+				var gotResp dto.ValidationProblem
+				obj.Decode(&gotResp)
+
+				gotFields := make([]string, len(gotResp.Fields))
+				for i, fe := range gotResp.Fields {
+					assert.NotEmpty(t, fe.Message)
+					gotFields[i] = fe.Field
+				}
+				assert.Equal(t, tc.ExpectedFields, gotFields)
+			})
+		}
+	})
+
+	t.Run("valid message", func(t *testing.T) {
+		uc.On("CreateMessage", mock.Anything, &message.Message{Content: "hello"}).
+			Return(nil).Once()
+
+		e.POST("/messages").WithJSON(dto.CreateMessageReq{Content: "hello"}).
+			Expect().
+			Status(http.StatusCreated)
+	})
+
+	t.Run("create messages", func(t *testing.T) {
+		uc.On("CreateMessages", mock.Anything, []*message.Message{{Content: "hello"}}).
+			Run(func(args mock.Arguments) {
+				args.Get(1).([]*message.Message)[0].ID = 7
+			}).Return(nil).Once()
+
+		obj := e.POST("/messages/batch").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`[{"content": "hello"}, {"content": ""}, {"content": "hi", "priority": 1}]`)).
+			Expect().
+			Status(http.StatusMultiStatus).
+			JSON().Object()
+
+		items := obj.Value("items").Array()
+		items.Value(0).Object().HasValue("id", 7)
+		items.Value(1).Object().Value("fields").Array().Value(0).Object().HasValue("field", "content")
+		items.Value(2).Object().Value("fields").Array().Value(0).Object().HasValue("field", "priority")
 	})
 }
@@ -0,0 +1,104 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	apikey "messagio_assignment/internal/domain/apikey"
+	auth "messagio_assignment/internal/domain/auth"
+
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyUsecase is an autogenerated mock type for the APIKeyUsecase type
+type APIKeyUsecase struct {
+	mock.Mock
+}
+
+// CreateKey provides a mock function with given fields: ctx, name, scopes, callbackURL
+func (_m *APIKeyUsecase) CreateKey(ctx context.Context, name string, scopes []auth.Scope, callbackURL string) (*apikey.Key, string, error) {
+	ret := _m.Called(ctx, name, scopes, callbackURL)
+
+	var r0 *apikey.Key
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []auth.Scope, string) (*apikey.Key, string, error)); ok {
+		return rf(ctx, name, scopes, callbackURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []auth.Scope, string) *apikey.Key); ok {
+		r0 = rf(ctx, name, scopes, callbackURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.Key)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []auth.Scope, string) string); ok {
+		r1 = rf(ctx, name, scopes, callbackURL)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []auth.Scope, string) error); ok {
+		r2 = rf(ctx, name, scopes, callbackURL)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListKeys provides a mock function with given fields: ctx
+func (_m *APIKeyUsecase) ListKeys(ctx context.Context) ([]*apikey.Key, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*apikey.Key
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*apikey.Key, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*apikey.Key); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*apikey.Key)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyUsecase) RevokeKey(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyUsecase creates a new instance of APIKeyUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyUsecase {
+	mock := &APIKeyUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
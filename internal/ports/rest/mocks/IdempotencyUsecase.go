@@ -0,0 +1,90 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	idempotency "messagio_assignment/internal/domain/idempotency"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IdempotencyUsecase is an autogenerated mock type for the IdempotencyUsecase type
+type IdempotencyUsecase struct {
+	mock.Mock
+}
+
+// Begin provides a mock function with given fields: ctx, key, fingerprint
+func (_m *IdempotencyUsecase) Begin(ctx context.Context, key string, fingerprint string) (string, *idempotency.Response, error) {
+	ret := _m.Called(ctx, key, fingerprint)
+
+	var r0 string
+	var r1 *idempotency.Response
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, *idempotency.Response, error)); ok {
+		return rf(ctx, key, fingerprint)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, key, fingerprint)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *idempotency.Response); ok {
+		r1 = rf(ctx, key, fingerprint)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*idempotency.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, key, fingerprint)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Complete provides a mock function with given fields: ctx, key, token, resp
+func (_m *IdempotencyUsecase) Complete(ctx context.Context, key string, token string, resp idempotency.Response) error {
+	ret := _m.Called(ctx, key, token, resp)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, idempotency.Response) error); ok {
+		r0 = rf(ctx, key, token, resp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: ctx, key, token
+func (_m *IdempotencyUsecase) Release(ctx context.Context, key string, token string) error {
+	ret := _m.Called(ctx, key, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, key, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIdempotencyUsecase creates a new instance of IdempotencyUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyUsecase {
+	mock := &IdempotencyUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
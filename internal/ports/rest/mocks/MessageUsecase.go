@@ -14,6 +14,32 @@ type MessageUsecase struct {
 	mock.Mock
 }
 
+// CancelMessage provides a mock function with given fields: ctx, id
+func (_m *MessageUsecase) CancelMessage(ctx context.Context, id int) (*message.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *message.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*message.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *message.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateMessage provides a mock function with given fields: ctx, msg
 func (_m *MessageUsecase) CreateMessage(ctx context.Context, msg *message.Message) error {
 	ret := _m.Called(ctx, msg)
@@ -28,25 +54,79 @@ func (_m *MessageUsecase) CreateMessage(ctx context.Context, msg *message.Messag
 	return r0
 }
 
-// GetStats provides a mock function with given fields: ctx
-func (_m *MessageUsecase) GetStats(ctx context.Context) (*message.Stats, error) {
-	ret := _m.Called(ctx)
+// CreateMessages provides a mock function with given fields: ctx, msgs
+func (_m *MessageUsecase) CreateMessages(ctx context.Context, msgs []*message.Message) error {
+	ret := _m.Called(ctx, msgs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*message.Message) error); ok {
+		r0 = rf(ctx, msgs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMessage provides a mock function with given fields: ctx, id
+func (_m *MessageUsecase) DeleteMessage(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetMessage provides a mock function with given fields: ctx, id
+func (_m *MessageUsecase) GetMessage(ctx context.Context, id int) (*message.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *message.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*message.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *message.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, filter
+func (_m *MessageUsecase) GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error) {
+	ret := _m.Called(ctx, filter)
 
 	var r0 *message.Stats
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (*message.Stats, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, message.StatsFilter) (*message.Stats, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) *message.Stats); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, message.StatsFilter) *message.Stats); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*message.Stats)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, message.StatsFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,18 +134,56 @@ func (_m *MessageUsecase) GetStats(ctx context.Context) (*message.Stats, error)
 	return r0, r1
 }
 
-// UpdateProcessedMessage provides a mock function with given fields: ctx, msg
-func (_m *MessageUsecase) UpdateProcessedMessage(ctx context.Context, msg *message.Message) error {
-	ret := _m.Called(ctx, msg)
+// ListMessages provides a mock function with given fields: ctx, filter
+func (_m *MessageUsecase) ListMessages(ctx context.Context, filter message.ListFilter) (*message.Page, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *message.Message) error); ok {
-		r0 = rf(ctx, msg)
+	var r0 *message.Page
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, message.ListFilter) (*message.Page, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, message.ListFilter) *message.Page); ok {
+		r0 = rf(ctx, filter)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Page)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, message.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchMessages provides a mock function with given fields: ctx, query
+func (_m *MessageUsecase) SearchMessages(ctx context.Context, query message.SearchQuery) (*message.SearchPage, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *message.SearchPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, message.SearchQuery) (*message.SearchPage, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, message.SearchQuery) *message.SearchPage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.SearchPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, message.SearchQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewMessageUsecase creates a new instance of MessageUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -0,0 +1,73 @@
+// Code generated by mockery v2.36.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	message "messagio_assignment/internal/domain/message"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StatsStreamer is an autogenerated mock type for the StatsStreamer type
+type StatsStreamer struct {
+	mock.Mock
+}
+
+// Subscribe provides a mock function with given fields: ctx
+func (_m *StatsStreamer) Subscribe(ctx context.Context) (*message.Stats, <-chan message.StatsDelta, func(), error) {
+	ret := _m.Called(ctx)
+
+	var r0 *message.Stats
+	var r1 <-chan message.StatsDelta
+	var r2 func()
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*message.Stats, <-chan message.StatsDelta, func(), error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *message.Stats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.Stats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) <-chan message.StatsDelta); ok {
+		r1 = rf(ctx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(<-chan message.StatsDelta)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context) func()); ok {
+		r2 = rf(ctx)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context) error); ok {
+		r3 = rf(ctx)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// NewStatsStreamer creates a new instance of StatsStreamer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatsStreamer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatsStreamer {
+	mock := &StatsStreamer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/dto"
+	"net/http"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:messagio:problem:"
+)
+
+var problemTitles = map[domain.Code]string{
+	domain.CodeAlreadyExists:        "Already exists",
+	domain.CodeNotCreated:           "Not created",
+	domain.CodeNotFound:             "Not found",
+	domain.CodeConflict:             "Conflict",
+	domain.CodeInvalidArgument:      "Invalid argument",
+	domain.CodeUnauthenticated:      "Unauthenticated",
+	domain.CodeForbidden:            "Forbidden",
+	domain.CodeIdempotencyKeyReused: "Idempotency key reused",
+	domain.CodeRateLimited:          "Too many requests",
+	domain.CodeBadRequest:           "Bad request",
+	domain.CodeValidation:           "Validation failed",
+	domain.CodePayloadTooLarge:      "Payload too large",
+	domain.CodeInternal:             "Internal error",
+}
+
+// errorCode returns the code of the error. Errors without a registered code are coded by the status.
+func errorCode(status int, err error) domain.Code {
+	if code, ok := domain.ErrorCode(err); ok {
+		return code
+	}
+
+	switch {
+	case status == http.StatusRequestEntityTooLarge:
+		return domain.CodePayloadTooLarge
+	case status == http.StatusTooManyRequests:
+		return domain.CodeRateLimited
+	case status >= 500:
+		return domain.CodeInternal
+	default:
+		return domain.CodeBadRequest
+	}
+}
+
+// newProblem describes the error by its code. Error texts are not exposed, they are only logged,
+// so the problem doesn't change with them and has no internal details.
+func newProblem(r *http.Request, status int, code domain.Code) dto.Problem {
+	return dto.Problem{
+		Type:      problemTypePrefix + string(code),
+		Title:     problemTitles[code],
+		Status:    status,
+		Detail:    code.Detail(),
+		Instance:  r.URL.Path,
+		Code:      string(code),
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+func (h *responder) error(w http.ResponseWriter, r *http.Request, status int, err error) {
+	h.problem(w, r, status, errorCode(status, err))
+}
+
+func (h *responder) problem(w http.ResponseWriter, r *http.Request, status int, code domain.Code) {
+	h.write(w, status, ProblemContentType, newProblem(r, status, code))
+}
+
+// createProblem returns the status and the code of the problem of a message that is not created.
+// A single message and each message of a batch fail the same way. Errors without a code are
+// not_created, their causes are only logged, they can have internal details.
+func createProblem(err error) (int, domain.Code) {
+	if _, ok := dto.AsFieldErrors(err); ok {
+		return http.StatusUnprocessableEntity, domain.CodeValidation
+	}
+
+	switch code, _ := domain.ErrorCode(err); code {
+	case domain.CodeAlreadyExists:
+		return http.StatusConflict, code
+	case domain.CodeInvalidArgument:
+		return http.StatusUnprocessableEntity, code
+	default:
+		return http.StatusUnprocessableEntity, domain.CodeNotCreated
+	}
+}
+
+// validationError is the only problem with the details of the request, they are in the fields.
+func (h *responder) validationError(w http.ResponseWriter, r *http.Request, fe message.FieldErrors) {
+	status := http.StatusUnprocessableEntity
+	h.write(w, status, ProblemContentType, dto.ValidationProblem{
+		Problem: newProblem(r, status, domain.CodeValidation),
+		Fields:  dto.NewFieldErrors(fe),
+	})
+}
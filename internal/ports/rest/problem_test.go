@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/dto"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMessageHandler_Problem(t *testing.T) {
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	t.Run("domain error", func(t *testing.T) {
+		uc.On("GetMessage", mock.Anything, 42).
+			Return(nil, &message.ErrorWithID{ID: 42, Err: domain.ErrNotFound}).Once()
+
+		var problem dto.Problem
+		e.GET("/messages/42").
+			WithHeader(middleware.RequestIDHeader, "req-1").
+			Expect().
+			Status(http.StatusNotFound).
+			JSON(contentOpts(true)).Object().
+			Decode(&problem)
+
+		assert.Equal(t, dto.Problem{
+			Type:      "urn:messagio:problem:not_found",
+			Title:     "Not found",
+			Status:    http.StatusNotFound,
+			Detail:    "The resource is not found.",
+			Instance:  "/messages/42",
+			Code:      string(domain.CodeNotFound),
+			RequestID: "req-1",
+		}, problem)
+	})
+
+	t.Run("error text is not exposed", func(t *testing.T) {
+		uc.On("CancelMessage", mock.Anything, 44).
+			Return(nil, &message.ErrorWithID{
+				ID:  44,
+				Err: fmt.Errorf("%w: row is locked by pid 1234", domain.ErrConflict),
+			}).Once()
+
+		e.POST("/messages/44/cancel").
+			Expect().
+			Status(http.StatusConflict).
+			JSON(contentOpts(true)).Object().
+			HasValue("code", domain.CodeConflict).
+			HasValue("detail", domain.CodeConflict.Detail())
+	})
+
+	t.Run("internal error is not exposed", func(t *testing.T) {
+		uc.On("GetMessage", mock.Anything, 43).
+			Return(nil, errors.New("connection to 10.0.0.1 refused")).Once()
+
+		obj := e.GET("/messages/43").
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON(contentOpts(true)).Object()
+
+		assertProblem(obj, http.StatusInternalServerError)
+		obj.NotContainsKey("detail")
+		obj.Value("code").IsEqual(domain.CodeInternal)
+		obj.Value("request_id").String().NotEmpty()
+	})
+
+	t.Run("batch fails as a single message", func(t *testing.T) {
+		exists := &message.Error{Err: fmt.Errorf("%w: duplicate key", domain.ErrAlreadyExists)}
+		uc.On("CreateMessage", mock.Anything, mock.Anything).Return(exists).Once()
+		uc.On("CreateMessages", mock.Anything, mock.Anything).Return(exists).Once()
+
+		e.POST("/messages").
+			WithJSON(map[string]string{"content": "hello"}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON(contentOpts(true)).Object().
+			HasValue("code", domain.CodeAlreadyExists)
+
+		e.POST("/messages/batch").
+			WithJSON([]map[string]string{{"content": "hello"}}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON(contentOpts(true)).Object().
+			HasValue("code", domain.CodeAlreadyExists)
+	})
+}
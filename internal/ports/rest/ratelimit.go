@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"github.com/go-chi/httprate"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/logger"
+	"net/http"
+	"strconv"
+)
+
+// clientKey returns the rate limit key of the client of the request.
+func clientKey(c limiter.Config, r *http.Request) (string, error) {
+	if p, ok := auth.PrincipalFrom(r.Context()); ok {
+		return p.Subject, nil
+	}
+
+	if c.KeyHeader != "" {
+		if v := r.Header.Get(c.KeyHeader); v != "" {
+			return "client:" + v, nil
+		}
+	}
+
+	ip, err := httprate.KeyByIP(r)
+	if err != nil {
+		return "", err
+	}
+	return "ip:" + ip, nil
+}
+
+func (h *MessageHandler) rateLimit(route string) func(http.Handler) http.Handler {
+	return h.limitRoute(h.cfg.RateLimit, route)
+}
+
+// limitRoute limits requests to the route for each client by the limits of the client tier.
+// It must be used after Auth, so that the authenticated client is known.
+// Requests are not limited while the store fails, so the API stays available.
+func (h *responder) limitRoute(cfg limiter.Config, route string) func(http.Handler) http.Handler {
+	routeLimiter := cfg.NewRouteLimiter(route)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, err := clientKey(cfg, r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d, err := routeLimiter.Allow(r.Context(), client)
+			if err != nil {
+				logger.ForRest(h.Log, "rate limit", r.Context()).Error("failed to check rate limit", logger.Err(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if d.Limit > 0 {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(d.Reset.Unix(), 10))
+			}
+			if !d.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(d.RetryAfter.Seconds())))
+				h.error(w, r, http.StatusTooManyRequests, domain.ErrRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMessageHandler_RateLimitTiers(t *testing.T) {
+	const keyHeader = "X-Client-ID"
+
+	authenticator := mocks.NewAuthenticator(t)
+	uc := mocks.NewMessageUsecase(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
+		RateLimit: limiter.Config{
+			KeyHeader:   keyHeader,
+			DefaultTier: "default",
+			Tiers: map[string]limiter.Tier{
+				"default": {limiter.RouteGetMsg: {PerMinute: 2}},
+				"premium": {limiter.RouteGetMsg: {PerMinute: 60, Burst: 4}},
+			},
+			Clients: map[string]string{
+				"apikey:1":   "premium",
+				"client:crm": "premium",
+			},
+		},
+	})
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	uc.On("GetMessage", mock.Anything, 1).Return(&message.Message{ID: 1}, nil)
+
+	get := func(headers map[string]string, status int) *httpexpect.Response {
+		return e.GET("/messages/1").WithHeaders(headers).Expect().Status(status)
+	}
+
+	t.Run("header_clients_are_limited_separately", func(t *testing.T) {
+		for _, client := range []string{"a", "b"} {
+			headers := map[string]string{keyHeader: client}
+			get(headers, http.StatusOK)
+			get(headers, http.StatusOK)
+			// until the sliding window rate drops below the limit
+			get(headers, http.StatusTooManyRequests).Header("Retry-After").AsNumber().InRange(16, 76)
+		}
+	})
+
+	t.Run("premium_header_client", func(t *testing.T) {
+		headers := map[string]string{keyHeader: "crm"}
+		for remaining := 3; remaining >= 0; remaining-- {
+			resp := get(headers, http.StatusOK)
+			resp.Header("X-RateLimit-Limit").IsEqual("4")
+			resp.Header("X-RateLimit-Remaining").IsEqual(strconv.Itoa(remaining))
+			resp.Header("X-RateLimit-Reset").AsNumber().InRange(time.Now().Unix(), time.Now().Add(4*time.Second).Unix())
+		}
+		resp := get(headers, http.StatusTooManyRequests)
+		resp.Header("X-RateLimit-Remaining").IsEqual("0")
+		resp.Header("Retry-After").AsNumber().InRange(1, 5)
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		get(nil, http.StatusOK)
+		get(nil, http.StatusOK)
+		get(nil, http.StatusTooManyRequests)
+	})
+
+	t.Run("principal_is_preferred_to_header", func(t *testing.T) {
+		mh.Auth = NewAuth(authenticator, nil)
+		router := chi.NewRouter()
+		mh.SetupRoutes(router)
+		server := httptest.NewServer(router)
+		defer server.Close()
+		e := httpexpect.Default(t, server.URL)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_premium").
+			Return(&auth.Principal{Subject: "apikey:1", Scopes: []auth.Scope{auth.ScopeMessagesRead}}, nil)
+
+		headers := map[string]string{APIKeyHeader: "msg_premium", keyHeader: "a"}
+		for range 4 {
+			e.GET("/messages/1").WithHeaders(headers).Expect().Status(http.StatusOK)
+		}
+		e.GET("/messages/1").WithHeaders(headers).Expect().Status(http.StatusTooManyRequests)
+	})
+}
+
+// memoryRateLimitStore is ratelimit.Repository shared by handlers in the test as by replicas.
+type memoryRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[string]map[time.Time]int
+	err      error
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{counters: make(map[string]map[time.Time]int)}
+}
+
+func (s *memoryRateLimitStore) Increment(_ context.Context, key string, window time.Time,
+	length time.Duration, amount int) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, 0, s.err
+	}
+	if s.counters[key] == nil {
+		s.counters[key] = make(map[time.Time]int)
+	}
+	s.counters[key][window] += amount
+	return s.counters[key][window], s.counters[key][window.Add(-length)], nil
+}
+
+func (s *memoryRateLimitStore) Get(_ context.Context, key string, window time.Time,
+	length time.Duration) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, 0, s.err
+	}
+	return s.counters[key][window], s.counters[key][window.Add(-length)], nil
+}
+
+func TestMessageHandler_RateLimitStore(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	uc := mocks.NewMessageUsecase(t)
+	uc.On("GetMessage", mock.Anything, 1).Return(&message.Message{ID: 1}, nil)
+
+	replica := func() *httpexpect.Expect {
+		router := chi.NewRouter()
+		mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
+			RateLimit: limiter.Config{
+				DefaultTier: "default",
+				Tiers:       map[string]limiter.Tier{"default": {limiter.RouteGetMsg: {PerMinute: 3}}},
+				Store:       store,
+			},
+		})
+		mh.SetupRoutes(router)
+
+		server := httptest.NewServer(mh)
+		t.Cleanup(server.Close)
+		return httpexpect.Default(t, server.URL)
+	}
+	first, second := replica(), replica()
+
+	first.GET("/messages/1").Expect().Status(http.StatusOK)
+	second.GET("/messages/1").Expect().Status(http.StatusOK)
+	first.GET("/messages/1").Expect().Status(http.StatusOK)
+	second.GET("/messages/1").Expect().Status(http.StatusTooManyRequests).
+		Header("Retry-After").AsNumber().InRange(11, 71)
+
+	store.mu.Lock()
+	for key := range store.counters {
+		assert.True(t, strings.HasPrefix(key, "get_msg:ip:127.0.0.1"), key)
+	}
+	store.err = errors.New("db is down")
+	store.mu.Unlock()
+
+	first.GET("/messages/1").Expect().Status(http.StatusOK)
+}
+
+func TestMessageHandler_RateLimitConcurrent(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	uc := mocks.NewMessageUsecase(t)
+	uc.On("GetMessage", mock.Anything, 1).Return(&message.Message{ID: 1}, nil)
+
+	var replicas []*httptest.Server
+	for range 3 {
+		router := chi.NewRouter()
+		mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{
+			RateLimit: limiter.Config{
+				DefaultTier: "default",
+				Tiers:       map[string]limiter.Tier{"default": {limiter.RouteGetMsg: {PerMinute: 5}}},
+				Store:       store,
+			},
+		})
+		mh.SetupRoutes(router)
+
+		server := httptest.NewServer(mh)
+		t.Cleanup(server.Close)
+		replicas = append(replicas, server)
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := http.Get(replicas[i%len(replicas)].URL + "/messages/1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The previous window keeps its weight when the window rolls, so the limit holds then too.
+	assert.Equal(t, 5, ok, "the replicas allow the limit together")
+}
@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// responder writes JSON responses and problems, handlers embed it.
+type responder struct {
+	Log *slog.Logger
+}
+
+func (h *responder) respond(w http.ResponseWriter, code int, data interface{}) {
+	h.write(w, code, "application/json", data)
+}
+
+func (h *responder) write(w http.ResponseWriter, code int, contentType string, data interface{}) {
+	var jsonData []byte
+	var err error
+
+	if data != nil {
+		jsonData, err = json.Marshal(data)
+
+		if err != nil {
+			h.Log.Error("json marshal error", slog.String("error", err.Error()))
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.WriteHeader(code)
+	_, err = w.Write(jsonData)
+	if err != nil {
+		h.Log.Error("response write json data error", slog.String("error", err.Error()))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	_ "messagio_assignment/docs" // for swagger
 	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/limiter"
 	"net/http"
 	"net/url"
 )
@@ -13,16 +14,56 @@ import (
 //	@title			Messagio Assigment
 //	@version		0.1
 //	@description	Test task to Messagio.
+//	@description	The message routes are versioned, e.g. /v1/messages. The unversioned routes, e.g. /messages,
+//	@description	are deprecated aliases of /v1 with the Deprecation, Sunset and Link headers.
 
 // @BasePath	/
 
-func NewServer(httpCfg config.HTTPServer, msgUC MessageUsecase, log *slog.Logger) *http.Server {
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer API key or JWT, e.g. "Bearer msg_..."
+
+// NewServer creates the server. Requests are anonymous if auth is nil, admin endpoints are not set up then.
+// The stats WebSocket is not set up if statsStreamer is nil. rateLimitCfg is shared with the gRPC server,
+// so both APIs count calls together.
+func NewServer(httpCfg config.HTTPServer, msgUC MessageUsecase, idempotencyUC IdempotencyUsecase,
+	auth *Auth, apiKeyUC APIKeyUsecase, webhookUC WebhookUsecase, rateLimitCfg limiter.Config, events EventSubscriber,
+	statsStreamer StatsStreamer, log *slog.Logger) (*http.Server, error) {
+	validationCfg, err := NewValidationConfig(httpCfg.Handlers.Message.Validation)
+	if err != nil {
+		return nil, err
+	}
+
 	router := chi.NewRouter()
 	msgHandler := NewMessageHandler(router, msgUC, log, MessageHandlerConfig{
-		CreateMsgPerMinute: httpCfg.Handlers.Message.CreateMsgPerMinute,
-		GetStatsPerMinute:  httpCfg.Handlers.Message.GetStatsPerMinute,
+		MaxBatchSize: httpCfg.Handlers.Message.MaxBatchSize,
+		MaxBodySize:  httpCfg.Handlers.Message.MaxBodySize,
+		MaxWait:      MaxWaitFor(httpCfg.Timeouts.Write),
+		RateLimit:    rateLimitCfg,
+		Validation:   validationCfg,
 	})
-	handler := NewHandler(router, msgHandler, log)
+	msgHandler.Idempotency = idempotencyUC
+	msgHandler.Auth = auth
+	msgHandler.Events = events
+
+	var others []ChiHandler
+	if auth != nil {
+		others = append(others, NewAPIKeyHandler(apiKeyUC, auth, log), NewWebhookHandler(webhookUC, auth, log))
+	}
+	if statsStreamer != nil {
+		others = append(others, NewStatsWSHandler(statsStreamer, auth, rateLimitCfg, log))
+	}
+	versions := []APIVersion{{Name: "v1", Handler: msgHandler}}
+	deprecation := Deprecation{
+		At:     httpCfg.UnversionedRoutes.DeprecatedAt,
+		Sunset: httpCfg.UnversionedRoutes.SunsetAt,
+	}
+	handler := NewHandler(router, versions, deprecation, log, others...)
 
 	swaggerURL := url.URL{
 		Path: "/swagger/doc.json",
@@ -47,5 +88,5 @@ func NewServer(httpCfg config.HTTPServer, msgUC MessageUsecase, log *slog.Logger
 		WriteTimeout:      httpCfg.Timeouts.Write,
 		IdleTimeout:       httpCfg.Timeouts.Idle,
 		MaxHeaderBytes:    httpCfg.MaxHeaderBytes,
-	}
+	}, nil
 }
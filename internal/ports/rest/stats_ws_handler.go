@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/logger"
+	"messagio_assignment/internal/ports/rest/dto"
+	"net/http"
+	"time"
+)
+
+//go:generate mockery --name StatsStreamer
+type StatsStreamer interface {
+	// Subscribe returns the current stats and the channel of their changes.
+	// The channel is closed by cancel, when the subscriber is too slow or on shutdown.
+	Subscribe(ctx context.Context) (*message.Stats, <-chan message.StatsDelta, func(), error)
+}
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	// wsPongTimeout is how long the connection stays open without a pong, pings are sent more often.
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = wsPongTimeout * 9 / 10
+)
+
+// StatsWSHandler pushes the message stats over WebSocket.
+type StatsWSHandler struct {
+	streamer  StatsStreamer
+	auth      *Auth
+	rateLimit limiter.Config
+	upgrader  websocket.Upgrader
+
+	responder
+}
+
+// NewStatsWSHandler creates the handler, connections are limited by the get_stats limit of rateLimit.
+func NewStatsWSHandler(streamer StatsStreamer, auth *Auth, rateLimit limiter.Config, log *slog.Logger) *StatsWSHandler {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	log = log.With(
+		slog.String("component", "ports/rest/stats_ws_handler"),
+	)
+
+	return &StatsWSHandler{streamer: streamer, auth: auth, rateLimit: rateLimit, responder: responder{Log: log}}
+}
+
+func (h *StatsWSHandler) SetupRoutes(r chi.Router) {
+	r.With(h.auth.Require(auth.ScopeMessagesRead), h.limitRoute(h.rateLimit, limiter.RouteGetStats)).
+		Get("/ws/stats", h.StreamStats())
+}
+
+// StreamStats godoc
+//
+//	@Summary		Stream message stats over WebSocket
+//	@Description	The first message is a "snapshot" with the current stats, the next ones are "delta"
+//	@Description	with the changes since the previous message. Deltas are sent when messages are created
+//	@Description	or processed, at most once per the configured interval. A slow client is disconnected
+//	@Description	and gets a new snapshot after reconnecting.
+//	@Tags			stats
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Success		101	{object}	dto.StatsStreamResp	"WebSocket messages"
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		429	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//	@Router			/ws/stats [get]
+func (h *StatsWSHandler) StreamStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "stream stats", r.Context())
+
+		if !websocket.IsWebSocketUpgrade(r) {
+			log.Warn("not a websocket upgrade request")
+			h.error(w, r, http.StatusBadRequest, errors.New("websocket upgrade is required"))
+			return
+		}
+
+		stats, deltas, cancel, err := h.streamer.Subscribe(r.Context())
+		if err != nil {
+			log.Error("failed to subscribe to stats", logger.Err(err))
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		defer cancel()
+
+		// The upgrader writes the error response itself.
+		conn, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Warn("failed to upgrade connection", logger.Err(err))
+			return
+		}
+		defer conn.Close()
+
+		log.Info("stats stream is started")
+		defer log.Info("stats stream is finished")
+
+		closed := h.readControl(conn)
+
+		var snapshot dto.GetStatsResp
+		snapshot.FromDomain(stats)
+		if err := h.writeJSON(conn, dto.StatsStreamResp{Type: dto.StatsStreamSnapshot, Stats: &snapshot}); err != nil {
+			log.Info("failed to write snapshot", logger.Err(err))
+			return
+		}
+
+		ping := time.NewTicker(wsPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case delta, ok := <-deltas:
+				if !ok {
+					// The client reconnects and gets a new snapshot.
+					h.writeClose(conn, websocket.CloseGoingAway, "stream is closed, reconnect")
+					return
+				}
+
+				var deltaResp dto.StatsDeltaResp
+				deltaResp.FromDomain(delta)
+				if err := h.writeJSON(conn, dto.StatsStreamResp{Type: dto.StatsStreamDelta, Delta: &deltaResp}); err != nil {
+					log.Info("failed to write delta", logger.Err(err))
+					return
+				}
+			case <-ping.C:
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+				if err != nil {
+					log.Info("failed to ping", logger.Err(err))
+					return
+				}
+			}
+		}
+	}
+}
+
+// readControl reads the connection, so pongs and close frames are handled. Messages of the client
+// are ignored. The returned channel is closed when the connection is closed or the client doesn't answer pings.
+func (h *StatsWSHandler) readControl(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return closed
+}
+
+func (h *StatsWSHandler) writeJSON(conn *websocket.Conn, v any) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(v)
+}
+
+func (h *StatsWSHandler) writeClose(conn *websocket.Conn, code int, text string) {
+	msg := websocket.FormatCloseMessage(code, text)
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout))
+}
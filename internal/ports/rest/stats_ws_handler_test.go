@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/limiter"
+	"messagio_assignment/internal/ports/rest/dto"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newStatsWSServer(t *testing.T, streamer StatsStreamer, rateLimit limiter.Config) *httptest.Server {
+	router := chi.NewRouter()
+	NewStatsWSHandler(streamer, nil, rateLimit, nil).SetupRoutes(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStatsWSHandler_StreamStats(t *testing.T) {
+	streamer := mocks.NewStatsStreamer(t)
+	server := newStatsWSServer(t, streamer, limiter.Config{})
+
+	deltas := make(chan message.StatsDelta, 2)
+	deltas <- message.StatsDelta{All: 2, ByStatus: map[message.Status]int{message.StatusPending: 2}}
+	deltas <- message.StatsDelta{Processed: 1, ByStatus: map[message.Status]int{
+		message.StatusPending:   -1,
+		message.StatusProcessed: 1,
+	}}
+	// The streamer closes the channel of a slow client or on shutdown.
+	close(deltas)
+
+	cancelled := make(chan struct{})
+	streamer.On("Subscribe", mock.Anything).Return(&message.Stats{
+		All:       10,
+		Processed: 7,
+		ByStatus:  map[message.Status]int{message.StatusProcessed: 7, message.StatusPending: 3},
+	}, (<-chan message.StatsDelta)(deltas), func() { close(cancelled) }, nil).Once()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/stats"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var snapshot dto.StatsStreamResp
+	require.NoError(t, conn.ReadJSON(&snapshot))
+	assert.Equal(t, dto.StatsStreamSnapshot, snapshot.Type)
+	require.NotNil(t, snapshot.Stats)
+	assert.Equal(t, 10, snapshot.Stats.All)
+	assert.Equal(t, 7, snapshot.Stats.Processed)
+	assert.Equal(t, map[string]int{"processed": 7, "pending": 3}, snapshot.Stats.ByStatus)
+
+	var delta dto.StatsStreamResp
+	require.NoError(t, conn.ReadJSON(&delta))
+	assert.Equal(t, dto.StatsStreamDelta, delta.Type)
+	assert.Equal(t, &dto.StatsDeltaResp{All: 2, ByStatus: map[string]int{"pending": 2}}, delta.Delta)
+
+	require.NoError(t, conn.ReadJSON(&delta))
+	assert.Equal(t, &dto.StatsDeltaResp{Processed: 1, ByStatus: map[string]int{"pending": -1, "processed": 1}},
+		delta.Delta)
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+
+	<-cancelled
+}
+
+func TestStatsWSHandler_Errors(t *testing.T) {
+	streamer := mocks.NewStatsStreamer(t)
+	server := newStatsWSServer(t, streamer, limiter.Config{})
+
+	t.Run("not_websocket", func(t *testing.T) {
+		httpexpect.Default(t, server.URL).GET("/ws/stats").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON(contentOpts(true)).Object().
+			Value("instance").String().IsEqual("/ws/stats")
+	})
+
+	t.Run("subscribe_failed", func(t *testing.T) {
+		streamer.On("Subscribe", mock.Anything).
+			Return(nil, nil, nil, errors.New("stats streamer is closed")).Once()
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/stats"
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestStatsWSHandler_RateLimit(t *testing.T) {
+	server := newStatsWSServer(t, mocks.NewStatsStreamer(t), limiter.Config{
+		DefaultTier: "default",
+		Tiers: map[string]limiter.Tier{
+			"default": {limiter.RouteGetStats: {PerMinute: 1}},
+		},
+	})
+
+	e := httpexpect.Default(t, server.URL)
+	// The connections are limited before the upgrade.
+	e.GET("/ws/stats").Expect().Status(http.StatusBadRequest)
+	e.GET("/ws/stats").Expect().
+		Status(http.StatusTooManyRequests).
+		Header("Retry-After").AsNumber().InRange(1, 120)
+}
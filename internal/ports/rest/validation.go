@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"messagio_assignment/internal/config"
+	"messagio_assignment/internal/domain/message"
+)
+
+// ValidationConfig sets the rules for message requests. The zero value accepts any content.
+type ValidationConfig struct {
+	Rules message.ValidationRules
+	// DisallowUnknownFields rejects requests with fields that are not in the request DTO.
+	DisallowUnknownFields bool
+}
+
+// NewValidationConfig converts the config of the message validation.
+func NewValidationConfig(cfg config.MessageValidation) (ValidationConfig, error) {
+	denyPatterns, err := message.CompileDenyPatterns(cfg.DenyPatterns)
+	if err != nil {
+		return ValidationConfig{}, err
+	}
+
+	return ValidationConfig{
+		Rules: message.ValidationRules{
+			MaxContentLength: cfg.MaxContentLength,
+			RequireContent:   cfg.RequireContent,
+			RequireUTF8:      cfg.RequireUTF8,
+			DenyPatterns:     denyPatterns,
+		},
+		DisallowUnknownFields: cfg.DisallowUnknownFields,
+	}, nil
+}
+
+// validateMessage checks the request against the configured rules and the request DTO rules.
+// It returns the draft of the request, the message is created from it if there are no errors.
+func (h *MessageHandler) validateMessage(msgReq MessageReq) (*message.Draft, message.FieldErrors) {
+	draft, fe := msgReq.Draft()
+	return draft, append(h.cfg.Validation.Rules.Validate(draft), fe...)
+}
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/dto"
+	"net/http"
+	"time"
+)
+
+// APIVersion is a version of the message API, its routes are mounted under /<Name>, e.g. /v1.
+// Versions share the usecases and differ in payloads: a new version registers its own handler,
+// e.g. a MessageHandler with other DTOs.
+type APIVersion struct {
+	Name    string
+	Handler ChiHandler
+}
+
+// Deprecation is announced by the unversioned aliases of the first API version,
+// e.g. /messages for /v1/messages. Zero times are not sent.
+type Deprecation struct {
+	// At is when the aliases were deprecated.
+	At time.Time
+	// Sunset is when the aliases stop working.
+	Sunset time.Time
+}
+
+// headers sets the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and links the versioned path.
+func (d Deprecation) headers(h http.Header, successor string) {
+	if !d.At.IsZero() {
+		h.Set("Deprecation", fmt.Sprintf("@%d", d.At.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+}
+
+// unversionedAliases serves the unversioned paths of the first API version with the deprecation headers.
+// The request is routed to the versioned route, so they share limits, and its URL is kept for problems.
+func (h *Handler) unversionedAliases(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := chi.RouteContext(r.Context())
+		if h.aliases == nil || rctx == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := r.URL.RawPath
+		if path == "" {
+			path = r.URL.Path
+		}
+
+		if h.aliases.Match(chi.NewRouteContext(), r.Method, path) {
+			versioned := "/" + h.versions[0].Name + path
+			h.deprecation.headers(w.Header(), versioned)
+			rctx.RoutePath = versioned
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MessageReq is the request DTO of a message to create, the body is decoded into it.
+type MessageReq interface {
+	// Draft converts the request to the draft, which is validated by the configured rules.
+	// It returns the errors of the fields that can't be represented in the draft.
+	Draft() (*message.Draft, message.FieldErrors)
+}
+
+// BatchItem is the result of a message of the batch: the created message or the error of the item.
+type BatchItem struct {
+	Msg *message.Message
+	Err error
+}
+
+// MessageDTOs converts the requests and the usecase results to the DTOs of an API version,
+// so a new version can change payloads with the same usecase and MessageHandler.
+type MessageDTOs interface {
+	// CreateMessageReq returns an empty request of a message to create,
+	// it is used for the messages of a batch as well.
+	CreateMessageReq() MessageReq
+	// CreateMessageResp is the response of a created message.
+	CreateMessageResp(msg *message.Message) any
+	// CreateMessagesResp is the response of a batch, the items are in the order of the request.
+	CreateMessagesResp(items []BatchItem) any
+	// GetMessageResp is the response of a message got, cancelled or waited for.
+	GetMessageResp(msg *message.Message) any
+	ListMessagesResp(page *message.Page) any
+	SearchMessagesResp(page *message.SearchPage) any
+	GetStatsResp(stats *message.Stats) any
+	// MessageEventResp is the data of a status event of the SSE streams.
+	MessageEventResp(ev message.Event) any
+}
+
+// V1DTOs are the DTOs of the v1 API and the unversioned aliases.
+type V1DTOs struct{}
+
+func (V1DTOs) CreateMessageReq() MessageReq {
+	return &dto.CreateMessageReq{}
+}
+
+func (V1DTOs) CreateMessageResp(msg *message.Message) any {
+	var resp dto.CreateMessageResp
+	resp.FromDomain(msg)
+	return resp
+}
+
+func (V1DTOs) CreateMessagesResp(items []BatchItem) any {
+	resp := dto.CreateMessagesResp{Items: make([]dto.CreateMessagesItemResp, len(items))}
+	for i, item := range items {
+		if item.Err != nil {
+			_, code := createProblem(item.Err)
+			resp.Items[i].Code = string(code)
+			resp.Items[i].Error = code.Detail()
+			if fe, ok := dto.AsFieldErrors(item.Err); ok {
+				resp.Items[i].Fields = dto.NewFieldErrors(fe)
+			}
+			resp.Failed++
+			continue
+		}
+
+		resp.Items[i].ID = item.Msg.ID
+		resp.Created++
+	}
+	return resp
+}
+
+func (V1DTOs) GetMessageResp(msg *message.Message) any {
+	var resp dto.GetMessageResp
+	resp.FromDomain(msg)
+	return resp
+}
+
+func (V1DTOs) ListMessagesResp(page *message.Page) any {
+	var resp dto.ListMessagesResp
+	resp.FromDomain(page)
+	return resp
+}
+
+func (V1DTOs) SearchMessagesResp(page *message.SearchPage) any {
+	var resp dto.SearchMessagesResp
+	resp.FromDomain(page)
+	return resp
+}
+
+func (V1DTOs) GetStatsResp(stats *message.Stats) any {
+	var resp dto.GetStatsResp
+	resp.FromDomain(stats)
+	return resp
+}
+
+func (V1DTOs) MessageEventResp(ev message.Event) any {
+	var resp dto.MessageEventResp
+	resp.FromDomain(ev)
+	return resp
+}
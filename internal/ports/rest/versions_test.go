@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// versionedHandlerMock answers with the API version it is registered for.
+type versionedHandlerMock string
+
+func (h versionedHandlerMock) SetupRoutes(r chi.Router) {
+	r.Get("/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(string(h) + ":" + chi.URLParam(r, "id") + ":" + r.URL.Path))
+	})
+}
+
+func TestHandler_Versions(t *testing.T) {
+	deprecation := Deprecation{
+		At:     time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	versions := []APIVersion{
+		{Name: "v1", Handler: versionedHandlerMock("v1")},
+		{Name: "v2", Handler: versionedHandlerMock("v2")},
+	}
+	handler := NewHandler(chi.NewRouter(), versions, deprecation, nil, &ChiHandlerMock{})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	t.Run("versioned routes", func(t *testing.T) {
+		for _, v := range []string{"v1", "v2"} {
+			resp := e.GET("/" + v + "/messages/1").Expect().Status(http.StatusOK)
+			resp.Body().IsEqual(v + ":1:/" + v + "/messages/1")
+			resp.Headers().NotContainsKey("Deprecation")
+		}
+	})
+
+	t.Run("unversioned alias of v1", func(t *testing.T) {
+		resp := e.GET("/messages/1").Expect().Status(http.StatusOK)
+		// The handler gets the original URL, e.g. for the problem instance.
+		resp.Body().IsEqual("v1:1:/messages/1")
+		resp.Header("Deprecation").IsEqual("@1725148800")
+		resp.Header("Sunset").IsEqual("Sat, 01 Mar 2025 00:00:00 GMT")
+		resp.Header("Link").IsEqual(`</v1/messages/1>; rel="successor-version"`)
+	})
+
+	t.Run("other routes are not aliased", func(t *testing.T) {
+		e.GET("/example").Expect().Status(http.StatusTeapot).Headers().NotContainsKey("Deprecation")
+		e.GET("/messages").Expect().Status(http.StatusNotFound).Headers().NotContainsKey("Deprecation")
+	})
+}
+
+// v2DTOs changes the message payloads of v1.
+type v2DTOs struct {
+	V1DTOs
+}
+
+type v2MessageReq struct {
+	Text string            `json:"text"`
+	Meta map[string]string `json:"meta"`
+}
+
+func (r *v2MessageReq) Draft() (*message.Draft, message.FieldErrors) {
+	return &message.Draft{Content: r.Text, Labels: r.Meta}, nil
+}
+
+type v2MessageResp struct {
+	ID    int               `json:"id"`
+	State string            `json:"state"`
+	Meta  map[string]string `json:"meta"`
+}
+
+type v2BatchResp struct {
+	IDs    []int `json:"ids"`
+	Errors int   `json:"errors"`
+}
+
+func (v2DTOs) CreateMessageReq() MessageReq {
+	return &v2MessageReq{}
+}
+
+func (v2DTOs) GetMessageResp(msg *message.Message) any {
+	return v2MessageResp{ID: msg.ID, State: msg.Status.String(), Meta: msg.Labels}
+}
+
+func (v2DTOs) CreateMessagesResp(items []BatchItem) any {
+	var resp v2BatchResp
+	for _, item := range items {
+		if item.Err != nil {
+			resp.Errors++
+			continue
+		}
+		resp.IDs = append(resp.IDs, item.Msg.ID)
+	}
+	return resp
+}
+
+func TestMessageHandler_DTOs(t *testing.T) {
+	uc := mocks.NewMessageUsecase(t)
+	uc.On("GetMessage", mock.Anything, 1).
+		Return(&message.Message{ID: 1, Status: message.StatusProcessed, Labels: message.Labels{"team": "crm"}}, nil).
+		Twice()
+
+	v1 := NewMessageHandler(nil, uc, nil, MessageHandlerConfig{})
+	v2 := NewMessageHandler(nil, uc, nil, MessageHandlerConfig{})
+	v2.DTOs = v2DTOs{}
+
+	versions := []APIVersion{{Name: "v1", Handler: v1}, {Name: "v2", Handler: v2}}
+	server := httptest.NewServer(NewHandler(chi.NewRouter(), versions, Deprecation{}, nil))
+	defer server.Close()
+
+	e := httpexpect.Default(t, server.URL)
+
+	e.GET("/v1/messages/1").Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "processed").
+		HasValue("labels", map[string]string{"team": "crm"})
+
+	e.GET("/v2/messages/1").Expect().Status(http.StatusOK).JSON().Object().
+		IsEqual(map[string]any{"id": 1, "state": "processed", "meta": map[string]string{"team": "crm"}})
+
+	uc.On("CreateMessages", mock.Anything, []*message.Message{{Content: "hello", Labels: message.Labels{"team": "crm"}}}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).([]*message.Message)[0].ID = 2
+		}).
+		Return(nil).Once()
+
+	e.POST("/v2/messages/batch").
+		WithJSON([]any{map[string]any{"text": "hello", "meta": map[string]string{"team": "crm"}}, "not an object"}).
+		Expect().
+		Status(http.StatusMultiStatus).JSON().Object().
+		IsEqual(map[string]any{"ids": []int{2}, "errors": 1})
+}
+
+func TestHandler_VersionsIdempotency(t *testing.T) {
+	uc := mocks.NewMessageUsecase(t)
+	iu := mocks.NewIdempotencyUsecase(t)
+
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{})
+	mh.Idempotency = iu
+	handler := NewHandler(router, []APIVersion{{Name: "v1", Handler: mh}}, Deprecation{}, nil)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var fingerprints []string
+	iu.On("Begin", mock.Anything, "same-key", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) {
+			fingerprints = append(fingerprints, args.String(2))
+		}).
+		Return("token", nil, nil).Twice()
+	uc.On("CreateMessage", mock.Anything, mock.Anything).Return(nil).Twice()
+	iu.On("Complete", mock.Anything, "same-key", "token", mock.Anything).Return(nil).Twice()
+
+	e := httpexpect.Default(t, server.URL)
+	for _, path := range []string{"/v1/messages", "/messages"} {
+		e.POST(path).
+			WithHeader(IdempotencyKeyHeader, "same-key").
+			WithJSON(map[string]string{"content": "hello"}).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	// a retry through the alias is the same request, so it is replayed instead of rejected
+	require.Len(t, fingerprints, 2)
+	require.Equal(t, fingerprints[0], fingerprints[1])
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"context"
+	"messagio_assignment/internal/domain/message"
+	"time"
+)
+
+const (
+	// DefaultMaxWait caps waiting for a message if the server has no write timeout.
+	DefaultMaxWait = time.Minute
+	// waitWriteMargin is left of the server write timeout to write the response after waiting.
+	waitWriteMargin = time.Second
+	// waitPollInterval is how often the waited message is read, in case its event is missed.
+	waitPollInterval = time.Second
+)
+
+// MaxWaitFor returns the longest wait that lets the response be written before the server write timeout.
+func MaxWaitFor(writeTimeout time.Duration) time.Duration {
+	switch {
+	case writeTimeout <= 0:
+		return DefaultMaxWait
+	case writeTimeout <= 2*waitWriteMargin:
+		return writeTimeout / 2
+	default:
+		return min(writeTimeout-waitWriteMargin, DefaultMaxWait)
+	}
+}
+
+// waitDone reports whether waiting for the message can end: it is processed or won't be processed.
+func waitDone(s message.Status) bool {
+	return s.IsFinal() || s.IsResult()
+}
+
+// waitMessage waits until the message gets a processing result or the wait elapses and returns its current state.
+// The message is read again on its events and every waitPollInterval. events may be nil.
+// If the client goes away, the last read state is returned.
+func (h *MessageHandler) waitMessage(ctx context.Context, msg *message.Message, events <-chan message.Event,
+	wait time.Duration) (*message.Message, error) {
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	poll := time.NewTicker(waitPollInterval)
+	defer poll.Stop()
+
+	for !waitDone(msg.Status) {
+		select {
+		case <-ctx.Done():
+			return msg, nil
+		case <-timeout.C:
+			return h.uc.GetMessage(ctx, msg.ID)
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if !waitDone(ev.Status) {
+				continue
+			}
+		case <-poll.C:
+		}
+
+		var err error
+		if msg, err = h.uc.GetMessage(ctx, msg.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
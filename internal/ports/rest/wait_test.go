@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaxWaitFor(t *testing.T) {
+	tcases := []struct {
+		Name         string
+		WriteTimeout time.Duration
+		Expected     time.Duration
+	}{
+		{Name: "no write timeout", WriteTimeout: 0, Expected: DefaultMaxWait},
+		{Name: "margin is left", WriteTimeout: 10 * time.Second, Expected: 9 * time.Second},
+		{Name: "capped by default", WriteTimeout: 5 * time.Minute, Expected: DefaultMaxWait},
+		{Name: "short write timeout", WriteTimeout: time.Second, Expected: 500 * time.Millisecond},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, MaxWaitFor(tc.WriteTimeout))
+		})
+	}
+}
+
+func newWaitTestServer(t *testing.T, maxWait time.Duration) (*httpexpect.Expect, *mocks.MessageUsecase, *mocks.EventSubscriber) {
+	uc := mocks.NewMessageUsecase(t)
+	es := mocks.NewEventSubscriber(t)
+	router := chi.NewRouter()
+	mh := NewMessageHandler(router, uc, nil, MessageHandlerConfig{MaxWait: maxWait})
+	mh.Events = es
+	mh.SetupRoutes(router)
+
+	server := httptest.NewServer(mh)
+	t.Cleanup(server.Close)
+
+	return httpexpect.Default(t, server.URL), uc, es
+}
+
+func TestMessageHandler_GetMessageWait(t *testing.T) {
+	queued := &message.Message{ID: 42, Content: "some content", Status: message.StatusQueued}
+	processed := &message.Message{ID: 42, Content: "some content", Status: message.StatusProcessed}
+
+	t.Run("until processed", func(t *testing.T) {
+		e, uc, es := newWaitTestServer(t, time.Minute)
+
+		events := make(chan message.Event, 2)
+		events <- message.Event{MessageID: 42, Status: message.StatusProcessing}
+		events <- message.Event{MessageID: 42, Status: message.StatusProcessed}
+
+		cancelled := false
+		es.On("Subscribe", 42).Return((<-chan message.Event)(events), func() { cancelled = true }).Once()
+		uc.On("GetMessage", mock.Anything, 42).Return(queued, nil).Once()
+		uc.On("GetMessage", mock.Anything, 42).Return(processed, nil).Once()
+
+		e.GET("/messages/{id}", 42).
+			WithQuery("wait", "30s").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("status", "processed")
+
+		assert.True(t, cancelled, "subscription is not cancelled")
+	})
+
+	t.Run("already processed", func(t *testing.T) {
+		e, uc, es := newWaitTestServer(t, time.Minute)
+
+		es.On("Subscribe", 42).Return((<-chan message.Event)(make(chan message.Event)), func() {}).Once()
+		uc.On("GetMessage", mock.Anything, 42).Return(processed, nil).Once()
+
+		e.GET("/messages/{id}", 42).
+			WithQuery("wait", "30s").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("status", "processed")
+	})
+
+	t.Run("wait is capped", func(t *testing.T) {
+		e, uc, es := newWaitTestServer(t, 100*time.Millisecond)
+
+		es.On("Subscribe", 42).Return((<-chan message.Event)(make(chan message.Event)), func() {}).Once()
+		uc.On("GetMessage", mock.Anything, 42).Return(queued, nil).Twice()
+
+		start := time.Now()
+		e.GET("/messages/{id}", 42).
+			WithQuery("wait", "30s").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("status", "queued")
+
+		assert.Less(t, time.Since(start), waitPollInterval)
+	})
+
+	t.Run("invalid wait", func(t *testing.T) {
+		e, _, _ := newWaitTestServer(t, time.Minute)
+
+		for _, wait := range []string{"soon", "-1s"} {
+			obj := e.GET("/messages/{id}", 42).
+				WithQuery("wait", wait).
+				Expect().
+				Status(http.StatusBadRequest).
+				JSON(contentOpts(true)).Object()
+			assertProblem(obj, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/webhook"
+	"messagio_assignment/internal/logger"
+	"messagio_assignment/internal/ports/rest/dto"
+	"net/http"
+	"strconv"
+)
+
+//go:generate mockery --name WebhookUsecase
+type WebhookUsecase interface {
+	ListDeliveries(ctx context.Context, filter webhook.ListFilter) (*webhook.Page, error)
+	Redeliver(ctx context.Context, id int) (*webhook.Delivery, error)
+}
+
+// WebhookHandler gives access to the webhook delivery log, all its endpoints require the admin scope.
+type WebhookHandler struct {
+	uc   WebhookUsecase
+	auth *Auth
+
+	responder
+}
+
+func NewWebhookHandler(uc WebhookUsecase, auth *Auth, log *slog.Logger) *WebhookHandler {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+
+	log = log.With(
+		slog.String("component", "ports/rest/webhook_handler"),
+	)
+
+	return &WebhookHandler{uc: uc, auth: auth, responder: responder{Log: log}}
+}
+
+func (h *WebhookHandler) SetupRoutes(r chi.Router) {
+	r.Route("/admin/webhooks/deliveries", func(r chi.Router) {
+		r.Use(h.auth.Require(auth.ScopeAdmin))
+		r.Get("/", h.ListDeliveries())
+		r.Post("/{id}/redeliver", h.Redeliver())
+	})
+}
+
+// ListDeliveries godoc
+//
+//	@Summary		List webhook deliveries
+//	@Description	list the webhook delivery log with the result of the last attempt of each delivery
+//	@Tags			admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Param			message_id	query		int		false	"Filter by message id"
+//	@Param			status		query		string	false	"Filter by delivery status"	Enums(pending, succeeded, failed)
+//	@Param			limit		query		int		false	"Page size, 50 by default, 500 at most"
+//	@Param			cursor		query		string	false	"next_cursor from the previous page"
+//	@Success		200			{object}	dto.ListDeliveriesResp
+//	@Failure		400			{object}	dto.Problem
+//	@Failure		401			{object}	dto.Problem
+//	@Failure		403			{object}	dto.Problem
+//	@Failure		500			{object}	dto.Problem
+//	@Router			/admin/webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "list webhook deliveries", r.Context())
+
+		var listReq dto.ListDeliveriesReq
+		if err := listReq.FromQuery(r.URL.Query()); err != nil {
+			log.Warn("failed to parse query", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		page, err := h.uc.ListDeliveries(r.Context(), listReq.ToDomain())
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidArgument):
+				log.Warn("invalid filter", logger.Err(err))
+				h.error(w, r, http.StatusBadRequest, err)
+			default:
+				log.Error("failed to list webhook deliveries", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		var listResp dto.ListDeliveriesResp
+		listResp.FromDomain(page)
+
+		h.respond(w, http.StatusOK, listResp)
+	}
+}
+
+// Redeliver godoc
+//
+//	@Summary		Redeliver a webhook
+//	@Description	send the webhook delivery again right away with all retry attempts, whatever its status is
+//	@Tags			admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Delivery ID"
+//	@Success		202	{object}	dto.DeliveryResp
+//	@Failure		400	{object}	dto.Problem
+//	@Failure		401	{object}	dto.Problem
+//	@Failure		403	{object}	dto.Problem
+//	@Failure		404	{object}	dto.Problem
+//	@Failure		500	{object}	dto.Problem
+//	@Router			/admin/webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.ForRest(h.Log, "redeliver webhook", r.Context())
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Warn("failed to parse delivery id", logger.Err(err))
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		delivery, err := h.uc.Redeliver(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				log.Warn("webhook delivery is not found", logger.Err(err))
+				h.error(w, r, http.StatusNotFound, err)
+			default:
+				log.Error("failed to redeliver webhook", logger.Err(err))
+				h.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		log.Info("webhook is scheduled for redelivery", slog.Int("id", id))
+
+		var resp dto.DeliveryResp
+		resp.FromDomain(delivery)
+
+		h.respond(w, http.StatusAccepted, resp)
+	}
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"github.com/gavv/httpexpect/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/webhook"
+	"messagio_assignment/internal/ports/rest/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newWebhookTestServer(t *testing.T) (*httpexpect.Expect, *mocks.WebhookUsecase, *mocks.Authenticator) {
+	uc := mocks.NewWebhookUsecase(t)
+	authenticator := mocks.NewAuthenticator(t)
+
+	router := chi.NewRouter()
+	NewWebhookHandler(uc, NewAuth(authenticator, nil), nil).SetupRoutes(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return httpexpect.Default(t, server.URL), uc, authenticator
+}
+
+func TestWebhookHandler(t *testing.T) {
+	at := time.Date(2024, 8, 17, 12, 0, 0, 0, time.UTC)
+	admin := &auth.Principal{Subject: "apikey:1", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	reader := &auth.Principal{Subject: "apikey:2", Scopes: []auth.Scope{auth.ScopeMessagesRead}}
+
+	failed := &webhook.Delivery{
+		ID: 3, MessageID: 42, URL: "https://crm.example.com/hooks", MessageStatus: message.StatusProcessed,
+		EventAt: at, Status: webhook.StatusFailed, Attempts: 10, NextAttemptAt: at.Add(time.Hour),
+		LastAttemptAt: &at, LastResponseCode: 503, LastError: "unexpected status 503", CreatedAt: at,
+	}
+
+	t.Run("list deliveries", func(t *testing.T) {
+		e, uc, authenticator := newWebhookTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+		uc.On("ListDeliveries", mock.Anything, webhook.ListFilter{
+			MessageID: 42, Status: webhook.StatusFailed, AfterID: 2, Limit: 1,
+		}).Return(&webhook.Page{Deliveries: []*webhook.Delivery{failed}, NextAfterID: 3}, nil).Once()
+
+		obj := e.GET("/admin/webhooks/deliveries").
+			WithHeader(APIKeyHeader, "msg_admin").
+			WithQuery("message_id", 42).
+			WithQuery("status", "failed").
+			WithQuery("cursor", domain.Cursor{AfterID: 2}.Encode()).
+			WithQuery("limit", 1).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		obj.Value("next_cursor").IsEqual(domain.Cursor{AfterID: 3}.Encode())
+		item := obj.Value("deliveries").Array().Value(0).Object()
+		item.HasValue("id", 3).
+			HasValue("message_status", "processed").
+			HasValue("status", "failed").
+			HasValue("last_response_code", 503).
+			NotContainsKey("next_attempt_at") // failed deliveries are not attempted anymore
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		e, _, authenticator := newWebhookTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Once()
+
+		e.GET("/admin/webhooks/deliveries").
+			WithHeader(APIKeyHeader, "msg_admin").
+			WithQuery("status", "lost").
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		e, uc, authenticator := newWebhookTestServer(t)
+
+		pending := *failed
+		pending.Status = webhook.StatusPending
+		pending.Attempts = 0
+
+		authenticator.On("Authenticate", mock.Anything, "msg_admin").Return(admin, nil).Twice()
+		uc.On("Redeliver", mock.Anything, 3).Return(&pending, nil).Once()
+		uc.On("Redeliver", mock.Anything, 4).Return(nil, &webhook.Error{ID: 4, Err: domain.ErrNotFound}).Once()
+
+		e.POST("/admin/webhooks/deliveries/3/redeliver").
+			WithHeader(APIKeyHeader, "msg_admin").
+			Expect().
+			Status(http.StatusAccepted).
+			JSON().Object().
+			HasValue("status", "pending").
+			HasValue("attempts", 0).
+			ContainsKey("next_attempt_at")
+
+		e.POST("/admin/webhooks/deliveries/4/redeliver").
+			WithHeader(APIKeyHeader, "msg_admin").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("admin scope is required", func(t *testing.T) {
+		e, _, authenticator := newWebhookTestServer(t)
+
+		authenticator.On("Authenticate", mock.Anything, "msg_reader").Return(reader, nil).Once()
+
+		e.POST("/admin/webhooks/deliveries/3/redeliver").
+			WithHeader(APIKeyHeader, "msg_reader").
+			Expect().
+			Status(http.StatusForbidden)
+	})
+}
@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/apikey"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/message"
+	"strconv"
+	"strings"
+)
+
+// BootstrapSubject is the subject of the principal authenticated with the bootstrap key.
+const BootstrapSubject = "apikey:bootstrap"
+
+type APIKeyUC struct {
+	Repo apikey.Repository
+
+	// bootstrapHash is the hash of the admin key from the config, empty if it is not set.
+	bootstrapHash string
+}
+
+// NewAPIKeyUC creates the usecase. The bootstrap key is accepted as an admin key without the database,
+// so the first keys can be created. It is disabled if empty.
+func NewAPIKeyUC(repo apikey.Repository, bootstrapKey string) *APIKeyUC {
+	uc := &APIKeyUC{Repo: repo}
+	if bootstrapKey != "" {
+		uc.bootstrapHash = apikey.Hash(bootstrapKey)
+	}
+	return uc
+}
+
+// CreateKey creates the key and returns it with the secret. The secret is not stored and can't be shown again.
+// callbackURL is the default webhook URL of messages created with the key, it may be empty.
+func (uc *APIKeyUC) CreateKey(ctx context.Context, name string, scopes []auth.Scope,
+	callbackURL string) (*apikey.Key, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", &apikey.Error{Err: fmt.Errorf("%w: name is empty", domain.ErrInvalidArgument)}
+	}
+	if len(scopes) == 0 {
+		return nil, "", &apikey.Error{Err: fmt.Errorf("%w: no scopes", domain.ErrInvalidArgument)}
+	}
+	for _, s := range scopes {
+		if !s.Valid() {
+			return nil, "", &apikey.Error{Err: fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidArgument, s)}
+		}
+	}
+
+	if err := message.ValidateCallbackURL(callbackURL); err != nil {
+		return nil, "", &apikey.Error{Err: fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)}
+	}
+
+	secret, prefix, err := apikey.NewSecret()
+	if err != nil {
+		return nil, "", &apikey.Error{Err: err}
+	}
+
+	key := &apikey.Key{Name: name, Prefix: prefix, Scopes: scopes, CallbackURL: callbackURL}
+	err = uc.Repo.Create(ctx, key, apikey.Hash(secret))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (uc *APIKeyUC) ListKeys(ctx context.Context) ([]*apikey.Key, error) {
+	return uc.Repo.List(ctx)
+}
+
+// RevokeKey revokes the key, requests with it are rejected right away.
+func (uc *APIKeyUC) RevokeKey(ctx context.Context, id int) error {
+	return uc.Repo.Revoke(ctx, id)
+}
+
+// Authenticate returns the principal of the API key secret.
+// It returns domain.ErrUnauthenticated for unknown and revoked keys and auth.ErrUnknownToken for other tokens.
+func (uc *APIKeyUC) Authenticate(ctx context.Context, secret string) (*auth.Principal, error) {
+	if !apikey.IsSecret(secret) {
+		return nil, &apikey.Error{Err: auth.ErrUnknownToken}
+	}
+
+	hash := apikey.Hash(secret)
+	if uc.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(uc.bootstrapHash)) == 1 {
+		return &auth.Principal{Subject: BootstrapSubject, Scopes: []auth.Scope{auth.ScopeAdmin}}, nil
+	}
+
+	key, err := uc.Repo.GetActiveByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, &apikey.Error{Err: fmt.Errorf("%w: unknown or revoked api key", domain.ErrUnauthenticated)}
+		}
+		return nil, err
+	}
+
+	return &auth.Principal{
+		Subject:     "apikey:" + strconv.Itoa(key.ID),
+		Scopes:      key.Scopes,
+		CallbackURL: key.CallbackURL,
+	}, nil
+}
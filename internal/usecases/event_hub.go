@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+type EventHubConfig struct {
+	// Buffer is the number of events kept for a slow subscriber. Further events are dropped for it.
+	Buffer int
+	// RetryInterval is how long Run waits before listening again after the source fails.
+	RetryInterval time.Duration
+}
+
+// EventHub delivers the events of the source to the subscribers of this replica.
+type EventHub struct {
+	Source message.EventSource
+
+	cfg EventHubConfig
+	log *slog.Logger
+
+	mu     sync.Mutex
+	subs   map[chan message.Event]int
+	closed bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewEventHub(source message.EventSource, log *slog.Logger, cfg EventHubConfig) *EventHub {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/event_hub"))
+
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 16
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Second
+	}
+
+	return &EventHub{
+		Source: source,
+		cfg:    cfg,
+		log:    log,
+		subs:   make(map[chan message.Event]int),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Subscribe returns the events of the message, of all messages if id is zero.
+// The channel is closed by cancel or when the hub is closed.
+func (h *EventHub) Subscribe(id int) (<-chan message.Event, func()) {
+	events := make(chan message.Event, h.cfg.Buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return events, func() {}
+	}
+	h.subs[events] = id
+
+	return events, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subs[events]; ok {
+			delete(h.subs, events)
+			close(events)
+		}
+	}
+}
+
+// Broadcast delivers the event to the subscribers without blocking.
+func (h *EventHub) Broadcast(ev message.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for events, id := range h.subs {
+		if id != 0 && id != ev.MessageID {
+			continue
+		}
+
+		select {
+		case events <- ev:
+		default:
+			h.log.Warn("subscriber is slow, event is dropped", slog.Int("id", ev.MessageID))
+		}
+	}
+}
+
+// Run is blocking. It listens to the source until ctx is done or Close is called.
+func (h *EventHub) Run(ctx context.Context) {
+	defer close(h.done)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-h.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		err := h.Source.Listen(ctx, h.Broadcast)
+		if ctx.Err() != nil {
+			return
+		}
+		h.log.Error("listen to message events", logger.Err(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.cfg.RetryInterval):
+		}
+	}
+}
+
+// Close stops Run and closes the subscriptions, so their streams end.
+func (h *EventHub) Close(ctx context.Context) error {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+
+		h.mu.Lock()
+		h.closed = true
+		for events := range h.subs {
+			delete(h.subs, events)
+			close(events)
+		}
+		h.mu.Unlock()
+	})
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("EventHub.Close: %w", ctx.Err())
+	}
+}
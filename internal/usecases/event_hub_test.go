@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/message/mocks"
+	"testing"
+	"time"
+)
+
+// listen returns Listen of a source that sends the events and then waits for ctx to be done.
+func listen(evs ...message.Event) func(context.Context, func(message.Event)) error {
+	return func(ctx context.Context, handle func(message.Event)) error {
+		for _, ev := range evs {
+			handle(ev)
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+}
+
+func TestEventHub_Broadcast(t *testing.T) {
+	t.Run("filters_by_message", func(t *testing.T) {
+		hub := NewEventHub(mocks.NewEventSource(t), nil, EventHubConfig{})
+		one, cancelOne := hub.Subscribe(1)
+		defer cancelOne()
+		all, cancelAll := hub.Subscribe(0)
+		defer cancelAll()
+
+		hub.Broadcast(message.Event{MessageID: 2, Status: message.StatusProcessing})
+		hub.Broadcast(message.Event{MessageID: 1, Status: message.StatusProcessed})
+
+		assert.Equal(t, message.Event{MessageID: 1, Status: message.StatusProcessed}, <-one)
+		assert.Empty(t, one)
+		assert.Equal(t, message.Event{MessageID: 2, Status: message.StatusProcessing}, <-all)
+		assert.Equal(t, message.Event{MessageID: 1, Status: message.StatusProcessed}, <-all)
+	})
+
+	t.Run("drops_for_slow_subscriber", func(t *testing.T) {
+		hub := NewEventHub(mocks.NewEventSource(t), nil, EventHubConfig{Buffer: 1})
+		slow, cancelSlow := hub.Subscribe(0)
+		defer cancelSlow()
+		fast, cancelFast := hub.Subscribe(0)
+		defer cancelFast()
+
+		hub.Broadcast(message.Event{MessageID: 1})
+		assert.Equal(t, message.Event{MessageID: 1}, <-fast)
+		hub.Broadcast(message.Event{MessageID: 2})
+		assert.Equal(t, message.Event{MessageID: 2}, <-fast)
+
+		assert.Equal(t, message.Event{MessageID: 1}, <-slow)
+		assert.Empty(t, slow, "the second event is dropped, Broadcast doesn't wait for the subscriber")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		hub := NewEventHub(mocks.NewEventSource(t), nil, EventHubConfig{})
+		events, cancel := hub.Subscribe(0)
+		cancel()
+		cancel()
+
+		hub.Broadcast(message.Event{MessageID: 1})
+
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+}
+
+func TestEventHub_Run(t *testing.T) {
+	t.Run("delivers_events", func(t *testing.T) {
+		source := mocks.NewEventSource(t)
+		hub := NewEventHub(source, nil, EventHubConfig{})
+		events, cancel := hub.Subscribe(0)
+		defer cancel()
+
+		source.On("Listen", mock.Anything, mock.Anything).Return(listen(message.Event{MessageID: 1})).Once()
+
+		go hub.Run(context.Background())
+
+		assert.Equal(t, message.Event{MessageID: 1}, <-events)
+		require.NoError(t, hub.Close(context.Background()))
+	})
+
+	t.Run("retries_after_error", func(t *testing.T) {
+		source := mocks.NewEventSource(t)
+		hub := NewEventHub(source, nil, EventHubConfig{RetryInterval: 10 * time.Millisecond})
+		events, cancel := hub.Subscribe(0)
+		defer cancel()
+
+		source.On("Listen", mock.Anything, mock.Anything).Return(errors.New("connection is lost")).Once()
+		source.On("Listen", mock.Anything, mock.Anything).Return(listen(message.Event{MessageID: 1})).Once()
+
+		go hub.Run(context.Background())
+
+		waitFor(t, events)
+		require.NoError(t, hub.Close(context.Background()))
+	})
+
+	t.Run("context_is_done", func(t *testing.T) {
+		source := mocks.NewEventSource(t)
+		hub := NewEventHub(source, nil, EventHubConfig{})
+
+		listening := make(chan struct{})
+		source.On("Listen", mock.Anything, mock.Anything).Return(listen()).Once().
+			Run(func(mock.Arguments) { close(listening) })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			hub.Run(ctx)
+			close(stopped)
+		}()
+		waitFor(t, listening)
+		cancel()
+
+		waitFor(t, stopped)
+	})
+}
+
+func TestEventHub_Close(t *testing.T) {
+	source := mocks.NewEventSource(t)
+	hub := NewEventHub(source, nil, EventHubConfig{})
+	events, cancel := hub.Subscribe(0)
+	defer cancel()
+
+	listening := make(chan struct{})
+	source.On("Listen", mock.Anything, mock.Anything).Return(listen()).Once().
+		Run(func(mock.Arguments) { close(listening) })
+
+	go hub.Run(context.Background())
+	waitFor(t, listening)
+
+	require.NoError(t, hub.Close(context.Background()))
+	_, ok := <-events
+	assert.False(t, ok, "the stream ends")
+
+	events, cancel = hub.Subscribe(0)
+	defer cancel()
+	_, ok = <-events
+	assert.False(t, ok, "no subscriptions after Close")
+
+	assert.NoError(t, hub.Close(context.Background()), "Close can be called again")
+}
+
+func TestEventHub_CloseTimeout(t *testing.T) {
+	hub := NewEventHub(mocks.NewEventSource(t), nil, EventHubConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, hub.Close(ctx), context.DeadlineExceeded, "Run is not started")
+}
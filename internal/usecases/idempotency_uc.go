@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"context"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
+	"messagio_assignment/internal/domain/idempotency"
+	"time"
+)
+
+const (
+	DefaultIdempotencyTTL                = 24 * time.Hour
+	DefaultIdempotencyReservationTimeout = time.Minute
+)
+
+type IdempotencyUC struct {
+	Repo idempotency.Repository
+	TTL  time.Duration
+	// ReservationTimeout is how long a request in progress holds the key. After it the request
+	// is considered lost, e.g. with a crashed replica, and the key can be reserved again.
+	ReservationTimeout time.Duration
+}
+
+func NewIdempotencyUC(repo idempotency.Repository, ttl, reservationTimeout time.Duration) *IdempotencyUC {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	if reservationTimeout <= 0 {
+		reservationTimeout = DefaultIdempotencyReservationTimeout
+	}
+	return &IdempotencyUC{Repo: repo, TTL: ttl, ReservationTimeout: reservationTimeout}
+}
+
+// Begin reserves the key of the authenticated client for the request with fingerprint and returns
+// the token of the reservation. Keys of anonymous requests are shared by all anonymous clients.
+// If the key was already used with the same fingerprint, it returns the stored response to replay.
+// It returns domain.ErrAlreadyExists while the original request is in progress
+// and idempotency.ErrFingerprintMismatch if the key was used for another request.
+func (uc *IdempotencyUC) Begin(ctx context.Context, key, fingerprint string) (string, *idempotency.Response, error) {
+	now := time.Now()
+	rec, reserved, err := uc.Repo.Reserve(ctx, principalOf(ctx), key, fingerprint,
+		now.Add(uc.TTL), now.Add(-uc.ReservationTimeout))
+	if err != nil {
+		return "", nil, err
+	}
+	if reserved {
+		return rec.Token, nil, nil
+	}
+
+	if rec.Fingerprint != fingerprint {
+		return "", nil, &idempotency.Error{Key: key, Err: idempotency.ErrFingerprintMismatch}
+	}
+	if rec.Response == nil {
+		return "", nil, &idempotency.Error{Key: key, Err: domain.ErrAlreadyExists}
+	}
+
+	return "", rec.Response, nil
+}
+
+// Complete stores the response of the request that began with the key and got the token.
+// It returns idempotency.ErrReservationLost if the key was reserved again by another request meanwhile.
+func (uc *IdempotencyUC) Complete(ctx context.Context, key, token string, resp idempotency.Response) error {
+	return uc.Repo.Complete(ctx, principalOf(ctx), key, token, resp)
+}
+
+// Release frees the key of the failed request, so it can be retried with the same key.
+// It returns idempotency.ErrReservationLost if the key was reserved again by another request meanwhile.
+func (uc *IdempotencyUC) Release(ctx context.Context, key, token string) error {
+	return uc.Repo.Release(ctx, principalOf(ctx), key, token)
+}
+
+// principalOf returns the subject of the authenticated client, empty for anonymous requests.
+func principalOf(ctx context.Context) string {
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		return p.Subject
+	}
+	return ""
+}
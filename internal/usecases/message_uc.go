@@ -2,32 +2,304 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/auth"
 	"messagio_assignment/internal/domain/message"
+	"strings"
+	"time"
 )
 
 type MessageUC struct {
-	MessageRepo      message.Repository
-	MessagesProducer message.Producer
+	MessageRepo message.Repository
+	// Events publishes created messages and status changes made by UpdateMessageStatus if it is not nil.
+	Events message.EventPublisher
 }
 
-func NewMessageUC(messageRepo message.Repository, messagesProd message.Producer) *MessageUC {
-	return &MessageUC{MessageRepo: messageRepo, MessagesProducer: messagesProd}
+func NewMessageUC(messageRepo message.Repository) *MessageUC {
+	return &MessageUC{MessageRepo: messageRepo}
 }
 
+// CreateMessage stores the message together with its outbox entry.
+// The message is produced later by OutboxRelay. If SendAt is in the future,
+// the message is written to the outbox by Scheduler at that time.
+// The callback URL of the client is used if the message has none.
 func (uc *MessageUC) CreateMessage(ctx context.Context, msg *message.Message) error {
-	err := uc.MessageRepo.Create(ctx, msg)
-	if err != nil {
+	if err := msg.Labels.Validate(); err != nil {
+		return &message.Error{Err: fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)}
+	}
+	if err := message.ValidateCallbackURL(msg.CallbackURL); err != nil {
+		return &message.Error{Err: fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)}
+	}
+	setDefaultCallbackURL(ctx, msg)
+	if msg.Expired(time.Now()) {
+		return &message.Error{Err: fmt.Errorf("%w: expires_at is in the past", domain.ErrInvalidArgument)}
+	}
+
+	msg.Status = message.StatusPending
+	if err := uc.MessageRepo.Create(ctx, msg); err != nil {
+		return err
+	}
+
+	uc.publish(ctx, msg)
+	return nil
+}
+
+// CreateMessages stores messages with their outbox entries in one transaction,
+// so OutboxRelay produces them together.
+func (uc *MessageUC) CreateMessages(ctx context.Context, msgs []*message.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i, msg := range msgs {
+		if err := msg.Labels.Validate(); err != nil {
+			return &message.Error{Err: fmt.Errorf("%w: message %d: %w", domain.ErrInvalidArgument, i, err)}
+		}
+		if err := message.ValidateCallbackURL(msg.CallbackURL); err != nil {
+			return &message.Error{Err: fmt.Errorf("%w: message %d: %w", domain.ErrInvalidArgument, i, err)}
+		}
+		setDefaultCallbackURL(ctx, msg)
+		if msg.Expired(now) {
+			return &message.Error{
+				Err: fmt.Errorf("%w: message %d: expires_at is in the past", domain.ErrInvalidArgument, i),
+			}
+		}
+
+		msg.Status = message.StatusPending
+	}
+	if err := uc.MessageRepo.CreateBatch(ctx, msgs); err != nil {
 		return err
 	}
 
-	uc.MessagesProducer.Produce(msg)
+	uc.publish(ctx, msgs...)
 	return nil
 }
 
-func (uc *MessageUC) GetStats(ctx context.Context) (*message.Stats, error) {
-	return uc.MessageRepo.GetStats(ctx)
+// setDefaultCallbackURL sets the callback URL of the authenticated client if the message has none.
+func setDefaultCallbackURL(ctx context.Context, msg *message.Message) {
+	if msg.CallbackURL != "" {
+		return
+	}
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		msg.CallbackURL = p.CallbackURL
+	}
+}
+
+func (uc *MessageUC) GetMessage(ctx context.Context, id int) (*message.Message, error) {
+	return uc.MessageRepo.GetByID(ctx, id)
+}
+
+// CancelMessage cancels the scheduled message, so it is never produced.
+// It returns domain.ErrConflict if the message is not scheduled or is already produced.
+func (uc *MessageUC) CancelMessage(ctx context.Context, id int) (*message.Message, error) {
+	msg, err := uc.MessageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.SendAt == nil || msg.Status != message.StatusPending {
+		return nil, &message.ErrorWithID{
+			ID:  id,
+			Err: fmt.Errorf("%w: message is not scheduled or is already sent", domain.ErrConflict),
+		}
+	}
+
+	msg.Status = message.StatusCancelled
+	err = uc.MessageRepo.UpdateStatus(ctx, msg, message.StatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// DeleteMessage soft deletes the message. It is purged later by RetentionPurger.
+func (uc *MessageUC) DeleteMessage(ctx context.Context, id int) error {
+	return uc.MessageRepo.Delete(ctx, id)
+}
+
+// ListMessages returns a page of messages after filter.AfterID.
+// filter.Limit is clamped to message.MaxListLimit and set to message.DefaultListLimit if it is not positive.
+func (uc *MessageUC) ListMessages(ctx context.Context, filter message.ListFilter) (*message.Page, error) {
+	if filter.MinID != nil && filter.MaxID != nil && *filter.MinID > *filter.MaxID {
+		return nil, &message.Error{Err: fmt.Errorf("%w: min_id is greater than max_id", domain.ErrInvalidArgument)}
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, &message.Error{
+			Err: fmt.Errorf("%w: created_from is not before created_to", domain.ErrInvalidArgument),
+		}
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = message.DefaultListLimit
+	case filter.Limit > message.MaxListLimit:
+		filter.Limit = message.MaxListLimit
+	}
+
+	limit := filter.Limit
+	filter.Limit++ // one more message to know if there is a next page
+
+	msgs, err := uc.MessageRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &message.Page{Messages: msgs}
+	if len(msgs) > limit {
+		page.Messages = msgs[:limit]
+		page.NextAfterID = page.Messages[limit-1].ID
+	}
+
+	return page, nil
+}
+
+// SearchMessages returns a page of messages matching the query, the most relevant first.
+// query.Limit is clamped to message.MaxSearchLimit and set to message.DefaultSearchLimit if it is not positive.
+func (uc *MessageUC) SearchMessages(ctx context.Context, query message.SearchQuery) (*message.SearchPage, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, &message.Error{Err: fmt.Errorf("%w: empty search query", domain.ErrInvalidArgument)}
+	}
+	if query.Offset < 0 || query.Offset > message.MaxSearchOffset {
+		return nil, &message.Error{
+			Err: fmt.Errorf("%w: offset is out of [0, %d]", domain.ErrInvalidArgument, message.MaxSearchOffset),
+		}
+	}
+
+	switch {
+	case query.Limit <= 0:
+		query.Limit = message.DefaultSearchLimit
+	case query.Limit > message.MaxSearchLimit:
+		query.Limit = message.MaxSearchLimit
+	}
+
+	limit := query.Limit
+	query.Limit++ // one more hit to know if there is a next page
+
+	hits, err := uc.MessageRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &message.SearchPage{Hits: hits}
+	if len(hits) > limit {
+		page.Hits = hits[:limit]
+		page.NextOffset = query.Offset + limit
+	}
+
+	return page, nil
+}
+
+// GetStats returns stats in the filter window. If the bucket is set, the window is closed:
+// the end defaults to now and the start to message.DefaultStatsBuckets buckets before the end.
+func (uc *MessageUC) GetStats(ctx context.Context, filter message.StatsFilter) (*message.Stats, error) {
+	if !filter.Bucket.Valid() {
+		return nil, &message.StatsError{
+			Err: fmt.Errorf("%w: unknown bucket %q", domain.ErrInvalidArgument, filter.Bucket),
+		}
+	}
+	if filter.GroupByLabel != "" && !message.ValidLabelKey(filter.GroupByLabel) {
+		return nil, &message.StatsError{
+			Err: fmt.Errorf("%w: invalid label key %q", domain.ErrInvalidArgument, filter.GroupByLabel),
+		}
+	}
+
+	if filter.Bucket != message.BucketNone {
+		step := filter.Bucket.Duration()
+
+		if filter.To == nil {
+			to := time.Now()
+			filter.To = &to
+		}
+		if filter.From == nil {
+			from := filter.To.Add(-message.DefaultStatsBuckets * step)
+			filter.From = &from
+		}
+
+		if filter.To.Sub(*filter.From) > message.MaxStatsBuckets*step {
+			return nil, &message.StatsError{
+				Err: fmt.Errorf("%w: more than %d buckets", domain.ErrInvalidArgument, message.MaxStatsBuckets),
+			}
+		}
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, &message.StatsError{
+			Err: fmt.Errorf("%w: window start is not before its end", domain.ErrInvalidArgument),
+		}
+	}
+
+	return uc.MessageRepo.GetStats(ctx, filter)
+}
+
+// updateStatusAttempts limits retries of UpdateMessageStatus when the status is changed concurrently.
+const updateStatusAttempts = 3
+
+// UpdateMessageStatus moves the message to the status. Setting the current status again is a no-op,
+// so redelivered events are accepted. Illegal transitions return message.ErrInvalidTransition.
+// A message processed after its expiry is recorded as expired instead. The change is published to Events.
+func (uc *MessageUC) UpdateMessageStatus(ctx context.Context, id int, status message.Status) (*message.Message, error) {
+	if !status.Valid() {
+		return nil, &message.ErrorWithID{
+			ID:  id,
+			Err: fmt.Errorf("%w: unknown status %q", domain.ErrInvalidArgument, status),
+		}
+	}
+
+	var err error
+	for range updateStatusAttempts {
+		var msg *message.Message
+		msg, err = uc.MessageRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		from, to := msg.Status, status
+		if to == message.StatusProcessed && msg.Expired(time.Now()) {
+			to = message.StatusExpired
+		}
+		if from == to {
+			return msg, nil
+		}
+		if !from.CanTransitionTo(to) {
+			return nil, &message.ErrorWithID{ID: id, Err: &message.TransitionError{From: from, To: to}}
+		}
+
+		msg.Status = to
+		err = uc.MessageRepo.UpdateStatus(ctx, msg, from)
+		if err == nil {
+			uc.publish(ctx, msg)
+			return msg, nil
+		}
+		if !errors.Is(err, domain.ErrConflict) {
+			return nil, err
+		}
+	}
+
+	return nil, err
 }
 
-func (uc *MessageUC) UpdateProcessedMessage(ctx context.Context, msg *message.Message) error {
-	return uc.MessageRepo.UpdateProcessed(ctx, msg)
+// publish sends the current status of the messages. The event time is the processing time
+// of processed messages, the creation time of created ones and now otherwise.
+func (uc *MessageUC) publish(ctx context.Context, msgs ...*message.Message) {
+	if uc.Events == nil {
+		return
+	}
+
+	now := time.Now()
+	evs := make([]message.Event, len(msgs))
+	for i, msg := range msgs {
+		evs[i] = message.Event{MessageID: msg.ID, Status: msg.Status, At: now}
+		switch {
+		case msg.ProcessedAt != nil:
+			evs[i].At = *msg.ProcessedAt
+		case msg.Status == message.StatusPending && !msg.CreatedAt.IsZero():
+			evs[i].At = msg.CreatedAt
+		}
+	}
+	uc.Events.Publish(ctx, evs...)
 }
@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+type OutboxRelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// OutboxRelay drains the outbox into the messages producer.
+// Messages are written to the outbox by message.Repository.Create, so they survive restarts and broker outages.
+type OutboxRelay struct {
+	OutboxRepo       message.OutboxRepository
+	MessagesProducer message.Producer
+
+	cfg OutboxRelayConfig
+	log *slog.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewOutboxRelay(outboxRepo message.OutboxRepository, messagesProd message.Producer,
+	log *slog.Logger, cfg OutboxRelayConfig) *OutboxRelay {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/outbox_relay"))
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 500 * time.Millisecond
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &OutboxRelay{
+		OutboxRepo:       outboxRepo,
+		MessagesProducer: messagesProd,
+		cfg:              cfg,
+		log:              log,
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// Run is blocking. It relays the outbox every interval until ctx is done or Close is called.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain relays batches until the outbox has no more pending messages.
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.log.Error("relay outbox", logger.Err(err))
+			return
+		}
+		if n > 0 {
+			r.log.Debug("outbox is relayed", slog.Int("count", n))
+		}
+		if n < r.cfg.BatchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stop:
+			return
+		default:
+		}
+	}
+}
+
+// RelayOnce produces one batch of pending messages and returns its size.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	return r.OutboxRepo.RelayPending(ctx, r.cfg.BatchSize, func(msgs []*message.Message) error {
+		return r.MessagesProducer.Produce(msgs...)
+	})
+}
+
+// Close stops Run and waits for the current batch to finish.
+func (r *OutboxRelay) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("OutboxRelay.Close: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/message/mocks"
+	"testing"
+)
+
+// relayPending returns RelayPending of a repository with the messages in the outbox,
+// it passes them to relay like the store does.
+func relayPending(msgs ...*message.Message) func(context.Context, int, func([]*message.Message) error) (int, error) {
+	return func(_ context.Context, limit int, relay func([]*message.Message) error) (int, error) {
+		batch := msgs[:min(limit, len(msgs))]
+		if len(batch) == 0 {
+			return 0, nil
+		}
+		if err := relay(batch); err != nil {
+			return 0, err
+		}
+		return len(batch), nil
+	}
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	msgs := []*message.Message{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	t.Run("batch", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		producer := mocks.NewProducer(t)
+		relay := NewOutboxRelay(repo, producer, nil, OutboxRelayConfig{BatchSize: 2})
+
+		repo.On("RelayPending", mock.Anything, 2, mock.Anything).Return(relayPending(msgs...)).Once()
+		producer.On("Produce", msgs[0], msgs[1]).Return(nil).Once()
+
+		n, err := relay.RelayOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		relay := NewOutboxRelay(repo, mocks.NewProducer(t), nil, OutboxRelayConfig{BatchSize: 2})
+
+		repo.On("RelayPending", mock.Anything, 2, mock.Anything).Return(relayPending()).Once()
+
+		n, err := relay.RelayOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n, "nothing is produced")
+	})
+
+	t.Run("producer_error", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		producer := mocks.NewProducer(t)
+		relay := NewOutboxRelay(repo, producer, nil, OutboxRelayConfig{BatchSize: 2})
+
+		brokerErr := errors.New("broker is down")
+		repo.On("RelayPending", mock.Anything, 2, mock.Anything).Return(relayPending(msgs...)).Once()
+		producer.On("Produce", msgs[0], msgs[1]).Return(brokerErr).Once()
+
+		n, err := relay.RelayOnce(context.Background())
+		assert.ErrorIs(t, err, brokerErr, "the entries are kept in the outbox")
+		assert.Zero(t, n)
+	})
+}
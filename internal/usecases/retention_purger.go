@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+type RetentionPurgerConfig struct {
+	Interval time.Duration
+	// MaxAge is how long deleted and processed messages are kept.
+	MaxAge    time.Duration
+	BatchSize int
+}
+
+// RetentionPurger hard deletes messages that were soft deleted or processed more than MaxAge ago.
+// Messages are deleted in batches, so the table is not locked for long.
+type RetentionPurger struct {
+	MessageRepo message.Repository
+
+	cfg RetentionPurgerConfig
+	log *slog.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewRetentionPurger(messageRepo message.Repository, log *slog.Logger,
+	cfg RetentionPurgerConfig) *RetentionPurger {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/retention_purger"))
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 30 * 24 * time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+
+	return &RetentionPurger{
+		MessageRepo: messageRepo,
+		cfg:         cfg,
+		log:         log,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Run is blocking. It purges messages every interval until ctx is done or Close is called.
+func (p *RetentionPurger) Run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain purges batches until there are no more expired messages.
+func (p *RetentionPurger) drain(ctx context.Context) {
+	before := time.Now().Add(-p.cfg.MaxAge)
+
+	total := 0
+	defer func() {
+		if total > 0 {
+			p.log.Info("messages are purged", slog.Int("count", total))
+		}
+	}()
+
+	for {
+		n, err := p.PurgeOnce(ctx, before)
+		if err != nil {
+			p.log.Error("purge messages", logger.Err(err))
+			return
+		}
+		total += n
+		if n < p.cfg.BatchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stop:
+			return
+		default:
+		}
+	}
+}
+
+// PurgeOnce deletes one batch of messages deleted or processed before the time and returns its size.
+func (p *RetentionPurger) PurgeOnce(ctx context.Context, before time.Time) (int, error) {
+	return p.MessageRepo.Purge(ctx, before, p.cfg.BatchSize)
+}
+
+// Close stops Run and waits for the current batch to finish.
+func (p *RetentionPurger) Close(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("RetentionPurger.Close: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message/mocks"
+	"testing"
+	"time"
+)
+
+func TestRetentionPurger_PurgeOnce(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	purger := NewRetentionPurger(repo, nil, RetentionPurgerConfig{BatchSize: 10})
+	before := time.Now().Add(-time.Hour)
+
+	repo.On("Purge", mock.Anything, before, 10).Return(4, nil).Once()
+
+	n, err := purger.PurgeOnce(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+}
+
+func TestRetentionPurger_Run(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	purger := NewRetentionPurger(repo, nil,
+		RetentionPurgerConfig{Interval: time.Hour, MaxAge: 24 * time.Hour, BatchSize: 2})
+
+	// All batches of one run use the same time, so messages that become expired meanwhile wait for the next run.
+	var before time.Time
+	sameBefore := mock.MatchedBy(func(at time.Time) bool {
+		if before.IsZero() {
+			before = at
+		}
+		return at.Equal(before)
+	})
+	startedAt := time.Now()
+
+	drained := make(chan struct{})
+	repo.On("Purge", mock.Anything, sameBefore, 2).Return(2, nil).Twice()
+	repo.On("Purge", mock.Anything, sameBefore, 2).Return(0, nil).Once().
+		Run(func(mock.Arguments) { close(drained) })
+
+	go purger.Run(context.Background())
+	waitFor(t, drained)
+	require.NoError(t, purger.Close(context.Background()))
+
+	assert.WithinRange(t, before, startedAt.Add(-24*time.Hour), time.Now().Add(-24*time.Hour))
+}
@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+type SchedulerConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Scheduler writes messages with message.Message.SendAt to the outbox when their time comes.
+// Then they are produced by OutboxRelay as usual.
+type Scheduler struct {
+	OutboxRepo message.OutboxRepository
+
+	cfg SchedulerConfig
+	log *slog.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewScheduler(outboxRepo message.OutboxRepository, log *slog.Logger, cfg SchedulerConfig) *Scheduler {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/scheduler"))
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &Scheduler{
+		OutboxRepo: outboxRepo,
+		cfg:        cfg,
+		log:        log,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run is blocking. It enqueues due messages every interval until ctx is done or Close is called.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain enqueues batches until there are no more due messages.
+func (s *Scheduler) drain(ctx context.Context) {
+	for {
+		n, err := s.EnqueueOnce(ctx)
+		if err != nil {
+			s.log.Error("enqueue scheduled messages", logger.Err(err))
+			return
+		}
+		if n > 0 {
+			s.log.Debug("scheduled messages are enqueued", slog.Int("count", n))
+		}
+		if n < s.cfg.BatchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// EnqueueOnce writes one batch of due messages to the outbox and returns its size.
+func (s *Scheduler) EnqueueOnce(ctx context.Context) (int, error) {
+	return s.OutboxRepo.EnqueueScheduled(ctx, s.cfg.BatchSize)
+}
+
+// Close stops Run and waits for the current batch to finish.
+func (s *Scheduler) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Scheduler.Close: %w", ctx.Err())
+	}
+}
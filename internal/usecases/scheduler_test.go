@@ -0,0 +1,21 @@
+package usecases
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message/mocks"
+	"testing"
+)
+
+func TestScheduler_EnqueueOnce(t *testing.T) {
+	repo := mocks.NewOutboxRepository(t)
+	scheduler := NewScheduler(repo, nil, SchedulerConfig{BatchSize: 10})
+
+	repo.On("EnqueueScheduled", mock.Anything, 10).Return(3, nil).Once()
+
+	n, err := scheduler.EnqueueOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
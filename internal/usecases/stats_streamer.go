@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+var ErrStatsStreamerClosed = errors.New("stats streamer is closed")
+
+type StatsStreamerConfig struct {
+	// Interval is the minimum time between two deltas, the stats are gotten at most once per interval.
+	Interval time.Duration
+	// Buffer is the number of deltas kept for a slow subscriber. It is unsubscribed if the buffer is full.
+	Buffer int
+}
+
+// StatsStreamer pushes the changes of the message stats to subscribers when messages are created
+// or processed. The stats are gotten once for all subscribers and not gotten if there are none.
+type StatsStreamer struct {
+	MessageRepo message.Repository
+	Events      *EventHub
+
+	cfg StatsStreamerConfig
+	log *slog.Logger
+
+	mu     sync.Mutex
+	last   *message.Stats
+	subs   map[chan message.StatsDelta]struct{}
+	closed bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewStatsStreamer(messageRepo message.Repository, events *EventHub, log *slog.Logger,
+	cfg StatsStreamerConfig) *StatsStreamer {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/stats_streamer"))
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 16
+	}
+
+	return &StatsStreamer{
+		MessageRepo: messageRepo,
+		Events:      events,
+		cfg:         cfg,
+		log:         log,
+		subs:        make(map[chan message.StatsDelta]struct{}),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe returns the current stats and the channel of their changes. Applying the deltas
+// to the snapshot gives the current stats. The channel is closed by cancel, when the subscriber
+// is too slow or when the streamer is closed.
+// The last snapshot is shared by the subscribers, the stats are gotten only if there is none.
+func (s *StatsStreamer) Subscribe(ctx context.Context) (*message.Stats, <-chan message.StatsDelta, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, nil, nil, ErrStatsStreamerClosed
+	}
+
+	if s.last == nil {
+		// Run and the other subscribers are not blocked while the stats are gotten.
+		s.mu.Unlock()
+		stats, err := s.MessageRepo.GetStats(ctx, message.StatsFilter{})
+		s.mu.Lock()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if s.closed {
+			return nil, nil, nil, ErrStatsStreamerClosed
+		}
+
+		// The other subscribers get the changes since the last snapshot before it is replaced.
+		s.update(stats)
+	}
+
+	deltas := make(chan message.StatsDelta, s.cfg.Buffer)
+	s.subs[deltas] = struct{}{}
+
+	return s.last, deltas, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unsubscribe(deltas)
+	}, nil
+}
+
+// Run is blocking. It sends deltas at most once per interval after messages are created or processed
+// until ctx is done or Close is called.
+func (s *StatsStreamer) Run(ctx context.Context) {
+	defer close(s.done)
+
+	events, cancel := s.Events.Subscribe(0)
+	defer cancel()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	changed := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			changed = true
+		case <-ticker.C:
+			if !changed {
+				continue
+			}
+
+			s.mu.Lock()
+			subscribed := len(s.subs) > 0
+			if !subscribed {
+				// The next subscriber gets a fresh snapshot.
+				s.last = nil
+			}
+			s.mu.Unlock()
+
+			if subscribed {
+				if err := s.refresh(ctx); err != nil {
+					s.log.Error("refresh stats", logger.Err(err))
+					continue
+				}
+			}
+			changed = false
+		}
+	}
+}
+
+// refresh gets the stats and sends the delta since the last snapshot. mu is held only to send the delta.
+func (s *StatsStreamer) refresh(ctx context.Context) error {
+	stats, err := s.MessageRepo.GetStats(ctx, message.StatsFilter{})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update(stats)
+
+	return nil
+}
+
+// update sends the delta since the last snapshot and replaces it. It must be called with mu held.
+func (s *StatsStreamer) update(stats *message.Stats) {
+	if s.last != nil {
+		if delta := stats.Delta(s.last); !delta.IsZero() {
+			for deltas := range s.subs {
+				select {
+				case deltas <- delta:
+				default:
+					s.log.Warn("subscriber is slow, it is unsubscribed")
+					s.unsubscribe(deltas)
+				}
+			}
+		}
+	}
+	s.last = stats
+}
+
+// unsubscribe must be called with mu held.
+func (s *StatsStreamer) unsubscribe(deltas chan message.StatsDelta) {
+	if _, ok := s.subs[deltas]; ok {
+		delete(s.subs, deltas)
+		close(deltas)
+	}
+}
+
+// Close stops Run and closes the subscriptions.
+func (s *StatsStreamer) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+
+		s.mu.Lock()
+		s.closed = true
+		for deltas := range s.subs {
+			s.unsubscribe(deltas)
+		}
+		s.mu.Unlock()
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("StatsStreamer.Close: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message"
+	"messagio_assignment/internal/domain/message/mocks"
+	"testing"
+	"time"
+)
+
+func newTestStatsStreamer(t *testing.T, cfg StatsStreamerConfig) (*StatsStreamer, *mocks.Repository, *EventHub) {
+	repo := mocks.NewRepository(t)
+	hub := NewEventHub(mocks.NewEventSource(t), nil, EventHubConfig{})
+	return NewStatsStreamer(repo, hub, nil, cfg), repo, hub
+}
+
+// waitSubscribed waits for Run to subscribe to the hub, so broadcast events are not missed.
+func waitSubscribed(t *testing.T, hub *EventHub) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.subs) > 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestStatsStreamer_Subscribe(t *testing.T) {
+	t.Run("snapshot", func(t *testing.T) {
+		streamer, repo, _ := newTestStatsStreamer(t, StatsStreamerConfig{})
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: 1}, nil).Once()
+
+		stats, deltas, cancel, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, &message.Stats{All: 1}, stats)
+
+		cancel()
+		cancel()
+		_, ok := <-deltas
+		assert.False(t, ok)
+	})
+
+	t.Run("repo_error", func(t *testing.T) {
+		streamer, repo, _ := newTestStatsStreamer(t, StatsStreamerConfig{})
+		dbErr := errors.New("db is down")
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(nil, dbErr).Once()
+
+		_, _, _, err := streamer.Subscribe(context.Background())
+		assert.ErrorIs(t, err, dbErr)
+	})
+
+	t.Run("cached_snapshot", func(t *testing.T) {
+		streamer, repo, _ := newTestStatsStreamer(t, StatsStreamerConfig{})
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).
+			Run(func(mock.Arguments) {
+				require.True(t, streamer.mu.TryLock(), "the stats are gotten without the lock")
+				streamer.mu.Unlock()
+			}).
+			Return(&message.Stats{All: 1}, nil).Once()
+
+		for range 2 {
+			stats, _, cancel, err := streamer.Subscribe(context.Background())
+			require.NoError(t, err)
+			defer cancel()
+			assert.Equal(t, &message.Stats{All: 1}, stats)
+		}
+	})
+
+	t.Run("slow_subscriber_is_unsubscribed", func(t *testing.T) {
+		streamer, repo, _ := newTestStatsStreamer(t, StatsStreamerConfig{Buffer: 1})
+		for all := 1; all <= 3; all++ {
+			repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: all}, nil).Once()
+		}
+
+		_, slow, cancelSlow, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		defer cancelSlow()
+		require.NoError(t, streamer.refresh(context.Background()))
+		_, fast, cancelFast, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		defer cancelFast()
+		// The buffer of the first subscriber is full, the delta doesn't fit.
+		require.NoError(t, streamer.refresh(context.Background()))
+
+		assert.Equal(t, message.StatsDelta{All: 1}, <-slow)
+		_, ok := <-slow
+		assert.False(t, ok, "the slow subscriber is unsubscribed")
+
+		assert.Equal(t, message.StatsDelta{All: 1}, <-fast)
+		assert.Empty(t, fast)
+	})
+}
+
+func TestStatsStreamer_Run(t *testing.T) {
+	t.Run("throttled", func(t *testing.T) {
+		interval := 200 * time.Millisecond
+		streamer, repo, hub := newTestStatsStreamer(t, StatsStreamerConfig{Interval: interval})
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: 1}, nil).Once()
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: 6}, nil).Once()
+
+		_, deltas, cancel, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		defer cancel()
+
+		go streamer.Run(context.Background())
+		waitSubscribed(t, hub)
+		for id := 1; id <= 5; id++ {
+			hub.Broadcast(message.Event{MessageID: id, Status: message.StatusProcessing})
+		}
+
+		waitFor(t, deltas)
+		// The stats are not gotten again until there are new events.
+		time.Sleep(2 * interval)
+		assert.Empty(t, deltas, "one delta for all events of the interval")
+
+		require.NoError(t, streamer.Close(context.Background()))
+	})
+
+	t.Run("delta", func(t *testing.T) {
+		streamer, repo, hub := newTestStatsStreamer(t, StatsStreamerConfig{Interval: 10 * time.Millisecond})
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).
+			Return(&message.Stats{All: 1, ByStatus: map[message.Status]int{message.StatusProcessing: 1}}, nil).Once()
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).
+			Return(&message.Stats{All: 1, Processed: 1, ByStatus: map[message.Status]int{message.StatusProcessed: 1}}, nil).
+			Once()
+
+		_, deltas, cancel, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		defer cancel()
+
+		go streamer.Run(context.Background())
+		waitSubscribed(t, hub)
+		hub.Broadcast(message.Event{MessageID: 1, Status: message.StatusProcessed})
+
+		assert.Equal(t, message.StatsDelta{
+			Processed: 1,
+			ByStatus:  map[message.Status]int{message.StatusProcessing: -1, message.StatusProcessed: 1},
+		}, <-deltas)
+		require.NoError(t, streamer.Close(context.Background()))
+	})
+
+	t.Run("retries_after_error", func(t *testing.T) {
+		streamer, repo, hub := newTestStatsStreamer(t, StatsStreamerConfig{Interval: 10 * time.Millisecond})
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: 1}, nil).Once()
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(nil, errors.New("db is down")).Once()
+		repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{All: 2}, nil).Once()
+
+		_, deltas, cancel, err := streamer.Subscribe(context.Background())
+		require.NoError(t, err)
+		defer cancel()
+
+		go streamer.Run(context.Background())
+		waitSubscribed(t, hub)
+		hub.Broadcast(message.Event{MessageID: 2, Status: message.StatusProcessing})
+
+		assert.Equal(t, message.StatsDelta{All: 1}, <-deltas)
+		require.NoError(t, streamer.Close(context.Background()))
+	})
+
+	t.Run("no_subscribers", func(t *testing.T) {
+		streamer, _, hub := newTestStatsStreamer(t, StatsStreamerConfig{Interval: 10 * time.Millisecond})
+
+		go streamer.Run(context.Background())
+		waitSubscribed(t, hub)
+		hub.Broadcast(message.Event{MessageID: 1, Status: message.StatusProcessing})
+
+		// The mock fails the test if the stats are gotten.
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, streamer.Close(context.Background()))
+	})
+}
+
+func TestStatsStreamer_Close(t *testing.T) {
+	streamer, repo, _ := newTestStatsStreamer(t, StatsStreamerConfig{})
+	repo.On("GetStats", mock.Anything, message.StatsFilter{}).Return(&message.Stats{}, nil).Once()
+
+	_, deltas, cancel, err := streamer.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer cancel()
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelCtx()
+	assert.ErrorIs(t, streamer.Close(ctx), context.DeadlineExceeded, "Run is not started")
+
+	_, ok := <-deltas
+	assert.False(t, ok, "the stream ends")
+
+	_, _, _, err = streamer.Subscribe(context.Background())
+	assert.ErrorIs(t, err, ErrStatsStreamerClosed)
+}
@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"messagio_assignment/internal/domain/webhook"
+	"messagio_assignment/internal/logger"
+	"sync"
+	"time"
+)
+
+type WebhookDispatcherConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// Lease is how long claimed deliveries are hidden from other replicas. It must be longer than a send.
+	Lease time.Duration
+	Retry webhook.RetryPolicy
+}
+
+// WebhookDispatcher sends due webhook deliveries and stores the results of attempts.
+// Deliveries are enqueued with message status updates, so they survive restarts and receiver outages.
+type WebhookDispatcher struct {
+	Repo   webhook.Repository
+	Sender webhook.Sender
+
+	cfg WebhookDispatcherConfig
+	log *slog.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewWebhookDispatcher(repo webhook.Repository, sender webhook.Sender, log *slog.Logger,
+	cfg WebhookDispatcherConfig) *WebhookDispatcher {
+	if log == nil {
+		log = logger.NewEraseLogger()
+	}
+	log = log.With(slog.String("component", "usecases/webhook_dispatcher"))
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry.MaxAttempts = 10
+	}
+	if cfg.Retry.MinBackoff <= 0 {
+		cfg.Retry.MinBackoff = 10 * time.Second
+	}
+	if cfg.Retry.MaxBackoff < cfg.Retry.MinBackoff {
+		cfg.Retry.MaxBackoff = max(time.Hour, cfg.Retry.MinBackoff)
+	}
+
+	return &WebhookDispatcher{
+		Repo:   repo,
+		Sender: sender,
+		cfg:    cfg,
+		log:    log,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Run is blocking. It sends due deliveries every interval until ctx is done or Close is called.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain sends batches until there are no more due deliveries.
+func (d *WebhookDispatcher) drain(ctx context.Context) {
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil {
+			d.log.Error("dispatch webhooks", logger.Err(err))
+			return
+		}
+		if n > 0 {
+			d.log.Debug("webhooks are dispatched", slog.Int("count", n))
+		}
+		if n < d.cfg.BatchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stop:
+			return
+		default:
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries concurrently and returns its size.
+// Failed attempts are not errors, they are stored and retried after the backoff.
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.Repo.ClaimDue(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.send(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *webhook.Delivery) {
+	log := d.log.With(slog.Int("id", delivery.ID), slog.Int("message_id", delivery.MessageID))
+
+	code, err := d.Sender.Send(ctx, delivery)
+	delivery.Record(time.Now(), code, err, d.cfg.Retry)
+
+	switch delivery.Status {
+	case webhook.StatusSucceeded:
+		log.Debug("webhook is delivered", slog.Int("code", code))
+	case webhook.StatusFailed:
+		log.Warn("webhook delivery failed, attempts are exhausted",
+			slog.Int("attempts", delivery.Attempts), logger.Err(err))
+	default:
+		log.Info("webhook attempt failed, it is retried later",
+			slog.Int("attempts", delivery.Attempts), slog.Time("next_attempt_at", delivery.NextAttemptAt),
+			logger.Err(err))
+	}
+
+	// the result is stored even if ctx is done, otherwise the attempt is repeated after the lease
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := d.Repo.SaveAttempt(saveCtx, delivery); err != nil {
+		log.Error("save webhook attempt", logger.Err(err))
+	}
+}
+
+// Close stops Run and waits for the current batch to finish.
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("WebhookDispatcher.Close: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/webhook"
+	"messagio_assignment/internal/domain/webhook/mocks"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = webhook.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: 3 * time.Minute}
+
+// forDelivery matches the delivery with the id.
+func forDelivery(id int) any {
+	return mock.MatchedBy(func(d *webhook.Delivery) bool { return d.ID == id })
+}
+
+func TestWebhookDispatcher_DispatchOnce(t *testing.T) {
+	t.Run("records_attempts", func(t *testing.T) {
+		repo, sender := mocks.NewRepository(t), mocks.NewSender(t)
+		dispatcher := NewWebhookDispatcher(repo, sender, nil,
+			WebhookDispatcherConfig{BatchSize: 10, Lease: time.Minute, Retry: testRetryPolicy})
+
+		succeeded := &webhook.Delivery{ID: 1, Status: webhook.StatusPending}
+		retried := &webhook.Delivery{ID: 2, Status: webhook.StatusPending}
+		backedOff := &webhook.Delivery{ID: 3, Status: webhook.StatusPending, Attempts: 1}
+		failed := &webhook.Delivery{ID: 4, Status: webhook.StatusPending, Attempts: 2}
+		deliveries := []*webhook.Delivery{succeeded, retried, backedOff, failed}
+
+		repo.On("ClaimDue", mock.Anything, 10, time.Minute).Return(deliveries, nil).Once()
+		sender.On("Send", mock.Anything, forDelivery(1)).Return(204, nil).Once()
+		sender.On("Send", mock.Anything, forDelivery(2)).Return(503, errors.New("unexpected status 503")).Once()
+		sender.On("Send", mock.Anything, forDelivery(3)).Return(0, errors.New("connection refused")).Once()
+		sender.On("Send", mock.Anything, forDelivery(4)).Return(500, errors.New("unexpected status 500")).Once()
+		for _, d := range deliveries {
+			repo.On("SaveAttempt", mock.Anything, d).Return(nil).Once()
+		}
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+
+		assert.Equal(t, webhook.StatusSucceeded, succeeded.Status)
+		assert.Equal(t, 1, succeeded.Attempts)
+		assert.Equal(t, 204, succeeded.LastResponseCode)
+		assert.NotNil(t, succeeded.DeliveredAt)
+
+		assert.Equal(t, webhook.StatusPending, retried.Status)
+		assert.Equal(t, 503, retried.LastResponseCode)
+		assert.Equal(t, "unexpected status 503", retried.LastError)
+		assert.Equal(t, time.Minute, retried.NextAttemptAt.Sub(*retried.LastAttemptAt))
+
+		assert.Equal(t, webhook.StatusPending, backedOff.Status)
+		assert.Equal(t, 2*time.Minute, backedOff.NextAttemptAt.Sub(*backedOff.LastAttemptAt), "the backoff doubles")
+
+		assert.Equal(t, webhook.StatusFailed, failed.Status, "attempts are exhausted")
+		assert.Equal(t, 3, failed.Attempts)
+		assert.Nil(t, failed.DeliveredAt)
+	})
+
+	t.Run("sends_concurrently", func(t *testing.T) {
+		repo, sender := mocks.NewRepository(t), mocks.NewSender(t)
+		dispatcher := NewWebhookDispatcher(repo, sender, nil, WebhookDispatcherConfig{Retry: testRetryPolicy})
+
+		repo.On("ClaimDue", mock.Anything, 50, time.Minute).
+			Return([]*webhook.Delivery{{ID: 1}, {ID: 2}}, nil).Once()
+		// Each send waits for the other one, so they never finish if sent one by one.
+		var sending sync.WaitGroup
+		sending.Add(2)
+		sender.On("Send", mock.Anything, mock.Anything).Return(200, nil).Twice().
+			Run(func(mock.Arguments) {
+				sending.Done()
+				sending.Wait()
+			})
+		repo.On("SaveAttempt", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		dispatched := make(chan struct{})
+		go func() {
+			n, err := dispatcher.DispatchOnce(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 2, n)
+			close(dispatched)
+		}()
+
+		waitFor(t, dispatched)
+	})
+
+	t.Run("saves_after_context_is_done", func(t *testing.T) {
+		repo, sender := mocks.NewRepository(t), mocks.NewSender(t)
+		dispatcher := NewWebhookDispatcher(repo, sender, nil, WebhookDispatcherConfig{Retry: testRetryPolicy})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		delivery := &webhook.Delivery{ID: 1}
+		repo.On("ClaimDue", mock.Anything, 50, time.Minute).Return([]*webhook.Delivery{delivery}, nil).Once()
+		sender.On("Send", mock.Anything, delivery).Return(0, context.Canceled).Once().
+			Run(func(mock.Arguments) { cancel() })
+		repo.On("SaveAttempt", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }), delivery).
+			Return(nil).Once()
+
+		_, err := dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, webhook.StatusPending, delivery.Status, "the attempt is retried after the backoff")
+	})
+
+	t.Run("save_error", func(t *testing.T) {
+		repo, sender := mocks.NewRepository(t), mocks.NewSender(t)
+		dispatcher := NewWebhookDispatcher(repo, sender, nil, WebhookDispatcherConfig{Retry: testRetryPolicy})
+
+		repo.On("ClaimDue", mock.Anything, 50, time.Minute).Return([]*webhook.Delivery{{ID: 1}}, nil).Once()
+		sender.On("Send", mock.Anything, forDelivery(1)).Return(200, nil).Once()
+		repo.On("SaveAttempt", mock.Anything, forDelivery(1)).Return(errors.New("db is down")).Once()
+
+		n, err := dispatcher.DispatchOnce(context.Background())
+		require.NoError(t, err, "the delivery is sent again after the lease")
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("claim_error", func(t *testing.T) {
+		repo := mocks.NewRepository(t)
+		dispatcher := NewWebhookDispatcher(repo, mocks.NewSender(t), nil, WebhookDispatcherConfig{})
+
+		dbErr := errors.New("db is down")
+		repo.On("ClaimDue", mock.Anything, 50, time.Minute).Return(nil, dbErr).Once()
+
+		_, err := dispatcher.DispatchOnce(context.Background())
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
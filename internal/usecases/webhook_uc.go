@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"messagio_assignment/internal/domain"
+	"messagio_assignment/internal/domain/webhook"
+)
+
+// WebhookUC gives admins access to the webhook delivery log.
+type WebhookUC struct {
+	Repo webhook.Repository
+}
+
+func NewWebhookUC(repo webhook.Repository) *WebhookUC {
+	return &WebhookUC{Repo: repo}
+}
+
+// ListDeliveries returns a page of deliveries after filter.AfterID.
+// filter.Limit is clamped to webhook.MaxListLimit and set to webhook.DefaultListLimit if it is not positive.
+func (uc *WebhookUC) ListDeliveries(ctx context.Context, filter webhook.ListFilter) (*webhook.Page, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, &webhook.Error{Err: fmt.Errorf("%w: unknown status %q", domain.ErrInvalidArgument, filter.Status)}
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = webhook.DefaultListLimit
+	case filter.Limit > webhook.MaxListLimit:
+		filter.Limit = webhook.MaxListLimit
+	}
+
+	limit := filter.Limit
+	filter.Limit++ // one more delivery to know if there is a next page
+
+	deliveries, err := uc.Repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &webhook.Page{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextAfterID = page.Deliveries[limit-1].ID
+	}
+
+	return page, nil
+}
+
+// Redeliver sends the delivery again right away with all attempts, whatever its status is.
+func (uc *WebhookUC) Redeliver(ctx context.Context, id int) (*webhook.Delivery, error) {
+	return uc.Repo.Redeliver(ctx, id)
+}
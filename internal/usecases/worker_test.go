@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messagio_assignment/internal/domain/message/mocks"
+	"messagio_assignment/internal/domain/webhook"
+	webhookmocks "messagio_assignment/internal/domain/webhook/mocks"
+	"testing"
+	"time"
+)
+
+// worker handles batches every interval until ctx is done or Close is called, like OutboxRelay.
+type worker interface {
+	Run(ctx context.Context)
+	Close(ctx context.Context) error
+}
+
+// expectBatch expects a batch call of the worker that returns the number of handled entries and the error.
+type expectBatch func(n int, err error) *mock.Call
+
+// workerBatchSize is the batch size of the workers in TestWorkers, a batch of this size is full.
+const workerBatchSize = 2
+
+func TestWorkers(t *testing.T) {
+	workers := []struct {
+		name string
+		// newWorker returns the worker with the interval and workerBatchSize.
+		newWorker func(t *testing.T, interval time.Duration) (worker, expectBatch)
+	}{
+		{
+			name: "outbox_relay",
+			newWorker: func(t *testing.T, interval time.Duration) (worker, expectBatch) {
+				repo := mocks.NewOutboxRepository(t)
+				relay := NewOutboxRelay(repo, mocks.NewProducer(t), nil,
+					OutboxRelayConfig{Interval: interval, BatchSize: workerBatchSize})
+				return relay, func(n int, err error) *mock.Call {
+					return repo.On("RelayPending", mock.Anything, workerBatchSize, mock.Anything).Return(n, err)
+				}
+			},
+		},
+		{
+			name: "retention_purger",
+			newWorker: func(t *testing.T, interval time.Duration) (worker, expectBatch) {
+				repo := mocks.NewRepository(t)
+				purger := NewRetentionPurger(repo, nil,
+					RetentionPurgerConfig{Interval: interval, BatchSize: workerBatchSize})
+				return purger, func(n int, err error) *mock.Call {
+					return repo.On("Purge", mock.Anything, mock.Anything, workerBatchSize).Return(n, err)
+				}
+			},
+		},
+		{
+			name: "scheduler",
+			newWorker: func(t *testing.T, interval time.Duration) (worker, expectBatch) {
+				repo := mocks.NewOutboxRepository(t)
+				scheduler := NewScheduler(repo, nil, SchedulerConfig{Interval: interval, BatchSize: workerBatchSize})
+				return scheduler, func(n int, err error) *mock.Call {
+					return repo.On("EnqueueScheduled", mock.Anything, workerBatchSize).Return(n, err)
+				}
+			},
+		},
+		{
+			name: "webhook_dispatcher",
+			newWorker: func(t *testing.T, interval time.Duration) (worker, expectBatch) {
+				repo, sender := webhookmocks.NewRepository(t), webhookmocks.NewSender(t)
+				sender.On("Send", mock.Anything, mock.Anything).Return(200, nil).Maybe()
+				repo.On("SaveAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+				dispatcher := NewWebhookDispatcher(repo, sender, nil,
+					WebhookDispatcherConfig{Interval: interval, BatchSize: workerBatchSize, Lease: time.Minute})
+				return dispatcher, func(n int, err error) *mock.Call {
+					var deliveries []*webhook.Delivery
+					for id := 1; id <= n; id++ {
+						deliveries = append(deliveries, &webhook.Delivery{ID: id})
+					}
+					return repo.On("ClaimDue", mock.Anything, workerBatchSize, time.Minute).Return(deliveries, err)
+				}
+			},
+		},
+	}
+
+	for _, w := range workers {
+		t.Run(w.name, func(t *testing.T) {
+			testWorker(t, w.newWorker)
+		})
+	}
+}
+
+// testWorker checks that the worker drains full batches, retries after errors and stops.
+func testWorker(t *testing.T, newWorker func(t *testing.T, interval time.Duration) (worker, expectBatch)) {
+	t.Run("drains_full_batches", func(t *testing.T) {
+		w, expect := newWorker(t, time.Hour)
+
+		drained := make(chan struct{})
+		expect(workerBatchSize, nil).Twice()
+		expect(1, nil).Once().Run(func(mock.Arguments) { close(drained) })
+
+		go w.Run(context.Background())
+		waitFor(t, drained)
+
+		require.NoError(t, w.Close(context.Background()))
+	})
+
+	t.Run("retries_after_error", func(t *testing.T) {
+		w, expect := newWorker(t, 10*time.Millisecond)
+
+		retried := make(chan struct{})
+		expect(0, errors.New("db is down")).Once()
+		expect(0, nil).Once().Run(func(mock.Arguments) { close(retried) })
+		expect(0, nil).Maybe()
+
+		go w.Run(context.Background())
+		waitFor(t, retried)
+
+		require.NoError(t, w.Close(context.Background()))
+	})
+
+	t.Run("close_stops_draining", func(t *testing.T) {
+		w, expect := newWorker(t, time.Hour)
+
+		handled := make(chan struct{}, 1)
+		expect(workerBatchSize, nil).Run(func(mock.Arguments) {
+			select {
+			case handled <- struct{}{}:
+			default:
+			}
+		})
+
+		go w.Run(context.Background())
+		waitFor(t, handled)
+
+		require.NoError(t, w.Close(context.Background()), "batches are always full, but Run stops")
+	})
+
+	t.Run("context_is_done", func(t *testing.T) {
+		w, expect := newWorker(t, time.Hour)
+		expect(0, nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			w.Run(ctx)
+			close(stopped)
+		}()
+		cancel()
+
+		waitFor(t, stopped)
+	})
+
+	t.Run("close_timeout", func(t *testing.T) {
+		w, expect := newWorker(t, time.Hour)
+
+		started, unblock := make(chan struct{}), make(chan struct{})
+		expect(0, nil).Once().Run(func(mock.Arguments) {
+			close(started)
+			<-unblock
+		})
+
+		go w.Run(context.Background())
+		waitFor(t, started)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded, "the current batch is not finished")
+
+		close(unblock)
+		assert.NoError(t, w.Close(context.Background()), "Close can be called again")
+	})
+}
+
+// waitFor fails the test if the channel is not closed or sent to in time.
+func waitFor[T any](t *testing.T, ch <-chan T) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
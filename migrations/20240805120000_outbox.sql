@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+create table outbox
+(
+    id         bigserial
+        constraint outbox_pk
+            primary key,
+    message_id int                                not null
+        constraint outbox_messages_id_fk
+            references messages
+            on delete cascade,
+    created_at timestamptz default now()          not null,
+    sent_at    timestamptz
+);
+
+create index outbox_pending_idx
+    on outbox (id)
+    where sent_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table outbox;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add created_at timestamptz default now() not null;
+
+create index messages_created_at_idx
+    on messages (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index messages_created_at_idx;
+
+alter table messages
+    drop column created_at;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add processed_at timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table messages
+    drop column processed_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table idempotency_keys
+(
+    key         varchar
+        constraint idempotency_keys_pk
+            primary key,
+    fingerprint varchar                   not null,
+    status_code int,
+    body        bytea,
+    created_at  timestamptz default now() not null,
+    expires_at  timestamptz               not null
+);
+
+create index idempotency_keys_expires_at_idx
+    on idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add status varchar default 'pending' not null;
+
+-- messages without an outbox entry were produced directly before the outbox was added
+update messages as m
+set status = case
+                 when m.processed then 'processed'
+                 when exists(select 1 from outbox as o where o.message_id = m.id and o.sent_at is null)
+                     then 'pending'
+                 else 'queued'
+    end;
+
+alter table messages
+    add constraint messages_status_check
+        check (status in ('pending', 'queued', 'processing', 'processed', 'failed', 'cancelled'));
+
+create index messages_status_idx
+    on messages (status);
+
+alter table messages
+    drop column processed;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table messages
+    add processed bool default false not null;
+
+update messages as m
+set processed = m.status = 'processed';
+
+drop index messages_status_idx;
+
+alter table messages
+    drop column status;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add deleted_at timestamptz;
+
+create index messages_deleted_at_idx
+    on messages (deleted_at) where deleted_at is not null;
+
+create index messages_processed_at_idx
+    on messages (processed_at) where status = 'processed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index messages_processed_at_idx;
+
+drop index messages_deleted_at_idx;
+
+alter table messages
+    drop column deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add content_tsv tsvector generated always as (to_tsvector('simple', content)) stored;
+
+create index messages_content_tsv_idx
+    on messages using gin (content_tsv);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index messages_content_tsv_idx;
+
+alter table messages
+    drop column content_tsv;
+-- +goose StatementEnd
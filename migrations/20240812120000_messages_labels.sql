@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add labels jsonb default '{}' not null;
+
+create index messages_labels_idx
+    on messages using gin (labels jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index messages_labels_idx;
+
+alter table messages
+    drop column labels;
+-- +goose StatementEnd
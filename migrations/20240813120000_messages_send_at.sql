@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add send_at timestamptz;
+
+create index messages_scheduled_idx
+    on messages (send_at)
+    where status = 'pending' and send_at is not null;
+
+create unique index outbox_message_id_key
+    on outbox (message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index outbox_message_id_key;
+
+drop index messages_scheduled_idx;
+
+alter table messages
+    drop column send_at;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add expires_at timestamptz;
+
+alter table messages
+    drop constraint messages_status_check;
+
+alter table messages
+    add constraint messages_status_check
+        check (status in ('pending', 'queued', 'processing', 'processed', 'failed', 'cancelled', 'expired'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- there is no expired status before, expired messages are not going to be processed anyway
+update messages as m
+set status = 'cancelled'
+where m.status = 'expired';
+
+alter table messages
+    drop constraint messages_status_check;
+
+alter table messages
+    add constraint messages_status_check
+        check (status in ('pending', 'queued', 'processing', 'processed', 'failed', 'cancelled'));
+
+alter table messages
+    drop column expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table api_keys
+(
+    id         serial
+        constraint api_keys_pk
+            primary key,
+    name       varchar                   not null,
+    prefix     varchar                   not null,
+    key_hash   varchar                   not null
+        constraint api_keys_key_hash_key
+            unique,
+    scopes     varchar[]                 not null,
+    created_at timestamptz default now() not null,
+    revoked_at timestamptz
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table api_keys;
+-- +goose StatementEnd
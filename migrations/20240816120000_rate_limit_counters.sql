@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create table rate_limit_counters
+(
+    key          varchar
+        constraint rate_limit_counters_pk
+            primary key,
+    window_start timestamptz not null,
+    count        int         not null,
+    prev_count   int         not null default 0
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table rate_limit_counters;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table messages
+    add callback_url varchar;
+
+alter table api_keys
+    add callback_url varchar;
+
+-- message_id is not a foreign key, the delivery log is kept after the message is purged
+create table webhook_deliveries
+(
+    id                 bigserial
+        constraint webhook_deliveries_pk
+            primary key,
+    message_id         int                                  not null,
+    url                varchar                              not null,
+    message_status     varchar                              not null,
+    event_at           timestamptz                          not null,
+    status             varchar     default 'pending'        not null
+        constraint webhook_deliveries_status_check
+            check (status in ('pending', 'succeeded', 'failed')),
+    attempts           int         default 0                not null,
+    next_attempt_at    timestamptz default now()            not null,
+    last_attempt_at    timestamptz,
+    last_response_code int,
+    last_error         varchar,
+    created_at         timestamptz default now()            not null,
+    delivered_at       timestamptz
+);
+
+create index webhook_deliveries_due_idx
+    on webhook_deliveries (next_attempt_at)
+    where status = 'pending';
+
+create index webhook_deliveries_message_id_idx
+    on webhook_deliveries (message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table webhook_deliveries;
+
+alter table api_keys
+    drop column callback_url;
+
+alter table messages
+    drop column callback_url;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- sent entries are deleted by the relay, every entry left in the outbox is pending
+delete from outbox
+where sent_at is not null;
+
+drop index outbox_pending_idx;
+
+alter table outbox
+    drop column sent_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table outbox
+    add sent_at timestamptz;
+
+create index outbox_pending_idx
+    on outbox (id)
+    where sent_at is null;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keys are scoped by the client, so keys of different clients don't collide
+alter table idempotency_keys
+    add principal varchar default '' not null;
+
+alter table idempotency_keys
+    drop constraint idempotency_keys_pk;
+
+alter table idempotency_keys
+    add constraint idempotency_keys_pk
+        primary key (principal, key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from idempotency_keys
+where principal <> '';
+
+alter table idempotency_keys
+    drop constraint idempotency_keys_pk;
+
+alter table idempotency_keys
+    add constraint idempotency_keys_pk
+        primary key (key);
+
+alter table idempotency_keys
+    drop column principal;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the token identifies the reservation, so a request whose stale reservation was taken over
+-- doesn't complete or release the reservation of the request that took it over
+alter table idempotency_keys
+    add token varchar default gen_random_uuid()::varchar not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table idempotency_keys
+    drop column token;
+-- +goose StatementEnd